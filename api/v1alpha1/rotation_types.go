@@ -23,21 +23,862 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// RotateNowAnnotation requests an immediate rotation. Any new value (e.g., an RFC3339
+// timestamp) triggers exactly one rotation.
+const RotateNowAnnotation = "rotation.security.io/rotate-now"
+
+// AttemptAnnotation records the ID of the rotation attempt in progress. It is set before
+// the backend write so that a retry can recognise a value already written by that attempt.
+const AttemptAnnotation = "rotation.security.io/attempt"
+
+// MaxHistoryEntries is the number of rotation attempts kept in status.history.
+const MaxHistoryEntries = 10
+
+// MaxPendingRevocations is the number of revocations kept in status.pendingRevocations.
+// When more rotations happen within a grace period, the oldest entries are dropped: the
+// versions they covered are destroyed with the next revocation that falls due.
+const MaxPendingRevocations = 10
+
+// Annotations that opt an existing Kubernetes Secret into rotation. The operator
+// materializes a managed Rotation, named after the Secret, for every Secret
+// carrying IntervalAnnotation.
+const (
+	// IntervalAnnotation sets the rotation interval (e.g., "720h").
+	IntervalAnnotation = "rotation.security.io/interval"
+	// VaultPathAnnotation sets the Vault path (defaults to "secret/data/<namespace>/<name>").
+	VaultPathAnnotation = "rotation.security.io/vault-path"
+	// KeyAnnotation sets the Secret key that receives the password (defaults to "password").
+	KeyAnnotation = "rotation.security.io/key"
+	// PasswordLengthAnnotation sets the length of the generated password.
+	PasswordLengthAnnotation = "rotation.security.io/password-length"
+)
+
+// ConsumesAnnotation declares on a Pod (or its template) the comma separated names of
+// Rotations, in the same namespace, whose value the workload consumes.
+const ConsumesAnnotation = "rotation.security.io/consumes"
+
+// AllowedSourceNamespacesAnnotation, on a Namespace, grants Rotations in the comma
+// separated namespaces it lists ("*" for any) permission to write target Secrets in it.
+// Without the grant, a Rotation only writes Secrets in another namespace if the default
+// ServiceAccount of its own namespace may create and update them there.
+const AllowedSourceNamespacesAnnotation = "rotation.security.io/allowed-source-namespaces"
+
+// ManagedByLabel marks resources created by the operator.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// ManagedByValue is the ManagedByLabel value set by the operator.
+const ManagedByValue = "secret-rotator-operator"
+
+// FieldManager is the field manager the operator writes with. Status and the spec of
+// managed Rotations are applied server-side under it, so the fields it owns coexist
+// with those applied by GitOps tools and other controllers.
+const FieldManager = "secret-rotator-operator"
+
+// PluginFieldManager is the field manager of the status changes made by kubectl-rotate
+// (approvals and imported state). The operator takes those fields over before applying
+// the status, so it can later clear them.
+const PluginFieldManager = "kubectl-rotate"
+
+// OwnerUIDLabel holds the UID of the Rotation that created a Secret in another namespace,
+// where an ownerReference cannot point to it. Such Secrets are deleted along with the
+// Rotation by CleanupFinalizer.
+const OwnerUIDLabel = "rotation.security.io/owner-uid"
+
+// CleanupFinalizer is set on Rotations with target Secrets in other namespaces, so the
+// Secrets they created there are deleted before the Rotation goes away.
+const CleanupFinalizer = "rotation.security.io/cleanup"
+
+// DefaultSecretKey is the Secret key that receives the password when none is set.
+const DefaultSecretKey = "password"
+
+// DefaultHolidaysKey is the ConfigMap key read for a holiday calendar when none is set.
+const DefaultHolidaysKey = "holidays"
+
+// PreviousValueSuffix is appended to the key of the main value to name the backend key
+// that keeps the prior value with spec.retainPrevious (e.g., "password_previous").
+const PreviousValueSuffix = "_previous"
+
+// PreviousSecretKey is the target Secret key that keeps the prior value with
+// spec.retainPrevious.
+const PreviousSecretKey = "old"
+
+// TokenSecretKey is the Secret key that receives a ServiceAccount token when none is set.
+const TokenSecretKey = "token"
+
+// DockerConfigSecretKey is the Secret key that receives a .dockerconfigjson payload.
+const DockerConfigSecretKey = ".dockerconfigjson"
+
+// HtpasswdSecretKey is the Secret key that receives an htpasswd entry, as expected by
+// ingress basic-auth Secrets.
+const HtpasswdSecretKey = "auth"
+
+// TOTPSeedKey is the Secret key that receives a TOTP seed.
+const TOTPSeedKey = "seed"
+
+// PrivateKeyKey is the Secret key that receives a generated private key (types WireGuard
+// and OpenPGP).
+const PrivateKeyKey = "privateKey"
+
+// KeyRingKey is the Secret key that receives a data-encryption key ring.
+const KeyRingKey = "keyring"
+
+// Rotation types, selected with spec.type.
+const (
+	// TypePassword generates a random password (the default).
+	TypePassword = "Password"
+	// TypeServiceAccountToken mints a bound ServiceAccount token with the TokenRequest API.
+	TypeServiceAccountToken = "ServiceAccountToken"
+	// TypeDockerConfigJSON rotates a registry robot account and produces a .dockerconfigjson payload.
+	TypeDockerConfigJSON = "DockerConfigJSON"
+	// TypeHtpasswd generates a password and its bcrypt htpasswd entry.
+	TypeHtpasswd = "Htpasswd"
+	// TypeTOTP generates a TOTP seed and its otpauth:// provisioning URI.
+	TypeTOTP = "TOTP"
+	// TypeWireGuard generates a WireGuard key pair and, optionally, a preshared key.
+	TypeWireGuard = "WireGuard"
+	// TypeOpenPGP generates an OpenPGP key with an encryption subkey.
+	TypeOpenPGP = "OpenPGP"
+	// TypeDataEncryptionKey adds a new AES-256 key to a data-encryption key ring.
+	TypeDataEncryptionKey = "DataEncryptionKey"
+)
+
+// Write modes of spec.writeMode.
+const (
+	// WriteModeReplace replaces the whole document at vaultPath.
+	WriteModeReplace = "Replace"
+	// WriteModeMerge updates only the Rotation's keys at vaultPath.
+	WriteModeMerge = "Merge"
+)
+
+// Policies of spec.vaultPathsPolicy.
+const (
+	// VaultPathsAllOrNothing fails the rotation and restores the paths already written
+	// when any of spec.vaultPaths cannot be written.
+	VaultPathsAllOrNothing = "AllOrNothing"
+	// VaultPathsBestEffort completes the rotation and reports the failed paths.
+	VaultPathsBestEffort = "BestEffort"
+)
+
+// Strategies of spec.strategy.
+const (
+	// StrategyDirect writes each new value straight to vaultPath.
+	StrategyDirect = "Direct"
+	// StrategyCanary stages each new value and promotes it only after a canary succeeds.
+	StrategyCanary = "Canary"
+)
+
+// Phases of status.canary.
+const (
+	CanaryRunning  = "Running"
+	CanaryPromoted = "Promoted"
+	CanaryFailed   = "Failed"
+)
+
+// Results of status.vaultPaths.
+const (
+	VaultPathWritten    = "Written"
+	VaultPathFailed     = "Failed"
+	VaultPathRolledBack = "RolledBack"
+)
+
+// Condition types reported in status.conditions.
+const (
+	// ConditionReady is False with reason ReasonInvalidSpec when the spec cannot be
+	// processed; the Rotation is not retried until its spec changes.
+	ConditionReady = "Ready"
+	// ConditionPathConflict is True when another Rotation writes to one of the same paths,
+	// vaultPath or any of vaultPaths.
+	// Only the oldest of them rotates; the rest wait until the conflict is resolved.
+	ConditionPathConflict = "PathConflict"
+	// ConditionBlocked is True while a due rotation waits for the Rotations listed in
+	// spec.dependsOn, or when those dependencies form a cycle.
+	ConditionBlocked = "Blocked"
+	// ConditionPendingApproval is True while a due rotation of a Rotation with
+	// spec.approvalRequired waits to be approved.
+	ConditionPendingApproval = "PendingApproval"
+	// ConditionWaitingForRollout is True while a due rotation of a Rotation with
+	// spec.waitForRollout waits for its consumer workloads to finish rolling out.
+	ConditionWaitingForRollout = "WaitingForRollout"
+	// ConditionTimedOut is True when the last rotation attempt exceeded spec.timeout. Its
+	// reason is the status of the step that was cut short (e.g., "ErrorEjecutor").
+	ConditionTimedOut = "TimedOut"
+	// ConditionBackendCircuitOpen is True while a due rotation waits because the backend
+	// failed too many times in a row; the operator probes it again periodically.
+	ConditionBackendCircuitOpen = "BackendCircuitOpen"
+)
+
+// ReasonInvalidSpec is the Ready condition reason for a spec the controller cannot process.
+const ReasonInvalidSpec = "InvalidSpec"
+
 // RotationSpec defines the desired state of Rotation
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'ServiceAccountToken' || has(self.serviceAccountToken)",message="serviceAccountToken is required for type ServiceAccountToken"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'DockerConfigJSON' || has(self.registry)",message="registry is required for type DockerConfigJSON"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'Htpasswd' || has(self.htpasswd)",message="htpasswd is required for type Htpasswd"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'TOTP' || has(self.totp)",message="totp is required for type TOTP"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'OpenPGP' || has(self.openPGP)",message="openPGP is required for type OpenPGP"
+// +kubebuilder:validation:XValidation:rule="!has(self.strategy) || self.strategy != 'Canary' || (has(self.canary) && !has(self.executor))",message="strategy Canary requires canary and no executor"
+// +kubebuilder:validation:XValidation:rule="!has(self.passwordLength) || self.passwordLength <= 72 || ((!has(self.type) || self.type != 'Htpasswd') && (!has(self.password) || !has(self.password.hashOutputs) || !self.password.hashOutputs.exists(h, h == 'bcrypt')))",message="passwordLength must be at most 72 with type Htpasswd or the bcrypt hash output"
+// +kubebuilder:validation:XValidation:rule="!has(self.serverSide) || ((!has(self.type) || self.type == 'Password') && !has(self.executor) && !has(self.password))",message="serverSide requires type Password without executor or password hash outputs"
 type RotationSpec struct {
 	// REQUIRED: Name of the Vault secret path where the new password will be stored (e.g., "secret/data/my-app/db-creds").
+	// +kubebuilder:validation:MinLength=1
 	VaultPath string `json:"vaultPath"`
 
-	// REQUIRED: How often the password should be rotated (e.g., "24h", "7d").
+	// OPTIONAL: How the rotated value is written to vaultPath: "Replace" (default) writes a
+	// document with only the Rotation's keys, "Merge" reads the existing document and
+	// updates only the Rotation's keys, keeping any others, with check-and-set so a
+	// concurrent write is never overwritten.
+	// +kubebuilder:default:=Replace
+	// +kubebuilder:validation:Enum=Replace;Merge
+	WriteMode string `json:"writeMode,omitempty"`
+
+	// OPTIONAL: Additional Vault paths, on the same or other mounts, that receive the same
+	// value as vaultPath on every rotation (e.g., per-environment mirrors). They are
+	// written with writeMode too.
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:MinLength=1
+	// +listType=set
+	VaultPaths []string `json:"vaultPaths,omitempty"`
+
+	// OPTIONAL: What happens when one of vaultPaths cannot be written: "AllOrNothing"
+	// (default) restores the paths already written and fails the rotation, "BestEffort"
+	// completes the rotation and reports the failed paths in status.vaultPaths. A failure
+	// writing vaultPath itself always fails the rotation.
+	// +kubebuilder:default:=AllOrNothing
+	// +kubebuilder:validation:Enum=AllOrNothing;BestEffort
+	VaultPathsPolicy string `json:"vaultPathsPolicy,omitempty"`
+
+	// OPTIONAL: How a new value is published: "Direct" (default) writes it to vaultPath,
+	// "Canary" writes it first to a staging path and runs a canary Job against it; the
+	// value is promoted to vaultPath only if the Job completes. A failed canary aborts
+	// the rotation, leaving vaultPath untouched, and it is retried.
+	// +kubebuilder:default:=Direct
+	// +kubebuilder:validation:Enum=Direct;Canary
+	Strategy string `json:"strategy,omitempty"`
+
+	// OPTIONAL: Canary run before promoting each value; required for strategy Canary.
+	Canary *CanarySpec `json:"canary,omitempty"`
+
+	// REQUIRED: How often the password should be rotated, as a Go duration of at least 1m
+	// (e.g., "24h", "168h").
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="rotationInterval must be a duration of at least 1m (e.g., \"24h\")"
 	RotationInterval string `json:"rotationInterval"`
 
-	// OPTIONAL: Desired length of the generated password (default 16).
+	// OPTIONAL: Kind of credential rotated: "Password" (default), "ServiceAccountToken",
+	// "DockerConfigJSON", "Htpasswd", "TOTP", "WireGuard", "OpenPGP" or "DataEncryptionKey".
+	// +kubebuilder:default:=Password
+	// +kubebuilder:validation:Enum=Password;ServiceAccountToken;DockerConfigJSON;Htpasswd;TOTP;WireGuard;OpenPGP;DataEncryptionKey
+	Type string `json:"type,omitempty"`
+
+	// OPTIONAL: ServiceAccount whose tokens are minted; required for type ServiceAccountToken.
+	ServiceAccountToken *ServiceAccountTokenSpec `json:"serviceAccountToken,omitempty"`
+
+	// OPTIONAL: Registry robot account rotated; required for type DockerConfigJSON.
+	Registry *RegistrySpec `json:"registry,omitempty"`
+
+	// OPTIONAL: User of the htpasswd entry; required for type Htpasswd.
+	Htpasswd *HtpasswdSpec `json:"htpasswd,omitempty"`
+
+	// OPTIONAL: Account of the TOTP seed; required for type TOTP.
+	TOTP *TOTPSpec `json:"totp,omitempty"`
+
+	// OPTIONAL: Options of type WireGuard.
+	WireGuard *WireGuardSpec `json:"wireGuard,omitempty"`
+
+	// OPTIONAL: Key generated by type OpenPGP; required for that type.
+	OpenPGP *OpenPGPSpec `json:"openPGP,omitempty"`
+
+	// OPTIONAL: Options of type DataEncryptionKey.
+	DataEncryptionKey *DataEncryptionKeySpec `json:"dataEncryptionKey,omitempty"`
+
+	// OPTIONAL: Generate the value inside Vault, so its plaintext never reaches the operator.
+	ServerSide *ServerSideSpec `json:"serverSide,omitempty"`
+
+	// OPTIONAL: Desired length of the generated password (default 16). At most 72 when
+	// the password is hashed with bcrypt (type Htpasswd or the bcrypt hash output).
 	// +kubebuilder:default:=16
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=128
 	PasswordLength int `json:"passwordLength,omitempty"`
 
 	// OPTIONAL: Include symbols in the generated password.
 	// +kubebuilder:default:=true
 	IncludeSymbols bool `json:"includeSymbols,omitempty"`
+
+	// OPTIONAL: Keep the prior value for one more cycle, so consumers that restart slowly
+	// can fall back to it during the cutover: under "<key>_previous" in the backend (e.g.,
+	// "password_previous") and under "old" in target Secrets.
+	RetainPrevious bool `json:"retainPrevious,omitempty"`
+
+	// OPTIONAL: Additional outputs of generated passwords (types Password and Htpasswd).
+	Password *PasswordSpec `json:"password,omitempty"`
+
+	// OPTIONAL: Endpoints notified after every successful rotation.
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
+
+	// OPTIONAL: Suspend pauses scheduled and manual rotations until set back to false.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// OPTIONAL: On the first reconcile, adopt the value already stored at vaultPath
+	// instead of rotating it: status.lastRotatedTime is set to when that value was written
+	// and its fingerprint is recorded, so the first rotation is due one rotationInterval
+	// later. Without a value at vaultPath the Rotation rotates right away as usual.
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// OPTIONAL: Names of Rotations, in the same namespace, that must rotate successfully
+	// before this one in every cycle (e.g., the CA before the certificates it signs). A due
+	// rotation waits, with the Blocked condition, until each dependency has rotated since
+	// this Rotation last did. Dependencies are expected to share the rotation interval.
+	// +listType=set
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// OPTIONAL: Hold every rotation, scheduled or manual, until it is approved. A due
+	// rotation opens a request in status.pendingApproval that a user allowed to patch the
+	// rotations/status subresource approves by setting status.pendingApproval.approvedBy.
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+	// OPTIONAL: How long a pending approval stays valid, as a Go duration of at least 1m
+	// (defaults to "24h"). An expired request is replaced by a new one.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="approvalTimeout must be a duration of at least 1m (e.g., \"24h\")"
+	ApprovalTimeout string `json:"approvalTimeout,omitempty"`
+
+	// OPTIONAL: Defer scheduled rotations, with the WaitingForRollout condition, while a
+	// consumer Deployment, StatefulSet or DaemonSet listed in status.consumers is rolling
+	// out, so credentials do not change underneath Pods still starting with the old ones.
+	// A Deployment past its progress deadline does not hold the rotation. Manual
+	// rotations do not wait.
+	WaitForRollout bool `json:"waitForRollout,omitempty"`
+
+	// OPTIONAL: Order of this Rotation when several are due at once (e.g., after the
+	// operator was down): higher values rotate first and, within the same priority, the
+	// most overdue. Between -100 and 100 (defaults to 0).
+	// +kubebuilder:validation:Minimum=-100
+	// +kubebuilder:validation:Maximum=100
+	Priority int32 `json:"priority,omitempty"`
+
+	// OPTIONAL: Days on which scheduled rotations never run, for rotations that need
+	// someone available (manual verification, vendor portals).
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// OPTIONAL: Deadline for the external steps of a rotation (generation, executor and its
+	// verification, and writes to Vault, destinations and target Secrets), as a Go
+	// duration of at least 1s (defaults to "10m"). An attempt cut short reports
+	// ErrorTimeout with the TimedOut condition and is retried.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="timeout must be a duration of at least 1s (e.g., \"5m\")"
+	Timeout string `json:"timeout,omitempty"`
+
+	// OPTIONAL: Copy the current value to a backup path before every rotation overwrites
+	// it, so rotating the wrong path by mistake can be undone.
+	Backup *BackupSpec `json:"backup,omitempty"`
+
+	// OPTIONAL: How long the previous credential stays valid after a successful rotation,
+	// as a Go duration of at least 1m (e.g., "24h"). Executors that can hold two passwords
+	// (LDAP and SMTP with the userPassword attribute) add the new one and revoke the old
+	// one at its source once it elapses; the others replace it when rotating. The previous
+	// KV v2 versions of vaultPath are destroyed at the same time, and the prior value is
+	// removed from vaultPath and target Secrets. Unset keeps every version, and executors
+	// replace the credential when rotating.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="revocationGracePeriod must be a duration of at least 1m (e.g., \"24h\")"
+	RevocationGracePeriod string `json:"revocationGracePeriod,omitempty"`
+
+	// OPTIONAL: Kubernetes resources kept in sync with the rotated value.
+	Targets *RotationTargets `json:"targets,omitempty"`
+
+	// OPTIONAL: Applies the new value in the system that uses it, and verifies it, before
+	// it is written to Vault.
+	Executor *ExecutorSpec `json:"executor,omitempty"`
+
+	// OPTIONAL: Destinations that receive the rotated value besides Vault.
+	Destinations *DestinationSpec `json:"destinations,omitempty"`
+
+	// OPTIONAL: Actions run on downstream resources after every successful rotation.
+	PostRotation *PostRotationSpec `json:"postRotation,omitempty"`
+}
+
+// ScheduleSpec excludes days from scheduled rotations. A rotation that falls due on an
+// excluded day, in UTC, is deferred to the start of the next allowed day. Manual
+// rotations are not affected.
+type ScheduleSpec struct {
+	// OPTIONAL: Days of the week excluded (e.g., ["Saturday", "Sunday"]).
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=6
+	// +kubebuilder:validation:items:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+	ExcludeDays []string `json:"excludeDays,omitempty"`
+
+	// OPTIONAL: ConfigMap, in the Rotation namespace, listing holidays excluded as well:
+	// one date (YYYY-MM-DD) per line, optionally followed by a description. Lines
+	// starting with # are ignored.
+	HolidayCalendar *HolidayCalendarReference `json:"holidayCalendar,omitempty"`
+}
+
+// HolidayCalendarReference selects the ConfigMap key holding a holiday calendar.
+type HolidayCalendarReference struct {
+	// REQUIRED: Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// OPTIONAL: Key holding the calendar (default "holidays").
+	Key string `json:"key,omitempty"`
+}
+
+// BackupSpec configures the copies of the current value written before each rotation.
+type BackupSpec struct {
+	// OPTIONAL: Vault KV v2 path under which the copies are written, one per rotation
+	// named after its UTC time (e.g., "20250301T090000Z"). Defaults to
+	// "<vaultPath>-backup".
+	Path string `json:"path,omitempty"`
+
+	// OPTIONAL: Number of copies kept; older ones are deleted with all their versions
+	// (default 5).
+	// +kubebuilder:default:=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Retain int32 `json:"retain,omitempty"`
+}
+
+// ServiceAccountTokenSpec configures the bound tokens minted with the TokenRequest API.
+// A token is re-minted once 80% of its lifetime has elapsed, even if rotationInterval
+// has not.
+type ServiceAccountTokenSpec struct {
+	// REQUIRED: Name of the ServiceAccount, in the Rotation namespace.
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// OPTIONAL: Intended audiences of the token (defaults to the API server audiences).
+	Audiences []string `json:"audiences,omitempty"`
+
+	// OPTIONAL: Requested lifetime of the token in seconds (default 3600). The API server
+	// may issue a shorter one.
+	// +kubebuilder:default:=3600
+	// +kubebuilder:validation:Minimum=600
+	ExpirationSeconds int64 `json:"expirationSeconds,omitempty"`
+}
+
+// PasswordSpec configures the outputs derived from a generated password.
+type PasswordSpec struct {
+	// OPTIONAL: Hashes of the password written to the backend next to the plaintext, from
+	// the same rotation, under the keys "hash_bcrypt", "hash_argon2id" and
+	// "hash_sha512_crypt". Clients read the plaintext; servers are configured with the hash.
+	// +kubebuilder:validation:items:Enum=bcrypt;argon2id;sha512-crypt
+	// +kubebuilder:validation:MaxItems=3
+	// +listType=set
+	HashOutputs []string `json:"hashOutputs,omitempty"`
+}
+
+// HtpasswdSpec configures type Htpasswd: a password is generated as usual (passwordLength
+// and includeSymbols apply, up to the 72 bytes bcrypt accepts) and written to the
+// backend together with its "<username>:<bcrypt hash>" entry. Target Secrets receive
+// the entry under the "auth" key used by ingress basic-auth.
+type HtpasswdSpec struct {
+	// REQUIRED: User name of the htpasswd entry.
+	// +kubebuilder:validation:Pattern=`^[^:]+$`
+	Username string `json:"username"`
+}
+
+// TOTPSpec configures type TOTP, used to rotate the second factor of shared accounts
+// (e.g., break-glass users). The backend receives the base32 "seed" and the otpauth://
+// "uri" to provision authenticator apps; target Secrets receive the seed.
+type TOTPSpec struct {
+	// REQUIRED: Account name shown by authenticator apps (e.g., "break-glass@example.com").
+	// +kubebuilder:validation:MinLength=1
+	AccountName string `json:"accountName"`
+
+	// OPTIONAL: Issuer shown by authenticator apps (e.g., "Example Corp").
+	// +kubebuilder:validation:Pattern=`^[^:]*$`
+	Issuer string `json:"issuer,omitempty"`
+
+	// OPTIONAL: HMAC algorithm: "SHA1" (default, the most widely supported), "SHA256" or "SHA512".
+	// +kubebuilder:default:=SHA1
+	// +kubebuilder:validation:Enum=SHA1;SHA256;SHA512
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// OPTIONAL: Number of digits of each code (default 6).
+	// +kubebuilder:default:=6
+	// +kubebuilder:validation:Enum=6;8
+	Digits int `json:"digits,omitempty"`
+
+	// OPTIONAL: Validity of each code in seconds (default 30).
+	// +kubebuilder:default:=30
+	// +kubebuilder:validation:Minimum=15
+	// +kubebuilder:validation:Maximum=300
+	Period int `json:"period,omitempty"`
+}
+
+// WireGuardSpec configures type WireGuard. The backend receives "privateKey" and
+// "publicKey" (and "presharedKey" if requested); target Secrets receive the private key
+// and status.publicKey exposes the public one so peers can be reconfigured, e.g. from a
+// notification webhook.
+type WireGuardSpec struct {
+	// OPTIONAL: Also generate a preshared key for the peer.
+	PresharedKey bool `json:"presharedKey,omitempty"`
+}
+
+// OpenPGPSpec configures type OpenPGP. The backend receives the armored "privateKey"
+// (unprotected; rely on the backend's access control) and "publicKey"; status exposes
+// the public key and its fingerprint. A new key is generated once 80% of its validity
+// has elapsed, even if rotationInterval has not.
+type OpenPGPSpec struct {
+	// OPTIONAL: Key algorithm: "Ed25519" (default, with a Curve25519 encryption subkey),
+	// "RSA3072" or "RSA4096".
+	// +kubebuilder:default:=Ed25519
+	// +kubebuilder:validation:Enum=Ed25519;RSA3072;RSA4096
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// REQUIRED: Name of the user ID (e.g., "Release Signing").
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// OPTIONAL: Email of the user ID.
+	Email string `json:"email,omitempty"`
+
+	// OPTIONAL: Comment of the user ID.
+	Comment string `json:"comment,omitempty"`
+
+	// OPTIONAL: Validity of each key from its creation, as a Go duration of at least 24h
+	// (default "8760h").
+	// +kubebuilder:default:="8760h"
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('24h')",message="expiry must be a duration of at least 24h"
+	Expiry string `json:"expiry,omitempty"`
+}
+
+// DataEncryptionKeySpec configures type DataEncryptionKey, for application envelope
+// encryption. Every rotation generates an AES-256 key with the next key ID and writes the
+// key ring {"activeKeyId": N, "keys": [{"id": N, "key": "<base64>"}, ...]} under
+// "keyring": applications encrypt with the active key and decrypt with any key in the
+// ring. The active key ID is recorded in status.activeKeyID.
+type DataEncryptionKeySpec struct {
+	// OPTIONAL: Number of previous keys kept in the ring to decrypt existing data (default 2).
+	// +kubebuilder:default:=2
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=20
+	Retain *int `json:"retain,omitempty"`
+}
+
+// CanarySpec configures the canary of strategy Canary.
+type CanarySpec struct {
+	// REQUIRED: Name of a CronJob, in the Rotation namespace, whose jobTemplate is run as
+	// the canary (usually suspended, so it only runs for rotations). Its containers get
+	// ROTATION_STAGING_PATH and ROTATION_ATTEMPT; the Job should read the value from the
+	// staging path, check it (e.g., start the consumer with it) and exit accordingly.
+	// +kubebuilder:validation:MinLength=1
+	CronJobName string `json:"cronJobName"`
+
+	// OPTIONAL: Vault path where the new value is staged (defaults to
+	// "<vaultPath>-canary"). The canary Job needs read access to it.
+	StagingPath string `json:"stagingPath,omitempty"`
+
+	// OPTIONAL: How long the canary Job may run before the rotation is aborted, as a Go
+	// duration of at least 1m (defaults to "15m").
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="canary timeout must be a duration of at least 1m (e.g., \"15m\")"
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ServerSideSpec generates the value with Vault's transit engine instead of in the
+// operator: every rotation creates a data key with transit/datakey/wrapped, which returns
+// only its ciphertext under the named transit key, and that ciphertext is what the
+// operator stores at vaultPath, fingerprints and syncs to target Secrets. Consumers
+// obtain the plaintext with transit/decrypt. Vault's password policy generate endpoint
+// is not offered: it returns the plaintext to the caller.
+type ServerSideSpec struct {
+	// REQUIRED: Transit key that encrypts the generated data key.
+	// +kubebuilder:validation:MinLength=1
+	TransitKey string `json:"transitKey"`
+
+	// OPTIONAL: Mount path of the transit engine (default "transit").
+	// +kubebuilder:default:=transit
+	Mount string `json:"mount,omitempty"`
+
+	// OPTIONAL: Size of the generated data key in bits: 128, 256 (default) or 512.
+	// +kubebuilder:default:=256
+	// +kubebuilder:validation:Enum=128;256;512
+	Bits int `json:"bits,omitempty"`
+}
+
+// RegistrySpec configures the registry account rotated by type DockerConfigJSON. The
+// new credential is written as a .dockerconfigjson payload, and target Secrets created
+// by the operator get type kubernetes.io/dockerconfigjson.
+// +kubebuilder:validation:XValidation:rule="self.provider != 'Harbor' || (has(self.robotID) && has(self.robot) && has(self.server))",message="robot, robotID and server are required for Harbor"
+// +kubebuilder:validation:XValidation:rule="self.provider != 'Quay' || (has(self.robot) && has(self.server))",message="robot and server are required for Quay"
+type RegistrySpec struct {
+	// REQUIRED: Registry API used to rotate the credential: "Harbor", "Quay" or "ECR".
+	// +kubebuilder:validation:Enum=Harbor;Quay;ECR
+	Provider string `json:"provider"`
+
+	// OPTIONAL: Registry server written in the payload (e.g., "harbor.example.com"). For
+	// ECR it defaults to the endpoint of the registry.
+	Server string `json:"server,omitempty"`
+
+	// OPTIONAL: Base URL of the Harbor or Quay API (defaults to "https://<server>").
+	// +kubebuilder:validation:Pattern=`^https?://`
+	APIURL string `json:"apiURL,omitempty"`
+
+	// OPTIONAL: Robot account: its full name for Harbor (e.g., "robot$ci-pull") or
+	// "<organization>+<name>" for Quay.
+	Robot string `json:"robot,omitempty"`
+
+	// OPTIONAL: ID of the Harbor robot account.
+	RobotID int64 `json:"robotID,omitempty"`
+
+	// OPTIONAL: AWS region of the ECR registry (defaults to the operator's region).
+	Region string `json:"region,omitempty"`
+
+	// OPTIONAL: AWS account ID of the ECR registry (defaults to the operator's account).
+	RegistryID string `json:"registryID,omitempty"`
+
+	// OPTIONAL: Name of a Secret in the Rotation namespace with the API credentials:
+	// "username" and "password" of a Harbor administrator, or a Quay OAuth "token".
+	// ECR uses the operator's AWS credentials.
+	CredentialsSecretRef string `json:"credentialsSecretRef,omitempty"`
+
+	// OPTIONAL: TLS settings for the Harbor or Quay API.
+	TLS *EndpointTLS `json:"tls,omitempty"`
+}
+
+// ExecutorSpec selects the system whose credential is rotated. At most one executor is set.
+// +kubebuilder:validation:MaxProperties=1
+type ExecutorSpec struct {
+	// OPTIONAL: Change the password of an LDAP or Active Directory account.
+	LDAP *LDAPExecutor `json:"ldap,omitempty"`
+
+	// OPTIONAL: Rotate SMTP relay credentials, verified with an SMTP AUTH.
+	SMTP *SMTPExecutor `json:"smtp,omitempty"`
+
+	// OPTIONAL: Change the password of a Snowflake user.
+	Snowflake *SnowflakeExecutor `json:"snowflake,omitempty"`
+}
+
+// SnowflakeExecutor runs ALTER USER ... SET PASSWORD with an administrative account and
+// validates the new password with a simple query as the rotated user.
+type SnowflakeExecutor struct {
+	// REQUIRED: Account identifier (e.g., "myorg-myaccount").
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._-]+$`
+	Account string `json:"account"`
+
+	// REQUIRED: Service user whose password is rotated.
+	User string `json:"user"`
+
+	// OPTIONAL: Role used to run ALTER USER (e.g., "SECURITYADMIN").
+	Role string `json:"role,omitempty"`
+
+	// REQUIRED: Name of a Secret in the Rotation namespace with the admin "username" and
+	// either "privateKey" (PEM, key-pair auth) or "password".
+	AdminSecretRef string `json:"adminSecretRef"`
+}
+
+// SMTPExecutor rotates the credentials of an SMTP relay account kept in a directory
+// and verifies them by authenticating to the SMTP server. If the server rejects the
+// new password, the previous one is restored.
+type SMTPExecutor struct {
+	// REQUIRED: Host of the SMTP server.
+	Host string `json:"host"`
+
+	// OPTIONAL: Port of the SMTP server (default 587).
+	// +kubebuilder:default:=587
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+
+	// REQUIRED: User presented in the SMTP AUTH.
+	Username string `json:"username"`
+
+	// OPTIONAL: "StartTLS" (default) or "TLS" for implicit TLS.
+	// +kubebuilder:validation:Enum=StartTLS;TLS
+	// +kubebuilder:default:=StartTLS
+	TLSMode string `json:"tlsMode,omitempty"`
+
+	// OPTIONAL: Name of a Secret in the Rotation namespace whose "ca.crt" verifies the
+	// certificate of the SMTP server. Unset uses the system roots.
+	CASecretRef string `json:"caSecretRef,omitempty"`
+
+	// REQUIRED: Directory holding the relay account, whose password is changed.
+	LDAP LDAPExecutor `json:"ldap"`
+}
+
+// LDAPExecutor changes the password of a service account with an LDAP modify and
+// verifies it with a bind as that account.
+type LDAPExecutor struct {
+	// REQUIRED: URL of the directory (e.g., "ldaps://dc01.corp.example.com"). An ldap://
+	// connection is upgraded with StartTLS before any credential is sent, and fails if
+	// the server does not support it.
+	// +kubebuilder:validation:Pattern=`^ldaps?://`
+	URL string `json:"url"`
+
+	// REQUIRED: DN of the account whose password is rotated.
+	UserDN string `json:"userDN"`
+
+	// OPTIONAL: Password attribute: "userPassword" (default) or "unicodePwd" (Active Directory).
+	// +kubebuilder:validation:Enum=userPassword;unicodePwd
+	// +kubebuilder:default:=userPassword
+	Attribute string `json:"attribute,omitempty"`
+
+	// REQUIRED: Name of a Secret in the Rotation namespace with "bindDN" and "bindPassword"
+	// of an account allowed to change the password, and optionally "ca.crt".
+	BindSecretRef string `json:"bindSecretRef"`
+}
+
+// DestinationSpec defines the additional stores written on every rotation.
+type DestinationSpec struct {
+	// OPTIONAL: Commit the value, encrypted with SOPS, to a Git repository.
+	Git *GitDestination `json:"git,omitempty"`
+
+	// OPTIONAL: Write the value to the HashiCorp Consul KV store.
+	Consul *ConsulDestination `json:"consul,omitempty"`
+}
+
+// ConsulDestination writes the rotated value under a Consul KV key.
+type ConsulDestination struct {
+	// OPTIONAL: Address of the Consul HTTP API (default "http://consul-server.consul:8500").
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address,omitempty"`
+
+	// REQUIRED: KV key that receives the value (e.g., "config/my-app/db-password").
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// OPTIONAL: Datacenter to write to (defaults to the agent's datacenter).
+	Datacenter string `json:"datacenter,omitempty"`
+
+	// OPTIONAL: Name of a Secret in the Rotation namespace whose "token" is the Consul ACL token.
+	TokenSecretRef string `json:"tokenSecretRef,omitempty"`
+
+	// OPTIONAL: TLS settings (client certificate and CA pinning) for the Consul API.
+	TLS *EndpointTLS `json:"tls,omitempty"`
+}
+
+// GitDestination commits the rotated value as a SOPS-encrypted file. The operator
+// image must provide the git and sops binaries.
+type GitDestination struct {
+	// REQUIRED: URL of the repository (e.g., "https://github.com/org/secrets.git" or "ssh://git@host/org/secrets.git").
+	// +kubebuilder:validation:Pattern=`^(https://|ssh://)`
+	Repository string `json:"repository"`
+
+	// OPTIONAL: Branch the commit is pushed to (default "main").
+	// +kubebuilder:default:=main
+	Branch string `json:"branch,omitempty"`
+
+	// REQUIRED: Path of the encrypted file in the repository (e.g., "apps/db/credentials.enc.yaml").
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// OPTIONAL: Key of the rotated value in the encrypted document (default "password").
+	// +kubebuilder:default:=password
+	Key string `json:"key,omitempty"`
+
+	// OPTIONAL: age public keys able to decrypt the file.
+	AgeRecipients []string `json:"ageRecipients,omitempty"`
+
+	// OPTIONAL: AWS KMS key ARNs able to decrypt the file.
+	KMSKeys []string `json:"kmsKeys,omitempty"`
+
+	// OPTIONAL: Name of a Secret in the Rotation namespace with "username"/"password" (HTTPS)
+	// or "ssh-privatekey" and optionally "known_hosts" (SSH).
+	CredentialsSecretRef string `json:"credentialsSecretRef,omitempty"`
+}
+
+// PostRotationSpec defines the downstream resources refreshed after a rotation.
+type PostRotationSpec struct {
+	// OPTIONAL: cert-manager Certificates re-issued after the rotation (equivalent to
+	// "cmctl renew"), e.g. when the rotated value is a CA or signing key they depend on.
+	Certificates []ResourceReference `json:"certificates,omitempty"`
+
+	// OPTIONAL: Argo CD Applications refreshed after the rotation so GitOps-managed
+	// consumers converge on the new value.
+	ArgoCDApplications []ResourceReference `json:"argoCDApplications,omitempty"`
+
+	// OPTIONAL: Flux Kustomizations reconciled after the rotation.
+	FluxKustomizations []ResourceReference `json:"fluxKustomizations,omitempty"`
+}
+
+// ResourceReference points to a namespaced resource of a known kind.
+type ResourceReference struct {
+	// REQUIRED: Name of the resource.
+	Name string `json:"name"`
+
+	// OPTIONAL: Namespace of the resource (defaults to the Rotation namespace).
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RotationTargets defines where the rotated value is synced besides Vault.
+type RotationTargets struct {
+	// OPTIONAL: Kubernetes Secrets that receive the rotated value.
+	Secrets []SecretTarget `json:"secrets,omitempty"`
+
+	// OPTIONAL: Peer clusters (e.g., DR or other regions) that receive a copy of every
+	// Secret in secrets after each rotation.
+	// +listType=map
+	// +listMapKey=name
+	RemoteClusters []RemoteCluster `json:"remoteClusters,omitempty"`
+}
+
+// RemoteCluster is a peer cluster reached with a kubeconfig stored in a Secret.
+type RemoteCluster struct {
+	// REQUIRED: Name identifying the cluster in errors and Events.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// REQUIRED: Name of the Secret, in the Rotation namespace, holding the kubeconfig.
+	// Its credentials need get, create and update on Secrets in the peer cluster.
+	KubeconfigSecretRef string `json:"kubeconfigSecretRef"`
+
+	// OPTIONAL: Key of the kubeconfig in the Secret (defaults to "kubeconfig").
+	Key string `json:"key,omitempty"`
+
+	// OPTIONAL: Namespace in the peer cluster that receives the copies (defaults to the
+	// namespace of each Secret).
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SecretTarget is a Kubernetes Secret that receives the rotated value.
+type SecretTarget struct {
+	// REQUIRED: Name of the Secret. It is created if it does not exist.
+	Name string `json:"name"`
+
+	// OPTIONAL: Namespace of the Secret (defaults to the Rotation namespace). Another
+	// namespace must grant access, see AllowedSourceNamespacesAnnotation.
+	Namespace string `json:"namespace,omitempty"`
+
+	// OPTIONAL: Key that receives the value (default "password", "token" for type
+	// ServiceAccountToken, ".dockerconfigjson" for type DockerConfigJSON, "auth" for
+	// type Htpasswd, "seed" for type TOTP, "privateKey" for types WireGuard and OpenPGP or
+	// "keyring" for type DataEncryptionKey).
+	Key string `json:"key,omitempty"`
+
+	// OPTIONAL: Additional keys composed from the rotated values with Go templates, for
+	// consumers that expect them embedded in a larger value (e.g., DATABASE_URL:
+	// "postgres://app:{{ .password | urlquery }}@db:5432/app"). Templates see every value
+	// written to Vault by its key; use index for keys that are not identifiers (e.g.,
+	// {{ index . ".dockerconfigjson" }}).
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// NotificationSpec defines the sinks notified after a rotation.
+type NotificationSpec struct {
+	// OPTIONAL: Webhooks receiving a JSON rotation event (never the secret value).
+	Webhooks []WebhookEndpoint `json:"webhooks,omitempty"`
+}
+
+// WebhookEndpoint is an external HTTP endpoint the operator calls out to.
+type WebhookEndpoint struct {
+	// REQUIRED: URL of the endpoint (e.g., "https://hooks.example.com/rotation").
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// OPTIONAL: TLS settings (client certificate and CA pinning) for this endpoint.
+	TLS *EndpointTLS `json:"tls,omitempty"`
+}
+
+// EndpointTLS configures mutual TLS and CA pinning for an external endpoint.
+type EndpointTLS struct {
+	// OPTIONAL: Name of a Secret in the Rotation namespace whose "ca.crt" replaces the system
+	// CAs. The Secret must contain that key.
+	CASecretRef string `json:"caSecretRef,omitempty"`
+
+	// OPTIONAL: Name of a kubernetes.io/tls Secret in the Rotation namespace presented as client certificate.
+	ClientCertSecretRef string `json:"clientCertSecretRef,omitempty"`
+
+	// OPTIONAL: SHA-256 fingerprints (hex) of certificates that must appear in the server chain.
+	PinnedSHA256 []string `json:"pinnedSHA256,omitempty"`
+
+	// OPTIONAL: Server name used for SNI and certificate verification.
+	ServerName string `json:"serverName,omitempty"`
 }
 
 // RotationStatus defines the observed state of Rotation.
@@ -48,6 +889,166 @@ type RotationStatus struct {
 
 	// El estado actual (e.g., "Ready", "Error", "Rotating").
 	Status string `json:"status,omitempty"`
+
+	// Huella SHA-256 con sal del último valor escrito ("sha256:<sal>:<hash>").
+	// Permite verificar qué credencial usa un consumidor sin exponer el valor.
+	SecretFingerprint string `json:"secretFingerprint,omitempty"`
+
+	// Indica si la última rotación se generó con el operador en modo FIPS.
+	FIPSMode bool `json:"fipsMode,omitempty"`
+
+	// Momento en que está prevista la próxima rotación.
+	NextRotationTime *metav1.Time `json:"nextRotationTime,omitempty"`
+
+	// Vencimiento del valor vigente, para los tipos cuyo valor caduca (e.g., ServiceAccountToken).
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// Clave pública del valor vigente, para los tipos que generan un par de claves (e.g., WireGuard).
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Huella de la clave vigente, para el tipo OpenPGP.
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+
+	// Identificador de la clave activa, para el tipo DataEncryptionKey.
+	ActiveKeyID int64 `json:"activeKeyID,omitempty"`
+
+	// Último valor de la anotación rotate-now ya atendido.
+	LastRotateNowRequest string `json:"lastRotateNowRequest,omitempty"`
+
+	// Identificador del último intento de rotación completado (ver la anotación attempt).
+	LastAttemptID string `json:"lastAttemptID,omitempty"`
+
+	// Solicitud de aprobación de la rotación en espera, con spec.approvalRequired.
+	PendingApproval *PendingApproval `json:"pendingApproval,omitempty"`
+
+	// Revocaciones de credenciales anteriores pendientes de cumplir su periodo de gracia,
+	// con spec.revocationGracePeriod, de la más antigua a la más reciente.
+	PendingRevocations []PendingRevocation `json:"pendingRevocations,omitempty"`
+
+	// RotationFreeze que aplaza la rotación vencida; vacío si no hay ninguno activo.
+	DeferredBy string `json:"deferredBy,omitempty"`
+
+	// Últimos intentos de rotación, del más reciente al más antiguo.
+	History []RotationHistoryEntry `json:"history,omitempty"`
+
+	// Workloads que consumen los Secrets sincronizados (volumen, env, envFrom o
+	// anotación consumes) y que se verán afectados por la próxima rotación.
+	Consumers []ConsumerReference `json:"consumers,omitempty"`
+
+	// Último canario de la estrategia Canary.
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
+	// Resultado de la última escritura en cada ruta de spec.vaultPaths.
+	// +listType=map
+	// +listMapKey=path
+	VaultPaths []VaultPathStatus `json:"vaultPaths,omitempty"`
+
+	// Condiciones observadas del recurso (e.g., Ready, PathConflict, Blocked).
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CanaryStatus describe el canario de un intento de rotación con la estrategia Canary.
+type CanaryStatus struct {
+	// Intento de rotación cuyo valor se prueba.
+	Attempt string `json:"attempt"`
+
+	// Ruta de Vault donde se dejó el valor nuevo.
+	StagingPath string `json:"stagingPath"`
+
+	// Nombre del Job del canario.
+	Job string `json:"job"`
+
+	// Fase: "Running", "Promoted" (el valor pasó a vaultPath) o "Failed".
+	Phase string `json:"phase"`
+
+	// Motivo del fallo, si falló.
+	Message string `json:"message,omitempty"`
+
+	// Momento en que se lanzó el canario.
+	StartTime metav1.Time `json:"startTime"`
+
+	// Momento en que terminó el canario.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// VaultPathStatus es el resultado de la última escritura en una ruta de spec.vaultPaths.
+type VaultPathStatus struct {
+	// Ruta de spec.vaultPaths.
+	Path string `json:"path"`
+
+	// Resultado: "Written", "Failed" o "RolledBack" (escrita y restablecida después
+	// porque falló otra ruta).
+	Result string `json:"result"`
+
+	// Error de la escritura, si falló.
+	Message string `json:"message,omitempty"`
+
+	// Momento de la escritura.
+	Time metav1.Time `json:"time"`
+}
+
+// ConsumerReference identifica un workload que consume el secreto rotado.
+type ConsumerReference struct {
+	// Tipo del workload (e.g., "Deployment", "StatefulSet", "Pod").
+	Kind string `json:"kind"`
+
+	// Namespace del workload.
+	Namespace string `json:"namespace"`
+
+	// Nombre del workload.
+	Name string `json:"name"`
+}
+
+// RotationHistoryEntry registra un intento de rotación.
+type RotationHistoryEntry struct {
+	// Momento del intento.
+	Time metav1.Time `json:"time"`
+
+	// Origen del intento: "Schedule" o "Manual".
+	Trigger string `json:"trigger"`
+
+	// Resultado del intento (e.g., "Ready", "ErrorVault").
+	Result string `json:"result"`
+
+	// Huella del valor escrito, si el intento tuvo éxito.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// PendingApproval is a rotation waiting to be approved.
+type PendingApproval struct {
+	// Identificador de la solicitud.
+	ID string `json:"id"`
+
+	// Momento en que se abrió la solicitud.
+	RequestedTime metav1.Time `json:"requestedTime"`
+
+	// Momento en que la solicitud caduca si no se aprueba.
+	ExpirationTime metav1.Time `json:"expirationTime"`
+
+	// Origen de la rotación solicitada: "Schedule" o "Manual".
+	Trigger string `json:"trigger,omitempty"`
+
+	// Set by the approver: who approved the rotation. Any non-empty value approves it.
+	ApprovedBy string `json:"approvedBy,omitempty"`
+}
+
+// PendingRevocation is the revocation of the credential a successful rotation replaced,
+// scheduled for the end of its grace period.
+type PendingRevocation struct {
+	// Huella de la credencial anterior, aún válida en el sistema del ejecutor hasta que se
+	// revoque; vacía si el ejecutor ya la sustituyó al rotar o ya se revocó.
+	// +optional
+	CredentialFingerprint string `json:"credentialFingerprint,omitempty"`
+
+	// Versiones KV v2 anteriores a esta, la escrita por la rotación, que se destruirán;
+	// 0 si el backend no conserva versiones.
+	// +optional
+	BeforeVersion int `json:"beforeVersion,omitempty"`
+
+	// Momento a partir del cual se revoca.
+	RevokeAfter metav1.Time `json:"revokeAfter"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionFrozen is True while a due rotation is deferred by an active RotationFreeze.
+const ConditionFrozen = "Frozen"
+
+// RotationFreezeSpec defines the desired state of RotationFreeze
+// +kubebuilder:validation:XValidation:rule="timestamp(self.end) > timestamp(self.start)",message="end must be after start"
+type RotationFreezeSpec struct {
+	// REQUIRED: Start of the freeze (RFC3339).
+	Start metav1.Time `json:"start"`
+
+	// REQUIRED: End of the freeze (RFC3339). Rotations deferred by the freeze run once it ends.
+	End metav1.Time `json:"end"`
+
+	// OPTIONAL: Namespaces whose Rotations and RotationGroups are paused. Without a
+	// selector the freeze applies to every namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// OPTIONAL: Why rotations are frozen (e.g., "Black Friday change freeze").
+	Reason string `json:"reason,omitempty"`
+}
+
+// RotationFreezeStatus defines the observed state of RotationFreeze.
+type RotationFreezeStatus struct {
+	// Fase del freeze: "Scheduled", "Active" o "Ended".
+	Phase string `json:"phase,omitempty"`
+
+	// Rotations y RotationGroups ("namespace/nombre") cuya rotación aplazó el freeze.
+	// +listType=set
+	DeferredRotations []string `json:"deferredRotations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// RotationFreeze is the Schema for the rotationfreezes API. While it is active, due
+// rotations in the matching namespaces are deferred until it ends.
+type RotationFreeze struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of RotationFreeze
+	// +required
+	Spec RotationFreezeSpec `json:"spec"`
+
+	// status defines the observed state of RotationFreeze
+	// +optional
+	Status RotationFreezeStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// RotationFreezeList contains a list of RotationFreeze
+type RotationFreezeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RotationFreeze `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RotationFreeze{}, &RotationFreezeList{})
+}
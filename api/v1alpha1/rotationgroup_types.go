@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RotationGroupSpec defines the desired state of RotationGroup
+type RotationGroupSpec struct {
+	// REQUIRED: Names of the Rotations, in the RotationGroup namespace, rotated as a unit.
+	// Member Rotations stop rotating on their own schedule; a Rotation belongs to at most
+	// one group.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Rotations []string `json:"rotations"`
+
+	// REQUIRED: How often the group is rotated, as a Go duration of at least 1m (e.g., "720h").
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="rotationInterval must be a duration of at least 1m (e.g., \"720h\")"
+	RotationInterval string `json:"rotationInterval"`
+
+	// OPTIONAL: Daily window in which due rotations of the group may start. Without a
+	// window the group rotates as soon as it is due.
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// OPTIONAL: Suspend pauses scheduled and manual rotations of the group.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// MaintenanceWindow is a daily time window, in UTC.
+type MaintenanceWindow struct {
+	// REQUIRED: Start of the window as "HH:MM" in UTC (e.g., "02:00").
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// REQUIRED: Length of the window as a Go duration between 1m and 24h (e.g., "2h").
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m') && duration(self) <= duration('24h')",message="duration must be between 1m and 24h"
+	Duration string `json:"duration"`
+}
+
+// RotationGroupStatus defines the observed state of RotationGroup.
+type RotationGroupStatus struct {
+	// La última vez que el grupo se rotó con éxito.
+	LastRotatedTime *metav1.Time `json:"lastRotatedTime,omitempty"`
+
+	// Momento en que está prevista la próxima rotación del grupo.
+	NextRotationTime *metav1.Time `json:"nextRotationTime,omitempty"`
+
+	// El estado actual (e.g., "Ready", "RolledBack", "WaitingForWindow", "Frozen").
+	Status string `json:"status,omitempty"`
+
+	// Último valor de la anotación rotate-now ya atendido.
+	LastRotateNowRequest string `json:"lastRotateNowRequest,omitempty"`
+
+	// RotationFreeze que aplaza la rotación vencida; vacío si no hay ninguno activo.
+	DeferredBy string `json:"deferredBy,omitempty"`
+
+	// Últimos intentos de rotación del grupo, del más reciente al más antiguo.
+	History []RotationHistoryEntry `json:"history,omitempty"`
+
+	// Condiciones observadas del recurso (e.g., Ready).
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// RotationGroup is the Schema for the rotationgroups API. It rotates several Rotations
+// together: either every member gets a new value or, if any step fails, the members
+// already changed are rolled back to their previous value.
+type RotationGroup struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of RotationGroup
+	// +required
+	Spec RotationGroupSpec `json:"spec"`
+
+	// status defines the observed state of RotationGroup
+	// +optional
+	Status RotationGroupStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// RotationGroupList contains a list of RotationGroup
+type RotationGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RotationGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RotationGroup{}, &RotationGroupList{})
+}
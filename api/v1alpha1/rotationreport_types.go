@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxReportEntries is the number of Rotation names listed in each list of a RotationReport.
+const MaxReportEntries = 50
+
+// RotationReportSpec defines the desired state of RotationReport
+type RotationReportSpec struct {
+	// OPTIONAL: How often the report is recomputed besides every Rotation change, as a Go
+	// duration of at least 1m (defaults to "5m").
+	// +kubebuilder:default:="5m"
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1m')",message="refreshInterval must be a duration of at least 1m (e.g., \"5m\")"
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// RotationReportStatus defines the observed state of RotationReport.
+type RotationReportStatus struct {
+	// Momento en que se calculó el informe.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Número total de Rotations en el clúster.
+	Rotations int32 `json:"rotations"`
+
+	// Rotations cuyo último intento tuvo éxito.
+	Ready int32 `json:"ready"`
+
+	// Rotations suspendidas.
+	Suspended int32 `json:"suspended"`
+
+	// Rotations no suspendidas cuya próxima rotación ya pasó.
+	Overdue int32 `json:"overdue"`
+
+	// Rotations cuyo último intento falló.
+	Failing int32 `json:"failing"`
+
+	// Rotations vencidas ("namespace/nombre"), las más atrasadas primero.
+	OverdueRotations []string `json:"overdueRotations,omitempty"`
+
+	// Rotations fallidas agrupadas por namespace.
+	// +listType=map
+	// +listMapKey=namespace
+	FailingByNamespace []NamespaceFailures `json:"failingByNamespace,omitempty"`
+
+	// Rotation cuyo valor vigente es el más antiguo.
+	OldestSecret *OldestSecret `json:"oldestSecret,omitempty"`
+}
+
+// NamespaceFailures lists the failing Rotations of a namespace.
+type NamespaceFailures struct {
+	// Namespace de las Rotations.
+	Namespace string `json:"namespace"`
+
+	// Número de Rotations fallidas en el namespace.
+	Count int32 `json:"count"`
+
+	// Nombres de las Rotations fallidas.
+	Rotations []string `json:"rotations,omitempty"`
+}
+
+// OldestSecret is the Rotation whose current value was rotated the longest ago.
+type OldestSecret struct {
+	// Rotation, como "namespace/nombre".
+	Rotation string `json:"rotation"`
+
+	// Momento de su última rotación.
+	LastRotatedTime metav1.Time `json:"lastRotatedTime"`
+
+	// Antigüedad del valor al calcular el informe (e.g., "912h0m0s").
+	Age string `json:"age"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// RotationReport is the Schema for the rotationreports API. Its status summarizes every
+// Rotation in the cluster for compliance dashboards.
+type RotationReport struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of RotationReport
+	// +optional
+	Spec RotationReportSpec `json:"spec,omitempty,omitzero"`
+
+	// status defines the observed state of RotationReport
+	// +optional
+	Status RotationReportStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// RotationReportList contains a list of RotationReport
+type RotationReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RotationReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RotationReport{}, &RotationReportList{})
+}
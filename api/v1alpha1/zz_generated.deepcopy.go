@@ -21,11 +21,780 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSpec.
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulDestination) DeepCopyInto(out *ConsulDestination) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EndpointTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulDestination.
+func (in *ConsulDestination) DeepCopy() *ConsulDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerReference) DeepCopyInto(out *ConsumerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerReference.
+func (in *ConsumerReference) DeepCopy() *ConsumerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataEncryptionKeySpec) DeepCopyInto(out *DataEncryptionKeySpec) {
+	*out = *in
+	if in.Retain != nil {
+		in, out := &in.Retain, &out.Retain
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataEncryptionKeySpec.
+func (in *DataEncryptionKeySpec) DeepCopy() *DataEncryptionKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataEncryptionKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestinationSpec) DeepCopyInto(out *DestinationSpec) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitDestination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulDestination)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationSpec.
+func (in *DestinationSpec) DeepCopy() *DestinationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DestinationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointTLS) DeepCopyInto(out *EndpointTLS) {
+	*out = *in
+	if in.PinnedSHA256 != nil {
+		in, out := &in.PinnedSHA256, &out.PinnedSHA256
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointTLS.
+func (in *EndpointTLS) DeepCopy() *EndpointTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
+	*out = *in
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(LDAPExecutor)
+		**out = **in
+	}
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(SMTPExecutor)
+		**out = **in
+	}
+	if in.Snowflake != nil {
+		in, out := &in.Snowflake, &out.Snowflake
+		*out = new(SnowflakeExecutor)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorSpec.
+func (in *ExecutorSpec) DeepCopy() *ExecutorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitDestination) DeepCopyInto(out *GitDestination) {
+	*out = *in
+	if in.AgeRecipients != nil {
+		in, out := &in.AgeRecipients, &out.AgeRecipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KMSKeys != nil {
+		in, out := &in.KMSKeys, &out.KMSKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitDestination.
+func (in *GitDestination) DeepCopy() *GitDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(GitDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendarReference) DeepCopyInto(out *HolidayCalendarReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendarReference.
+func (in *HolidayCalendarReference) DeepCopy() *HolidayCalendarReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendarReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HtpasswdSpec) DeepCopyInto(out *HtpasswdSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HtpasswdSpec.
+func (in *HtpasswdSpec) DeepCopy() *HtpasswdSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HtpasswdSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPExecutor) DeepCopyInto(out *LDAPExecutor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPExecutor.
+func (in *LDAPExecutor) DeepCopy() *LDAPExecutor {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPExecutor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceFailures) DeepCopyInto(out *NamespaceFailures) {
+	*out = *in
+	if in.Rotations != nil {
+		in, out := &in.Rotations, &out.Rotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceFailures.
+func (in *NamespaceFailures) DeepCopy() *NamespaceFailures {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceFailures)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSpec) DeepCopyInto(out *NotificationSpec) {
+	*out = *in
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]WebhookEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSpec.
+func (in *NotificationSpec) DeepCopy() *NotificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OldestSecret) DeepCopyInto(out *OldestSecret) {
+	*out = *in
+	in.LastRotatedTime.DeepCopyInto(&out.LastRotatedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OldestSecret.
+func (in *OldestSecret) DeepCopy() *OldestSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(OldestSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenPGPSpec) DeepCopyInto(out *OpenPGPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenPGPSpec.
+func (in *OpenPGPSpec) DeepCopy() *OpenPGPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenPGPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordSpec) DeepCopyInto(out *PasswordSpec) {
+	*out = *in
+	if in.HashOutputs != nil {
+		in, out := &in.HashOutputs, &out.HashOutputs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSpec.
+func (in *PasswordSpec) DeepCopy() *PasswordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingApproval) DeepCopyInto(out *PendingApproval) {
+	*out = *in
+	in.RequestedTime.DeepCopyInto(&out.RequestedTime)
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingApproval.
+func (in *PendingApproval) DeepCopy() *PendingApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingRevocation) DeepCopyInto(out *PendingRevocation) {
+	*out = *in
+	in.RevokeAfter.DeepCopyInto(&out.RevokeAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingRevocation.
+func (in *PendingRevocation) DeepCopy() *PendingRevocation {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingRevocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostRotationSpec) DeepCopyInto(out *PostRotationSpec) {
+	*out = *in
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ArgoCDApplications != nil {
+		in, out := &in.ArgoCDApplications, &out.ArgoCDApplications
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.FluxKustomizations != nil {
+		in, out := &in.FluxKustomizations, &out.FluxKustomizations
+		*out = make([]ResourceReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostRotationSpec.
+func (in *PostRotationSpec) DeepCopy() *PostRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrySpec) DeepCopyInto(out *RegistrySpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EndpointTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySpec.
+func (in *RegistrySpec) DeepCopy() *RegistrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteCluster) DeepCopyInto(out *RemoteCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteCluster.
+func (in *RemoteCluster) DeepCopy() *RemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReference.
+func (in *ResourceReference) DeepCopy() *ResourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rotation) DeepCopyInto(out *Rotation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rotation.
+func (in *Rotation) DeepCopy() *Rotation {
+	if in == nil {
+		return nil
+	}
+	out := new(Rotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Rotation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationFreeze) DeepCopyInto(out *RotationFreeze) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationFreeze.
+func (in *RotationFreeze) DeepCopy() *RotationFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationFreeze) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationFreezeList) DeepCopyInto(out *RotationFreezeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RotationFreeze, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationFreezeList.
+func (in *RotationFreezeList) DeepCopy() *RotationFreezeList {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationFreezeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationFreezeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationFreezeSpec) DeepCopyInto(out *RotationFreezeSpec) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationFreezeSpec.
+func (in *RotationFreezeSpec) DeepCopy() *RotationFreezeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationFreezeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationFreezeStatus) DeepCopyInto(out *RotationFreezeStatus) {
+	*out = *in
+	if in.DeferredRotations != nil {
+		in, out := &in.DeferredRotations, &out.DeferredRotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationFreezeStatus.
+func (in *RotationFreezeStatus) DeepCopy() *RotationFreezeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationFreezeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationGroup) DeepCopyInto(out *RotationGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationGroup.
+func (in *RotationGroup) DeepCopy() *RotationGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationGroupList) DeepCopyInto(out *RotationGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RotationGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationGroupList.
+func (in *RotationGroupList) DeepCopy() *RotationGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationGroupSpec) DeepCopyInto(out *RotationGroupSpec) {
+	*out = *in
+	if in.Rotations != nil {
+		in, out := &in.Rotations, &out.Rotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationGroupSpec.
+func (in *RotationGroupSpec) DeepCopy() *RotationGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationGroupStatus) DeepCopyInto(out *RotationGroupStatus) {
+	*out = *in
+	if in.LastRotatedTime != nil {
+		in, out := &in.LastRotatedTime, &out.LastRotatedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRotationTime != nil {
+		in, out := &in.NextRotationTime, &out.NextRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]RotationHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationGroupStatus.
+func (in *RotationGroupStatus) DeepCopy() *RotationGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationHistoryEntry) DeepCopyInto(out *RotationHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationHistoryEntry.
+func (in *RotationHistoryEntry) DeepCopy() *RotationHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationList) DeepCopyInto(out *RotationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Rotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationList.
+func (in *RotationList) DeepCopy() *RotationList {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationReport) DeepCopyInto(out *RotationReport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -33,18 +802,18 @@ func (in *Rotation) DeepCopyInto(out *Rotation) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rotation.
-func (in *Rotation) DeepCopy() *Rotation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationReport.
+func (in *RotationReport) DeepCopy() *RotationReport {
 	if in == nil {
 		return nil
 	}
-	out := new(Rotation)
+	out := new(RotationReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Rotation) DeepCopyObject() runtime.Object {
+func (in *RotationReport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -52,40 +821,186 @@ func (in *Rotation) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RotationList) DeepCopyInto(out *RotationList) {
+func (in *RotationReportList) DeepCopyInto(out *RotationReportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Rotation, len(*in))
+		*out = make([]RotationReport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationList.
-func (in *RotationList) DeepCopy() *RotationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationReportList.
+func (in *RotationReportList) DeepCopy() *RotationReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(RotationList)
+	out := new(RotationReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RotationList) DeepCopyObject() runtime.Object {
+func (in *RotationReportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationReportSpec) DeepCopyInto(out *RotationReportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationReportSpec.
+func (in *RotationReportSpec) DeepCopy() *RotationReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationReportStatus) DeepCopyInto(out *RotationReportStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.OverdueRotations != nil {
+		in, out := &in.OverdueRotations, &out.OverdueRotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailingByNamespace != nil {
+		in, out := &in.FailingByNamespace, &out.FailingByNamespace
+		*out = make([]NamespaceFailures, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OldestSecret != nil {
+		in, out := &in.OldestSecret, &out.OldestSecret
+		*out = new(OldestSecret)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationReportStatus.
+func (in *RotationReportStatus) DeepCopy() *RotationReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RotationSpec) DeepCopyInto(out *RotationSpec) {
 	*out = *in
+	if in.VaultPaths != nil {
+		in, out := &in.VaultPaths, &out.VaultPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		**out = **in
+	}
+	if in.ServiceAccountToken != nil {
+		in, out := &in.ServiceAccountToken, &out.ServiceAccountToken
+		*out = new(ServiceAccountTokenSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(RegistrySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Htpasswd != nil {
+		in, out := &in.Htpasswd, &out.Htpasswd
+		*out = new(HtpasswdSpec)
+		**out = **in
+	}
+	if in.TOTP != nil {
+		in, out := &in.TOTP, &out.TOTP
+		*out = new(TOTPSpec)
+		**out = **in
+	}
+	if in.WireGuard != nil {
+		in, out := &in.WireGuard, &out.WireGuard
+		*out = new(WireGuardSpec)
+		**out = **in
+	}
+	if in.OpenPGP != nil {
+		in, out := &in.OpenPGP, &out.OpenPGP
+		*out = new(OpenPGPSpec)
+		**out = **in
+	}
+	if in.DataEncryptionKey != nil {
+		in, out := &in.DataEncryptionKey, &out.DataEncryptionKey
+		*out = new(DataEncryptionKeySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerSide != nil {
+		in, out := &in.ServerSide, &out.ServerSide
+		*out = new(ServerSideSpec)
+		**out = **in
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(PasswordSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupSpec)
+		**out = **in
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = new(RotationTargets)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Executor != nil {
+		in, out := &in.Executor, &out.Executor
+		*out = new(ExecutorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = new(DestinationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostRotation != nil {
+		in, out := &in.PostRotation, &out.PostRotation
+		*out = new(PostRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationSpec.
@@ -105,6 +1020,57 @@ func (in *RotationStatus) DeepCopyInto(out *RotationStatus) {
 		in, out := &in.LastRotatedTime, &out.LastRotatedTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextRotationTime != nil {
+		in, out := &in.NextRotationTime, &out.NextRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingApproval != nil {
+		in, out := &in.PendingApproval, &out.PendingApproval
+		*out = new(PendingApproval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingRevocations != nil {
+		in, out := &in.PendingRevocations, &out.PendingRevocations
+		*out = make([]PendingRevocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]RotationHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]ConsumerReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VaultPaths != nil {
+		in, out := &in.VaultPaths, &out.VaultPaths
+		*out = make([]VaultPathStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationStatus.
@@ -116,3 +1082,209 @@ func (in *RotationStatus) DeepCopy() *RotationStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationTargets) DeepCopyInto(out *RotationTargets) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]SecretTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]RemoteCluster, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationTargets.
+func (in *RotationTargets) DeepCopy() *RotationTargets {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationTargets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMTPExecutor) DeepCopyInto(out *SMTPExecutor) {
+	*out = *in
+	out.LDAP = in.LDAP
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMTPExecutor.
+func (in *SMTPExecutor) DeepCopy() *SMTPExecutor {
+	if in == nil {
+		return nil
+	}
+	out := new(SMTPExecutor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+	if in.ExcludeDays != nil {
+		in, out := &in.ExcludeDays, &out.ExcludeDays
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HolidayCalendar != nil {
+		in, out := &in.HolidayCalendar, &out.HolidayCalendar
+		*out = new(HolidayCalendarReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretTarget) DeepCopyInto(out *SecretTarget) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTarget.
+func (in *SecretTarget) DeepCopy() *SecretTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerSideSpec) DeepCopyInto(out *ServerSideSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerSideSpec.
+func (in *ServerSideSpec) DeepCopy() *ServerSideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerSideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountTokenSpec) DeepCopyInto(out *ServiceAccountTokenSpec) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountTokenSpec.
+func (in *ServiceAccountTokenSpec) DeepCopy() *ServiceAccountTokenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnowflakeExecutor) DeepCopyInto(out *SnowflakeExecutor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnowflakeExecutor.
+func (in *SnowflakeExecutor) DeepCopy() *SnowflakeExecutor {
+	if in == nil {
+		return nil
+	}
+	out := new(SnowflakeExecutor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TOTPSpec) DeepCopyInto(out *TOTPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TOTPSpec.
+func (in *TOTPSpec) DeepCopy() *TOTPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TOTPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultPathStatus) DeepCopyInto(out *VaultPathStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPathStatus.
+func (in *VaultPathStatus) DeepCopy() *VaultPathStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPathStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookEndpoint) DeepCopyInto(out *WebhookEndpoint) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EndpointTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookEndpoint.
+func (in *WebhookEndpoint) DeepCopy() *WebhookEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WireGuardSpec) DeepCopyInto(out *WireGuardSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WireGuardSpec.
+func (in *WireGuardSpec) DeepCopy() *WireGuardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WireGuardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+func list(ctx context.Context, c client.Client, namespace string) error {
+	rotations := &rotationv1alpha1.RotationList{}
+	if err := c.List(ctx, rotations, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tINTERVAL\tLAST ROTATED\tNEXT ROTATION\tDUE IN\tSTATUS\tSUSPENDED")
+	for _, rotation := range rotations.Items {
+		next, due := dueTime(&rotation)
+		var lastRotated time.Time
+		if rotation.Status.LastRotatedTime != nil {
+			lastRotated = rotation.Status.LastRotatedTime.Time
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n",
+			rotation.Namespace,
+			rotation.Name,
+			rotation.Spec.RotationInterval,
+			formatTime(lastRotated),
+			formatTime(next),
+			due,
+			valueOr(rotation.Status.Status, "Pending"),
+			rotation.Spec.Suspend,
+		)
+	}
+	return w.Flush()
+}
+
+func trigger(ctx context.Context, c client.Client, namespace, name string) error {
+	rotation, err := get(ctx, c, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(rotation.DeepCopy())
+	if rotation.Annotations == nil {
+		rotation.Annotations = map[string]string{}
+	}
+	rotation.Annotations[rotationv1alpha1.RotateNowAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	if err := c.Patch(ctx, rotation, patch); err != nil {
+		return err
+	}
+
+	fmt.Printf("rotation.rotation.security.io/%s rotation requested\n", name)
+	return nil
+}
+
+// approve approves the pending rotation request of a Rotation with spec.approvalRequired,
+// recording the user the API server authenticates the caller as.
+func approve(ctx context.Context, c client.Client, namespace, name string) error {
+	rotation, err := get(ctx, c, namespace, name)
+	if err != nil {
+		return err
+	}
+	pending := rotation.Status.PendingApproval
+	if pending == nil {
+		return fmt.Errorf("rotation %s has no pending approval", name)
+	}
+	if !time.Now().Before(pending.ExpirationTime.Time) {
+		return fmt.Errorf("approval request %s expired at %s", pending.ID, formatTime(pending.ExpirationTime.Time))
+	}
+	if pending.ApprovedBy != "" {
+		fmt.Printf("rotation.rotation.security.io/%s already approved by %s\n", name, pending.ApprovedBy)
+		return nil
+	}
+
+	review := &authenticationv1.SelfSubjectReview{}
+	if err := c.Create(ctx, review); err != nil {
+		return fmt.Errorf("resolving the current user: %w", err)
+	}
+
+	// The optimistic lock fails the patch if the request was replaced since it was read
+	patch := client.MergeFromWithOptions(rotation.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	rotation.Status.PendingApproval.ApprovedBy = review.Status.UserInfo.Username
+	if err := c.Status().Patch(ctx, rotation, patch); err != nil {
+		return err
+	}
+
+	fmt.Printf("rotation.rotation.security.io/%s approved (request %s)\n", name, pending.ID)
+	return nil
+}
+
+func setSuspend(ctx context.Context, c client.Client, namespace, name string, suspend bool) error {
+	rotation, err := get(ctx, c, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(rotation.DeepCopy())
+	rotation.Spec.Suspend = suspend
+	if err := c.Patch(ctx, rotation, patch); err != nil {
+		return err
+	}
+
+	state := "resumed"
+	if suspend {
+		state = "suspended"
+	}
+	fmt.Printf("rotation.rotation.security.io/%s %s\n", name, state)
+	return nil
+}
+
+func history(ctx context.Context, c client.Client, namespace, name string) error {
+	rotation, err := get(ctx, c, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTRIGGER\tRESULT\tFINGERPRINT")
+	for _, entry := range rotation.Status.History {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			formatTime(entry.Time.Time),
+			entry.Trigger,
+			entry.Result,
+			valueOr(entry.Fingerprint, "-"),
+		)
+	}
+	return w.Flush()
+}
+
+func get(ctx context.Context, c client.Client, namespace, name string) (*rotationv1alpha1.Rotation, error) {
+	rotation := &rotationv1alpha1.Rotation{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, rotation); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+// dueTime returns the next rotation time and a human readable countdown.
+func dueTime(rotation *rotationv1alpha1.Rotation) (time.Time, string) {
+	var next time.Time
+	switch {
+	case rotation.Status.NextRotationTime != nil:
+		next = rotation.Status.NextRotationTime.Time
+	case rotation.Status.LastRotatedTime != nil:
+		interval, err := time.ParseDuration(rotation.Spec.RotationInterval)
+		if err != nil {
+			return next, "invalid interval"
+		}
+		next = rotation.Status.LastRotatedTime.Add(interval)
+	default:
+		return next, "pending"
+	}
+
+	until := time.Until(next).Round(time.Second)
+	if until < 0 {
+		return next, fmt.Sprintf("overdue %s", -until)
+	}
+	return next, until.String()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// due prints every rotation sorted by time until its next rotation, flagging the
+// overdue and failing ones so a secret-hygiene review is a single command.
+func due(ctx context.Context, c client.Client, namespace string) error {
+	rotations := &rotationv1alpha1.RotationList{}
+	if err := c.List(ctx, rotations, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	type row struct {
+		rotation *rotationv1alpha1.Rotation
+		next     time.Time
+		due      string
+	}
+	rows := make([]row, 0, len(rotations.Items))
+	for i := range rotations.Items {
+		next, dueIn := dueTime(&rotations.Items[i])
+		rows = append(rows, row{rotation: &rotations.Items[i], next: next, due: dueIn})
+	}
+
+	// Never rotated (zero next time) sorts first: those are due right away
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].next.Before(rows[j].next)
+	})
+
+	now := time.Now()
+	var overdue, failing int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tNEXT ROTATION\tDUE IN\tSTATUS\tATTENTION")
+	for _, r := range rows {
+		var flags []string
+		if !r.rotation.Spec.Suspend && !r.next.IsZero() && r.next.Before(now) {
+			flags = append(flags, "OVERDUE")
+			overdue++
+		}
+		if strings.HasPrefix(r.rotation.Status.Status, "Error") {
+			flags = append(flags, "FAILING")
+			failing++
+		}
+		if r.rotation.Spec.Suspend {
+			flags = append(flags, "SUSPENDED")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.rotation.Namespace,
+			r.rotation.Name,
+			formatTime(r.next),
+			r.due,
+			valueOr(r.rotation.Status.Status, "Pending"),
+			valueOr(strings.Join(flags, ","), "-"),
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d rotations, %d overdue, %d failing\n", len(rows), overdue, failing)
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-rotate is a kubectl plugin to inspect and drive Rotation resources.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+const usage = `Usage: kubectl rotate <command> [flags]
+
+Commands:
+  list              List rotations with their next due time
+  due               Cluster-wide report sorted by time until next rotation,
+                    highlighting overdue and failing rotations
+  trigger NAME      Request an immediate rotation
+  approve NAME      Approve the pending rotation of a Rotation with approvalRequired
+  suspend NAME      Suspend scheduled and manual rotations
+  resume NAME       Resume a suspended rotation
+  history NAME      Show the latest rotation attempts
+  export            Write the rotation state (last rotation, fingerprints, history) as JSON
+  import -f FILE    Restore an exported state onto existing rotations
+
+Flags:
+  -n, --namespace        Namespace of the rotations (defaults to the current context)
+  -A, --all-namespaces   List rotations across all namespaces (list only)
+      --kubeconfig       Path to the kubeconfig file
+  -f, --filename         State document to import (- for stdin)
+      --overwrite        Import even when the current status is newer than the export
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(rotationv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(authenticationv1.AddToScheme(scheme))
+}
+
+// options holds the flags shared by every command.
+type options struct {
+	kubeconfig    string
+	namespace     string
+	allNamespaces bool
+	filename      string
+	overwrite     bool
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	opts := options{}
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	fs.StringVar(&opts.namespace, "namespace", "", "Namespace of the rotations.")
+	fs.StringVar(&opts.namespace, "n", "", "Namespace of the rotations (shorthand).")
+	fs.BoolVar(&opts.allNamespaces, "all-namespaces", false, "List rotations across all namespaces.")
+	fs.BoolVar(&opts.allNamespaces, "A", false, "List rotations across all namespaces (shorthand).")
+	fs.StringVar(&opts.filename, "filename", "", "State document to import.")
+	fs.StringVar(&opts.filename, "f", "", "State document to import (shorthand).")
+	fs.BoolVar(&opts.overwrite, "overwrite", false, "Import even when the current status is newer.")
+	args := parseInterspersed(fs, os.Args[2:])
+
+	c, namespace, err := newClient(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.allNamespaces {
+		namespace = ""
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "list":
+		err = list(ctx, c, namespace)
+	case "due":
+		// The report is cluster-wide unless a namespace is given explicitly
+		err = due(ctx, c, opts.namespace)
+	case "trigger":
+		err = withName(args, func(name string) error { return trigger(ctx, c, namespace, name) })
+	case "approve":
+		err = withName(args, func(name string) error { return approve(ctx, c, namespace, name) })
+	case "suspend":
+		err = withName(args, func(name string) error { return setSuspend(ctx, c, namespace, name, true) })
+	case "resume":
+		err = withName(args, func(name string) error { return setSuspend(ctx, c, namespace, name, false) })
+	case "history":
+		err = withName(args, func(name string) error { return history(ctx, c, namespace, name) })
+	case "export":
+		err = exportState(ctx, c, namespace)
+	case "import":
+		err = importState(ctx, c, opts.filename, opts.overwrite)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		err = fmt.Errorf("unknown command %q", command)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseInterspersed parses flags placed before or after positional arguments, as kubectl does.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func withName(args []string, fn func(name string) error) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one rotation name")
+	}
+	return fn(args[0])
+}
+
+// newClient builds a client from the kubeconfig and resolves the target namespace.
+func newClient(opts options) (client.Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.Context.Namespace = opts.namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving namespace: %w", err)
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("creating client: %w", err)
+	}
+	// The operator takes over the status fields written under this manager before applying its own
+	return client.WithFieldOwner(c, rotationv1alpha1.PluginFieldManager), namespace, nil
+}
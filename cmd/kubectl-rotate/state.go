@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// stateKind identifies the portable rotation state document.
+const stateKind = "RotationState"
+
+// stateDocument is the portable export of the rotation state of a cluster.
+type stateDocument struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Rotations  []rotationState `json:"rotations"`
+}
+
+// rotationState is the exported status of a single Rotation.
+type rotationState struct {
+	Namespace string                          `json:"namespace"`
+	Name      string                          `json:"name"`
+	VaultPath string                          `json:"vaultPath"`
+	Status    rotationv1alpha1.RotationStatus `json:"status"`
+}
+
+// exportState writes the status of every rotation (last rotation, fingerprints,
+// history) as a JSON document to stdout.
+func exportState(ctx context.Context, c client.Client, namespace string) error {
+	rotations := &rotationv1alpha1.RotationList{}
+	if err := c.List(ctx, rotations, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	doc := stateDocument{
+		APIVersion: rotationv1alpha1.GroupVersion.String(),
+		Kind:       stateKind,
+		ExportedAt: time.Now().UTC(),
+		Rotations:  make([]rotationState, 0, len(rotations.Items)),
+	}
+	for _, rotation := range rotations.Items {
+		doc.Rotations = append(doc.Rotations, rotationState{
+			Namespace: rotation.Namespace,
+			Name:      rotation.Name,
+			VaultPath: rotation.Spec.VaultPath,
+			Status:    rotation.Status,
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// importState restores the exported status onto existing Rotations. Rotations whose
+// backend path changed, or whose current status is newer, are skipped unless overwrite is set.
+func importState(ctx context.Context, c client.Client, filename string, overwrite bool) error {
+	doc, err := readState(filename)
+	if err != nil {
+		return err
+	}
+
+	var imported, skipped int
+	for _, state := range doc.Rotations {
+		rotation, err := get(ctx, c, state.Namespace, state.Name)
+		if apierrors.IsNotFound(err) {
+			fmt.Printf("skip %s/%s: rotation not found\n", state.Namespace, state.Name)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if rotation.Spec.VaultPath != state.VaultPath {
+			fmt.Printf("skip %s/%s: vaultPath changed (%s -> %s)\n",
+				state.Namespace, state.Name, state.VaultPath, rotation.Spec.VaultPath)
+			skipped++
+			continue
+		}
+		if !overwrite && newerThan(rotation.Status.LastRotatedTime, state.Status.LastRotatedTime) {
+			fmt.Printf("skip %s/%s: current status is newer than the export\n", state.Namespace, state.Name)
+			skipped++
+			continue
+		}
+
+		patch := client.MergeFrom(rotation.DeepCopy())
+		rotation.Status = state.Status
+		if err := c.Status().Patch(ctx, rotation, patch); err != nil {
+			return fmt.Errorf("importing %s/%s: %w", state.Namespace, state.Name, err)
+		}
+		fmt.Printf("rotation.rotation.security.io/%s state imported\n", state.Name)
+		imported++
+	}
+
+	fmt.Printf("\n%d imported, %d skipped\n", imported, skipped)
+	return nil
+}
+
+func readState(filename string) (*stateDocument, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("-f is required (use - for stdin)")
+	}
+
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	doc := &stateDocument{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, fmt.Errorf("decoding state document: %w", err)
+	}
+	if doc.Kind != stateKind {
+		return nil, fmt.Errorf("unexpected document kind %q", doc.Kind)
+	}
+	return doc, nil
+}
+
+// newerThan reports whether current is strictly after exported.
+func newerThan(current, exported *metav1.Time) bool {
+	if current == nil {
+		return false
+	}
+	if exported == nil {
+		return true
+	}
+	return current.After(exported.Time)
+}
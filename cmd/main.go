@@ -20,15 +20,20 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -36,7 +41,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/chaos"
 	"github.com/AndreCbrera/secret-rotator-operator/internal/controller"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/workpool"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +70,19 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var fipsMode bool
+	var vaultConfig vault.Config
+	var vaultClientCertFile, vaultClientKeyFile, vaultClientCertSecret string
+	var spiffeSVIDDir string
+	var executorWorkers int
+	var executorLimits, executorRates string
+	var namespaceRotationsPerHour int
+	var circuitThreshold int
+	var circuitCooldown time.Duration
+	var allowCrossNamespaceTargets bool
+	var startupSpread time.Duration
+	var chaosFailureRate float64
+	var chaosMaxLatency time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -79,6 +101,64 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&fipsMode, "fips-mode", security.FIPSBuild,
+		"If set, secret generators are restricted to FIPS-approved algorithms and key sizes. "+
+			"Requires a binary built with GOFIPS140 or GOEXPERIMENT=boringcrypto.")
+	flag.StringVar(&vaultConfig.Address, "vault-address", vault.DefaultAddress, "The address of the Vault server.")
+	flag.StringVar(&vaultConfig.AuthMethod, "vault-auth-method", vault.AuthToken,
+		"The Vault auth method used by the operator: token (VAULT_TOKEN), cert, jwt, aws, gcp or azure.")
+	flag.StringVar(&vaultConfig.AuthMount, "vault-auth-mount", "",
+		"The mount path of the Vault auth method. Defaults to the method name.")
+	flag.StringVar(&vaultConfig.AuthRole, "vault-auth-role", "", "The Vault role used when logging in.")
+	flag.StringVar(&vaultConfig.CACertFile, "vault-ca-cert", "", "PEM bundle used to verify the Vault server.")
+	flag.StringVar(&vaultClientCertFile, "vault-client-cert", "",
+		"Client certificate file for the Vault cert auth method. Reloaded when it changes on disk.")
+	flag.StringVar(&vaultClientKeyFile, "vault-client-key", "", "Client key file for the Vault cert auth method.")
+	flag.StringVar(&vaultClientCertSecret, "vault-client-cert-secret", "",
+		"A kubernetes.io/tls Secret (namespace/name) holding the client certificate for the Vault cert auth method.")
+	flag.StringVar(&vaultConfig.JWTFile, "vault-jwt-file", "",
+		"File holding the JWT for the Vault jwt auth method, e.g. a projected service account token with a "+
+			"custom audience. Re-read on every login. Set --vault-auth-mount when the method is mounted as oidc.")
+	flag.StringVar(&vaultConfig.AWSRegion, "vault-aws-region", "",
+		"STS region signed for the Vault aws auth method. Defaults to the global endpoint.")
+	flag.StringVar(&vaultConfig.AWSServerID, "vault-aws-server-id", "",
+		"Value of the X-Vault-AWS-IAM-Server-ID header required by the Vault aws auth mount, if any.")
+	flag.StringVar(&vaultConfig.GCPRoleType, "vault-gcp-role-type", vault.GCPRoleIAM,
+		"Type of the Vault gcp auth role: iam (GKE Workload Identity) or gce.")
+	flag.StringVar(&vaultConfig.AzureResource, "vault-azure-resource", "",
+		"Resource of the token presented to the Vault azure auth method. Defaults to Azure Resource Manager.")
+	flag.StringVar(&vaultConfig.AzureSubscriptionID, "vault-azure-subscription-id", "",
+		"Subscription sent to the Vault azure auth method. Read from the instance metadata when empty.")
+	flag.StringVar(&vaultConfig.AzureResourceGroup, "vault-azure-resource-group", "",
+		"Resource group sent to the Vault azure auth method.")
+	flag.StringVar(&spiffeSVIDDir, "spiffe-svid-dir", "",
+		"Directory where spiffe-helper writes the operator SVIDs. The X.509-SVID is used for the Vault cert "+
+			"auth method and presented to webhooks and destinations, the JWT-SVID for the Vault jwt auth method.")
+	flag.IntVar(&executorWorkers, "executor-workers", workpool.DefaultWorkers,
+		"Maximum number of backend operations (Vault writes, database and cloud API calls) running at once.")
+	flag.StringVar(&executorLimits, "executor-limits", "",
+		"Per-backend concurrency limits, e.g. vault=4. Backends without a limit share only the global one.")
+	flag.StringVar(&executorRates, "executor-rates", "",
+		"Per-backend pacing in operations per second across all Rotations, with an optional burst after a colon, "+
+			"e.g. vault=20:40,registry=0.5. Time spent waiting is exported as secret_rotator_backend_throttle_wait_seconds.")
+	flag.IntVar(&namespaceRotationsPerHour, "namespace-rotations-per-hour", 0,
+		"Maximum number of rotations started per namespace in any hour. Use 0 for no limit.")
+	flag.IntVar(&circuitThreshold, "backend-circuit-threshold", controller.DefaultCircuitThreshold,
+		"Consecutive Vault failures that open its circuit: due rotations are deferred with a BackendCircuitOpen "+
+			"condition instead of retrying. Use 0 to never open it.")
+	flag.DurationVar(&circuitCooldown, "backend-circuit-cooldown", controller.DefaultCircuitCooldown,
+		"Time an open circuit waits before letting a single rotation through to probe the backend.")
+	flag.BoolVar(&allowCrossNamespaceTargets, "allow-cross-namespace-targets", false,
+		"Write target Secrets in other namespaces without checking that the Rotation namespace has access to them.")
+	flag.DurationVar(&startupSpread, "startup-spread", controller.DefaultStartupSpread,
+		"Window after startup over which Rotations that became due while the operator was down are spread. "+
+			"Use 0 to rotate them immediately.")
+	flag.Float64Var(&chaosFailureRate, "chaos-failure-rate", 0,
+		"Testing and staging only: probability between 0 and 1 that a backend write, executor rotation or "+
+			"verification fails with an injected error.")
+	flag.DurationVar(&chaosMaxLatency, "chaos-max-latency", 0,
+		"Testing and staging only: maximum random latency added to every backend write, executor rotation "+
+			"and verification.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -87,6 +167,14 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if fipsMode {
+		if err := security.EnableFIPSMode(); err != nil {
+			setupLog.Error(err, "unable to enable FIPS mode")
+			os.Exit(1)
+		}
+		setupLog.Info("FIPS mode enabled", "module", security.FIPSModule)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -154,6 +242,49 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	// Los SVIDs de SPIFFE sustituyen a credenciales de larga duración; las opciones
+	// explícitas de Vault tienen prioridad sobre ellos
+	var endpointIdentity vault.CertificateSource
+	if len(spiffeSVIDDir) > 0 {
+		svid := vault.NewFileCertificateSource(
+			filepath.Join(spiffeSVIDDir, "svid.pem"), filepath.Join(spiffeSVIDDir, "svid_key.pem"))
+		endpointIdentity = svid
+		if len(vaultClientCertFile) == 0 {
+			vaultConfig.ClientCert = svid
+		}
+		if len(vaultConfig.CACertFile) == 0 {
+			vaultConfig.CACertFile = filepath.Join(spiffeSVIDDir, "svid_bundle.pem")
+		}
+		if len(vaultConfig.JWTFile) == 0 {
+			vaultConfig.JWTFile = filepath.Join(spiffeSVIDDir, "jwt_svid.token")
+		}
+	}
+
+	if len(vaultClientCertFile) > 0 {
+		vaultConfig.ClientCert = vault.NewFileCertificateSource(vaultClientCertFile, vaultClientKeyFile)
+	}
+
+	var vaultCertSecret types.NamespacedName
+	if len(vaultClientCertSecret) > 0 {
+		namespace, name, found := strings.Cut(vaultClientCertSecret, "/")
+		if !found {
+			setupLog.Error(nil, "vault-client-cert-secret must be in namespace/name form", "value", vaultClientCertSecret)
+			os.Exit(1)
+		}
+		vaultCertSecret = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	limits, err := workpool.ParseLimits(executorLimits)
+	if err != nil {
+		setupLog.Error(err, "invalid executor-limits")
+		os.Exit(1)
+	}
+	rates, err := workpool.ParseRates(executorRates)
+	if err != nil {
+		setupLog.Error(err, "invalid executor-rates")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -178,11 +309,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controller.RotationReconciler{
-		Client: mgr.GetClient(),
+	chaosInjector, err := chaos.New(chaosFailureRate, chaosMaxLatency)
+	if err != nil {
+		setupLog.Error(err, "invalid chaos-failure-rate or chaos-max-latency")
+		os.Exit(1)
+	}
+	if chaosInjector != nil {
+		setupLog.Info("failure injection enabled, do not use in production",
+			"failureRate", chaosFailureRate, "maxLatency", chaosMaxLatency)
+	}
+
+	// Todo lo que escriben los controladores queda a nombre de un mismo gestor de campos
+	apiClient := client.WithFieldOwner(mgr.GetClient(), rotationv1alpha1.FieldManager)
+
+	rotationReconciler := &controller.RotationReconciler{
+		Client:                     apiClient,
+		Scheme:                     mgr.GetScheme(),
+		VaultConfig:                vaultConfig,
+		VaultCertSecret:            vaultCertSecret,
+		Executors:                  workpool.New(executorWorkers, limits, rates),
+		StartupSpread:              startupSpread,
+		NamespaceQuota:             namespaceRotationsPerHour,
+		CircuitThreshold:           circuitThreshold,
+		CircuitCooldown:            circuitCooldown,
+		AllowCrossNamespaceTargets: allowCrossNamespaceTargets,
+		Recorder:                   mgr.GetEventRecorderFor("rotation-controller"),
+		EndpointIdentity:           endpointIdentity,
+		Chaos:                      chaosInjector,
+	}
+	if err := rotationReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Rotation")
+		os.Exit(1)
+	}
+	if err := (&controller.SecretReconciler{
+		Client: apiClient,
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Rotation")
+		setupLog.Error(err, "unable to create controller", "controller", "Secret")
+		os.Exit(1)
+	}
+	if err := (&controller.RotationGroupReconciler{
+		Client:    apiClient,
+		Scheme:    mgr.GetScheme(),
+		Rotations: rotationReconciler,
+		Recorder:  mgr.GetEventRecorderFor("rotationgroup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RotationGroup")
+		os.Exit(1)
+	}
+	if err := (&controller.RotationFreezeReconciler{
+		Client: apiClient,
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RotationFreeze")
+		os.Exit(1)
+	}
+	if err := (&controller.RotationReportReconciler{
+		Client: apiClient,
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RotationReport")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// rotatorctl performs one-off rotations without the operator, e.g. to bootstrap
+// secrets from a laptop or a CI pipeline before the operator is installed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+const usage = `Usage: rotatorctl <command> [flags]
+
+Commands:
+  rotate    Generate a new secret and write it to the backend
+
+Run "rotatorctl <command> -h" for the flags of each command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "rotate":
+		err = rotate(ctx, os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		err = fmt.Errorf("unknown command %q", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func rotate(ctx context.Context, args []string) error {
+	var path, policySpec string
+	var fipsMode bool
+	var vaultConfig vault.Config
+	var clientCertFile, clientKeyFile string
+
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	fs.StringVar(&path, "path", "", "Vault path where the new secret is written (e.g. secret/data/my-app/db-creds).")
+	fs.StringVar(&policySpec, "policy", "",
+		"Password policy as comma separated key=value pairs, e.g. \"length=32,symbols=false\".")
+	fs.BoolVar(&fipsMode, "fips-mode", security.FIPSBuild, "Restrict generation to FIPS-approved algorithms.")
+	fs.StringVar(&vaultConfig.Address, "vault-address", "", "The address of the Vault server. Defaults to VAULT_ADDR.")
+	fs.StringVar(&vaultConfig.AuthMethod, "vault-auth-method", vault.AuthToken,
+		"The Vault auth method: token (VAULT_TOKEN) or cert.")
+	fs.StringVar(&vaultConfig.AuthMount, "vault-auth-mount", "", "The mount path of the Vault auth method.")
+	fs.StringVar(&vaultConfig.AuthRole, "vault-auth-role", "", "The Vault role used when logging in.")
+	fs.StringVar(&vaultConfig.CACertFile, "vault-ca-cert", "", "PEM bundle used to verify the Vault server.")
+	fs.StringVar(&clientCertFile, "vault-client-cert", "", "Client certificate file for the Vault cert auth method.")
+	fs.StringVar(&clientKeyFile, "vault-client-key", "", "Client key file for the Vault cert auth method.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if path == "" {
+		return fmt.Errorf("--path is required")
+	}
+	if fipsMode {
+		if err := security.EnableFIPSMode(); err != nil {
+			return err
+		}
+	}
+	if clientCertFile != "" {
+		vaultConfig.ClientCert = vault.NewFileCertificateSource(clientCertFile, clientKeyFile)
+	}
+
+	policy, err := security.ParsePasswordPolicy(policySpec)
+	if err != nil {
+		return err
+	}
+	password, err := policy.Generate()
+	if err != nil {
+		return err
+	}
+	fingerprint, err := security.Fingerprint(password)
+	if err != nil {
+		return err
+	}
+
+	client, err := vault.NewClient(vaultConfig)
+	if err != nil {
+		return err
+	}
+	if err := client.Write(ctx, path, vault.SecretData(map[string]string{"password": password}, "rotatorctl", "")); err != nil {
+		return err
+	}
+
+	// Only the fingerprint is printed: the value never leaves the backend
+	fmt.Printf("rotated %s fingerprint=%s\n", path, fingerprint)
+	return nil
+}
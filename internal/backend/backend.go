@@ -0,0 +1,58 @@
+// Package backend define la interfaz del almacén en el que el operador publica los
+// valores rotados. Las implementaciones viven en los subpaquetes (vault, fake).
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict es el error de una escritura con check-and-set ({"options":
+// {"cas": n}} en el documento, como KV v2) cuya versión ya no es la vigente: otro
+// escritor modificó la ruta después de leerla.
+var ErrVersionConflict = errors.New("la versión de la ruta cambió desde que se leyó")
+
+// SecretBackend es el almacén principal de los valores rotados. Los documentos siguen
+// el formato de KV v2 de Vault: Write recibe {"data": {...}} y Read devuelve el mapa
+// interior, o nil si la ruta no existe. Un documento con {"options": {"cas": n}} solo
+// se escribe si la versión vigente es n; si no, Write devuelve ErrVersionConflict.
+type SecretBackend interface {
+	Read(ctx context.Context, path string) (map[string]interface{}, error)
+	Write(ctx context.Context, path string, data map[string]interface{}) error
+}
+
+// VersionedBackend es un almacén que conserva las versiones anteriores de cada ruta,
+// como KV v2. El operador destruye las anteriores a la vigente cuando termina su periodo
+// de gracia.
+type VersionedBackend interface {
+	// CurrentVersion devuelve la versión vigente de la ruta; cero si no existe.
+	CurrentVersion(ctx context.Context, path string) (int, error)
+	// DestroyVersionsBefore destruye para siempre las versiones anteriores a version.
+	DestroyVersionsBefore(ctx context.Context, path string, version int) error
+}
+
+// ListingBackend es un almacén que enumera y borra rutas. El operador lo usa para podar
+// las copias de seguridad antiguas.
+type ListingBackend interface {
+	// List devuelve los nombres de los secretos directamente bajo path; los que acaban
+	// en "/" son carpetas.
+	List(ctx context.Context, path string) ([]string, error)
+	// Delete borra la ruta con todas sus versiones.
+	Delete(ctx context.Context, path string) error
+}
+
+// DatedBackend es un almacén que sabe cuándo se escribió el valor vigente de cada ruta.
+// El operador lo usa al adoptar un valor que ya existía antes de la Rotation.
+type DatedBackend interface {
+	// UpdatedTime devuelve cuándo se escribió el valor vigente de la ruta; cero si no existe.
+	UpdatedTime(ctx context.Context, path string) (time.Time, error)
+}
+
+// DataKeyBackend es un almacén que genera claves de datos en el servidor y solo entrega
+// su texto cifrado, como el motor transit de Vault: el texto en claro nunca sale de él.
+type DataKeyBackend interface {
+	// GenerateDataKey genera una clave de datos de bits bits, la cifra con la clave key del
+	// motor montado en mount y devuelve solo el texto cifrado.
+	GenerateDataKey(ctx context.Context, mount, key string, bits int) (string, error)
+}
@@ -0,0 +1,64 @@
+// Package consul escribe valores rotados en el almacén KV de HashiCorp Consul a
+// través de su API HTTP.
+package consul
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultAddress es la dirección del agente de Consul dentro de K8s.
+const DefaultAddress = "http://consul-server.consul:8500"
+
+// Client escribe claves en el KV de Consul con un token ACL opcional.
+type Client struct {
+	http       *http.Client
+	address    string
+	token      string
+	datacenter string
+}
+
+// NewClient crea un cliente para la dirección indicada (DefaultAddress si está vacía).
+func NewClient(httpClient *http.Client, address, token, datacenter string) *Client {
+	if address == "" {
+		address = DefaultAddress
+	}
+	return &Client{http: httpClient, address: strings.TrimSuffix(address, "/"), token: token, datacenter: datacenter}
+}
+
+// Put guarda value en la clave indicada.
+func (c *Client) Put(ctx context.Context, key string, value []byte) error {
+	endpoint := c.address + "/v1/kv/" + strings.TrimPrefix(key, "/")
+	if c.datacenter != "" {
+		endpoint += "?dc=" + url.QueryEscape(c.datacenter)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("fallo al construir la petición: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("fallo al escribir en Consul: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul respondió con estado %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	// Consul responde "true" si la escritura se aplicó
+	if strings.TrimSpace(string(body)) != "true" {
+		return fmt.Errorf("consul rechazó la escritura de %q", key)
+	}
+	return nil
+}
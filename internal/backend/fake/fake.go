@@ -0,0 +1,252 @@
+// Package fake implementa en memoria el almacén de secretos, para ejercitar el
+// controlador en tests sin un Vault real.
+package fake
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+)
+
+// Backend guarda los documentos por ruta en memoria. Es seguro para uso concurrente.
+type Backend struct {
+	mu       sync.Mutex
+	docs     map[string]map[string]interface{}
+	versions map[string][]map[string]interface{}
+	writes   map[string]int
+	updated  map[string]time.Time
+	readErr  error
+	writeErr error
+	// pathErrs son los fallos de escritura de rutas concretas
+	pathErrs map[string]error
+
+	hangWrites bool
+}
+
+// New crea un almacén vacío.
+func New() *Backend {
+	return &Backend{
+		docs:     map[string]map[string]interface{}{},
+		versions: map[string][]map[string]interface{}{},
+		writes:   map[string]int{},
+		updated:  map[string]time.Time{},
+		pathErrs: map[string]error{},
+	}
+}
+
+// Read devuelve una copia del documento de la ruta; nil si no existe.
+func (b *Backend) Read(_ context.Context, path string) (map[string]interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.readErr != nil {
+		return nil, b.readErr
+	}
+	doc, ok := b.docs[path]
+	if !ok {
+		return nil, nil
+	}
+	return maps.Clone(doc), nil
+}
+
+// Write guarda el contenido de data["data"] en la ruta como una versión nueva, como KV v2.
+func (b *Backend) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	b.mu.Lock()
+	hang := b.hangWrites
+	b.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writeErr != nil {
+		return b.writeErr
+	}
+	if err := b.pathErrs[path]; err != nil {
+		return err
+	}
+	// Como KV v2, una escritura con check-and-set solo se acepta sobre la versión vigente
+	if options, ok := data["options"].(map[string]interface{}); ok {
+		if cas, ok := options["cas"].(int); ok && cas != len(b.versions[path]) {
+			return backend.ErrVersionConflict
+		}
+	}
+	doc, _ := data["data"].(map[string]interface{})
+	b.docs[path] = maps.Clone(doc)
+	b.versions[path] = append(b.versions[path], maps.Clone(doc))
+	b.writes[path]++
+	b.updated[path] = time.Now()
+	return nil
+}
+
+// UpdatedTime devuelve cuándo se escribió la versión vigente de la ruta; cero si no existe.
+func (b *Backend) UpdatedTime(_ context.Context, path string) (time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.readErr != nil {
+		return time.Time{}, b.readErr
+	}
+	return b.updated[path], nil
+}
+
+// CurrentVersion devuelve la versión vigente de la ruta; cero si no existe.
+func (b *Backend) CurrentVersion(_ context.Context, path string) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.readErr != nil {
+		return 0, b.readErr
+	}
+	return len(b.versions[path]), nil
+}
+
+// DestroyVersionsBefore descarta las versiones de la ruta anteriores a version.
+func (b *Backend) DestroyVersionsBefore(_ context.Context, path string, version int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writeErr != nil {
+		return b.writeErr
+	}
+	for i := range b.versions[path] {
+		if i+1 < version {
+			b.versions[path][i] = nil
+		}
+	}
+	return nil
+}
+
+// List devuelve los nombres de los documentos y carpetas directamente bajo la ruta.
+func (b *Backend) List(_ context.Context, path string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.readErr != nil {
+		return nil, b.readErr
+	}
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	seen := map[string]bool{}
+	var names []string
+	for docPath := range b.docs {
+		rest, ok := strings.CutPrefix(docPath, prefix)
+		if !ok {
+			continue
+		}
+		if folder, _, nested := strings.Cut(rest, "/"); nested {
+			rest = folder + "/"
+		}
+		if !seen[rest] {
+			seen[rest] = true
+			names = append(names, rest)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete borra el documento de la ruta con todas sus versiones.
+func (b *Backend) Delete(_ context.Context, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writeErr != nil {
+		return b.writeErr
+	}
+	delete(b.docs, path)
+	delete(b.versions, path)
+	delete(b.updated, path)
+	return nil
+}
+
+// GenerateDataKey devuelve una clave de datos aleatoria con el formato del texto cifrado
+// de transit ("vault:v1:<base64>"), como si la hubiera cifrado la clave key.
+func (b *Backend) GenerateDataKey(_ context.Context, _, _ string, bits int) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writeErr != nil {
+		return "", b.writeErr
+	}
+	key := make([]byte, bits/8)
+	_, _ = rand.Read(key)
+	return "vault:v1:" + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Seed guarda doc en la ruta como si se hubiera escrito en updated, sin contarlo entre
+// las escrituras: simula un secreto que ya existía.
+func (b *Backend) Seed(path string, doc map[string]interface{}, updated time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.docs[path] = maps.Clone(doc)
+	b.versions[path] = append(b.versions[path], maps.Clone(doc))
+	b.updated[path] = updated
+}
+
+// Get devuelve el documento de la ruta sin pasar por los errores inyectados.
+func (b *Backend) Get(path string) map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return maps.Clone(b.docs[path])
+}
+
+// Version devuelve la versión indicada de la ruta, empezando en 1; nil si no existe o
+// se destruyó.
+func (b *Backend) Version(path string, version int) map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if version < 1 || version > len(b.versions[path]) {
+		return nil
+	}
+	return maps.Clone(b.versions[path][version-1])
+}
+
+// Writes devuelve cuántas escrituras correctas recibió la ruta.
+func (b *Backend) Writes(path string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.writes[path]
+}
+
+// FailReads hace que las lecturas devuelvan err hasta que se llame con nil.
+func (b *Backend) FailReads(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readErr = err
+}
+
+// FailWrites hace que las escrituras devuelvan err hasta que se llame con nil.
+func (b *Backend) FailWrites(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.writeErr = err
+}
+
+// FailPathWrites hace que las escrituras en path devuelvan err hasta que se llame con nil.
+func (b *Backend) FailPathWrites(path string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.pathErrs, path)
+		return
+	}
+	b.pathErrs[path] = err
+}
+
+// HangWrites hace que las escrituras no terminen hasta que se cancele su contexto,
+// como un backend colgado, hasta que se llame con false.
+func (b *Backend) HangWrites(hang bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hangWrites = hang
+}
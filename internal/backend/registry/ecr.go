@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/cloud/aws"
+)
+
+// ECRAuthorization es un token de autorización de Amazon ECR y el servidor al que aplica.
+type ECRAuthorization struct {
+	Credential
+	// Server es el endpoint del registro (e.g., "123456789012.dkr.ecr.eu-west-1.amazonaws.com").
+	Server string
+}
+
+// ECRAuthorizationToken obtiene con ecr:GetAuthorizationToken un token del registro de
+// la cuenta registryID (la de las credenciales si está vacía). Los tokens caducan a las 12 horas.
+func ECRAuthorizationToken(ctx context.Context, httpClient *http.Client, creds aws.Credentials, region, registryID string) (ECRAuthorization, error) {
+	input := map[string][]string{}
+	if registryID != "" {
+		input["registryIds"] = []string{registryID}
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return ECRAuthorization{}, err
+	}
+
+	endpoint := "https://api.ecr." + region + ".amazonaws.com/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return ECRAuthorization{}, fmt.Errorf("fallo al construir la petición: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+	aws.Sign(req, body, creds, region, "ecr", time.Now())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ECRAuthorization{}, fmt.Errorf("fallo al llamar a ECR: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(resp, "ecr"); err != nil {
+		return ECRAuthorization{}, err
+	}
+
+	var out struct {
+		AuthorizationData []struct {
+			AuthorizationToken string  `json:"authorizationToken"`
+			ExpiresAt          float64 `json:"expiresAt"`
+			ProxyEndpoint      string  `json:"proxyEndpoint"`
+		} `json:"authorizationData"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return ECRAuthorization{}, fmt.Errorf("respuesta de ECR no válida: %w", err)
+	}
+	if len(out.AuthorizationData) == 0 {
+		return ECRAuthorization{}, fmt.Errorf("ECR no devolvió ningún token de autorización")
+	}
+	data := out.AuthorizationData[0]
+
+	// El token es "AWS:<contraseña>" en base64
+	decoded, err := base64.StdEncoding.DecodeString(data.AuthorizationToken)
+	if err != nil {
+		return ECRAuthorization{}, fmt.Errorf("token de ECR no válido: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return ECRAuthorization{}, fmt.Errorf("token de ECR sin usuario")
+	}
+
+	secs, frac := math.Modf(data.ExpiresAt)
+	return ECRAuthorization{
+		Credential: Credential{
+			Username: username,
+			Password: password,
+			Expires:  time.Unix(int64(secs), int64(frac*1e9)),
+		},
+		Server: strings.TrimPrefix(data.ProxyEndpoint, "https://"),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HarborClient llama a la API v2.0 de Harbor con una cuenta administradora.
+type HarborClient struct {
+	http     *http.Client
+	url      string
+	username string
+	password string
+}
+
+// NewHarborClient crea un cliente para la URL base de Harbor (e.g., "https://harbor.example.com").
+func NewHarborClient(httpClient *http.Client, baseURL, username, password string) *HarborClient {
+	return &HarborClient{http: httpClient, url: strings.TrimSuffix(baseURL, "/"), username: username, password: password}
+}
+
+// RefreshRobotSecret fija secret como nuevo secreto de la cuenta robot. Harbor exige
+// entre 8 y 128 caracteres con al menos una mayúscula, una minúscula y un dígito.
+func (c *HarborClient) RefreshRobotSecret(ctx context.Context, robotID int64, secret string) error {
+	body, err := json.Marshal(map[string]string{"secret": secret})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/api/v2.0/robots/%d", c.url, robotID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("fallo al construir la petición: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("fallo al llamar a Harbor: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return checkResponse(resp, "harbor")
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// QuayClient llama a la API v1 de Quay con un token OAuth de aplicación.
+type QuayClient struct {
+	http  *http.Client
+	url   string
+	token string
+}
+
+// NewQuayClient crea un cliente para la URL base de Quay (e.g., "https://quay.io").
+func NewQuayClient(httpClient *http.Client, baseURL, token string) *QuayClient {
+	return &QuayClient{http: httpClient, url: strings.TrimSuffix(baseURL, "/"), token: token}
+}
+
+// RegenerateRobotToken genera un token nuevo para la cuenta robot "<organización>+<nombre>";
+// el anterior deja de ser válido. Quay elige el valor.
+func (c *QuayClient) RegenerateRobotToken(ctx context.Context, robot string) (Credential, error) {
+	org, name, ok := strings.Cut(robot, "+")
+	if !ok {
+		return Credential{}, fmt.Errorf("la cuenta robot %q no tiene la forma <organización>+<nombre>", robot)
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/organization/%s/robots/%s/regenerate", c.url, url.PathEscape(org), url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return Credential{}, fmt.Errorf("fallo al construir la petición: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Credential{}, fmt.Errorf("fallo al llamar a Quay: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(resp, "quay"); err != nil {
+		return Credential{}, err
+	}
+
+	var out struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Credential{}, fmt.Errorf("respuesta de Quay no válida: %w", err)
+	}
+	if out.Token == "" {
+		return Credential{}, fmt.Errorf("quay no devolvió el token de %q", robot)
+	}
+	return Credential{Username: out.Name, Password: out.Token}, nil
+}
@@ -0,0 +1,47 @@
+// Package registry rota credenciales de cuentas robot en registros de imágenes (Harbor,
+// Quay y Amazon ECR) y compone el .dockerconfigjson que usan los Pods para descargar
+// imágenes.
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Credential es una credencial de registro recién emitida.
+type Credential struct {
+	Username string
+	Password string
+	// Expires es cero si la credencial no caduca por sí sola.
+	Expires time.Time
+}
+
+// dockerAuth es la entrada de un servidor en el fichero de configuración de Docker.
+type dockerAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// DockerConfigJSON compone el contenido de un Secret kubernetes.io/dockerconfigjson
+// con la credencial para el servidor indicado.
+func DockerConfigJSON(server string, cred Credential) ([]byte, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))
+	return json.Marshal(map[string]map[string]dockerAuth{
+		"auths": {server: {Username: cred.Username, Password: cred.Password, Auth: auth}},
+	})
+}
+
+// checkResponse devuelve un error con el principio del cuerpo si el estado no es 2xx.
+func checkResponse(resp *http.Response, api string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s respondió con estado %d: %s", api, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,195 @@
+// Package sopsgit publica valores rotados como ficheros cifrados con SOPS en un
+// repositorio Git. Usa los binarios git, ssh y sops del sistema, incluidos en la imagen
+// del operador.
+package sopsgit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBranch es la rama usada si no se indica otra.
+const DefaultBranch = "main"
+
+// Identidad con la que el operador firma los commits, como autor y como committer.
+const (
+	AuthorName  = "secret-rotator-operator"
+	AuthorEmail = "secret-rotator-operator@users.noreply.github.com"
+)
+
+// Config describe el repositorio, el fichero de destino y los destinatarios SOPS.
+type Config struct {
+	// Repository es la URL del repositorio (https:// o ssh).
+	Repository string
+	// Branch es la rama a la que se hace push (por defecto, DefaultBranch).
+	Branch string
+	// Path es la ruta del fichero dentro del repositorio; ".json" cifra en JSON, el resto en YAML.
+	Path string
+	// AgeRecipients son las claves públicas age que pueden descifrar el fichero.
+	AgeRecipients []string
+	// KMSKeys son ARNs de claves de AWS KMS que pueden descifrar el fichero.
+	KMSKeys []string
+
+	// Username y Password autentican por HTTPS.
+	Username string
+	Password string
+	// SSHKey es la clave privada para repositorios ssh; KnownHosts, si existe, fija sus claves de host.
+	SSHKey     []byte
+	KnownHosts []byte
+}
+
+// Commit cifra values con SOPS, los escribe en Path y publica el cambio con el mensaje
+// indicado en la rama configurada.
+func Commit(ctx context.Context, cfg Config, values map[string]string, message string) error {
+	if len(cfg.AgeRecipients) == 0 && len(cfg.KMSKeys) == 0 {
+		return errors.New("se necesita al menos un destinatario age o una clave KMS")
+	}
+	if cfg.Branch == "" {
+		cfg.Branch = DefaultBranch
+	}
+
+	work, err := os.MkdirTemp("", "sopsgit-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	env, err := gitEnv(cfg, work)
+	if err != nil {
+		return err
+	}
+
+	repo := filepath.Join(work, "repo")
+	if err := run(ctx, env, "", nil, "git", "clone", "--depth", "1", "--branch", cfg.Branch, cfg.Repository, repo); err != nil {
+		return err
+	}
+
+	encrypted, err := encrypt(ctx, cfg, work, values)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(repo, filepath.FromSlash(cfg.Path))
+	if !strings.HasPrefix(target, repo+string(os.PathSeparator)) {
+		return fmt.Errorf("ruta fuera del repositorio: %q", cfg.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, encrypted, 0o644); err != nil {
+		return err
+	}
+
+	if err := run(ctx, env, repo, nil, "git", "add", "--", cfg.Path); err != nil {
+		return err
+	}
+	// Sin cambios preparados no hay nada que publicar
+	if err := run(ctx, env, repo, nil, "git", "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	if err := run(ctx, env, repo, nil, "git", "commit", "-m", message); err != nil {
+		return err
+	}
+	return run(ctx, env, repo, nil, "git", "push", "origin", "HEAD:"+cfg.Branch)
+}
+
+// encrypt cifra el documento en claro sin que este llegue a escribirse en el repositorio.
+func encrypt(ctx context.Context, cfg Config, work string, values map[string]string) ([]byte, error) {
+	format := "yaml"
+	if strings.HasSuffix(cfg.Path, ".json") {
+		format = "json"
+	}
+
+	// JSON es YAML válido: sirve de entrada para ambos formatos
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	plainFile := filepath.Join(work, "plain."+format)
+	if err := os.WriteFile(plainFile, plain, 0o600); err != nil {
+		return nil, err
+	}
+	defer os.Remove(plainFile)
+
+	args := []string{"--encrypt", "--input-type", format, "--output-type", format}
+	if len(cfg.AgeRecipients) > 0 {
+		args = append(args, "--age", strings.Join(cfg.AgeRecipients, ","))
+	}
+	if len(cfg.KMSKeys) > 0 {
+		args = append(args, "--kms", strings.Join(cfg.KMSKeys, ","))
+	}
+	args = append(args, plainFile)
+
+	var out bytes.Buffer
+	if err := run(ctx, nil, "", &out, "sops", args...); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// gitEnv prepara la identidad y las credenciales de git sin exponerlas en los argumentos
+// del proceso. HOME apunta al directorio de trabajo: en el contenedor no hay otro
+// escribible ni una configuración de git con la que identificarse.
+func gitEnv(cfg Config, work string) ([]string, error) {
+	env := []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"HOME=" + work,
+		"GIT_AUTHOR_NAME=" + AuthorName,
+		"GIT_AUTHOR_EMAIL=" + AuthorEmail,
+		"GIT_COMMITTER_NAME=" + AuthorName,
+		"GIT_COMMITTER_EMAIL=" + AuthorEmail,
+	}
+
+	if cfg.Username != "" || cfg.Password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Password))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+		)
+	}
+
+	if len(cfg.SSHKey) > 0 {
+		keyFile := filepath.Join(work, "id")
+		if err := os.WriteFile(keyFile, cfg.SSHKey, 0o600); err != nil {
+			return nil, err
+		}
+		ssh := "ssh -i " + keyFile + " -o IdentitiesOnly=yes"
+		if len(cfg.KnownHosts) > 0 {
+			knownHosts := filepath.Join(work, "known_hosts")
+			if err := os.WriteFile(knownHosts, cfg.KnownHosts, 0o600); err != nil {
+				return nil, err
+			}
+			ssh += " -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + knownHosts
+		} else {
+			// ssh no usa HOME sino el directorio del usuario, que no es escribible
+			ssh += " -o StrictHostKeyChecking=accept-new -o UserKnownHostsFile=" + filepath.Join(work, "known_hosts")
+		}
+		env = append(env, "GIT_SSH_COMMAND="+ssh)
+	}
+	return env, nil
+}
+
+// run ejecuta un comando y devuelve su salida de error si falla.
+func run(ctx context.Context, env []string, dir string, stdout *bytes.Buffer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/cloud/aws"
+)
+
+// awsServerIDHeader liga la petición firmada a un servidor de Vault concreto cuando el
+// montaje aws exige iam_server_id_header_value.
+const awsServerIDHeader = "X-Vault-AWS-IAM-Server-ID"
+
+// loginAWS inicia sesión con el método aws (tipo iam): firma una llamada a
+// sts:GetCallerIdentity con las credenciales del Pod y Vault la reenvía a AWS.
+func (c *Client) loginAWS(ctx context.Context) error {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	creds, err := aws.CredentialsFromEnv(ctx, httpClient)
+	if err != nil {
+		return err
+	}
+
+	region := c.cfg.AWSRegion
+	if region == "" {
+		region = aws.DefaultRegion
+	}
+	body := []byte("Action=GetCallerIdentity&Version=2011-06-15")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, aws.STSEndpoint(region), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if c.cfg.AWSServerID != "" {
+		req.Header.Set(awsServerIDHeader, c.cfg.AWSServerID)
+	}
+	aws.Sign(req, body, creds, region, "sts", time.Now())
+
+	headers, err := json.Marshal(req.Header)
+	if err != nil {
+		return fmt.Errorf("fallo al serializar las cabeceras firmadas: %w", err)
+	}
+
+	return c.loginWith(ctx, AuthAWS, map[string]interface{}{
+		"role":                    c.cfg.AuthRole,
+		"iam_http_request_method": req.Method,
+		"iam_request_url":         base64.StdEncoding.EncodeToString([]byte(req.URL.String())),
+		"iam_request_body":        base64.StdEncoding.EncodeToString(body),
+		"iam_request_headers":     base64.StdEncoding.EncodeToString(headers),
+	})
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/cloud/azure"
+)
+
+// loginAzure inicia sesión con el método azure usando un token de la identidad del Pod
+// (Workload Identity) o de la identidad administrada del nodo.
+func (c *Client) loginAzure(ctx context.Context) error {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+
+	resource := c.cfg.AzureResource
+	if resource == "" {
+		resource = azure.DefaultResource
+	}
+	jwt, err := azure.Token(ctx, httpClient, resource)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"role":                c.cfg.AuthRole,
+		"jwt":                 jwt,
+		"subscription_id":     c.cfg.AzureSubscriptionID,
+		"resource_group_name": c.cfg.AzureResourceGroup,
+	}
+	// Sin suscripción configurada se toman los datos del nodo; fuera de Azure IMDS no
+	// responde y Vault decidirá con lo que el rol exija
+	if c.cfg.AzureSubscriptionID == "" {
+		if instance, err := azure.InstanceMetadata(ctx, httpClient); err == nil {
+			payload["subscription_id"] = instance.SubscriptionID
+			payload["resource_group_name"] = instance.ResourceGroupName
+			if instance.VMScaleSetName != "" {
+				payload["vmss_name"] = instance.VMScaleSetName
+			} else {
+				payload["vm_name"] = instance.Name
+			}
+		}
+	}
+
+	return c.loginWith(ctx, AuthAzure, payload)
+}
@@ -0,0 +1,93 @@
+package vault
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertificateSource proporciona el certificado de cliente vigente.
+type CertificateSource interface {
+	Certificate() (*tls.Certificate, error)
+}
+
+// FileCertificateSource lee un par certificado/clave de disco y lo recarga cuando
+// cambia (por ejemplo, al rotar el Secret montado en el Pod).
+type FileCertificateSource struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewFileCertificateSource crea una fuente que recarga los ficheros indicados al cambiar.
+func NewFileCertificateSource(certFile, keyFile string) *FileCertificateSource {
+	return &FileCertificateSource{certFile: certFile, keyFile: keyFile}
+}
+
+// Certificate devuelve el certificado en caché o lo recarga si los ficheros cambiaron.
+func (s *FileCertificateSource) Certificate() (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	modTime, err := latestModTime(s.certFile, s.keyFile)
+	if err != nil {
+		if s.cert != nil {
+			return s.cert, nil
+		}
+		return nil, err
+	}
+	if s.cert != nil && modTime.Equal(s.modTime) {
+		return s.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		// Durante una rotación el par puede estar a medio escribir: seguimos con el anterior
+		if s.cert != nil {
+			return s.cert, nil
+		}
+		return nil, fmt.Errorf("fallo al cargar el certificado de cliente de Vault: %w", err)
+	}
+
+	s.cert = &cert
+	s.modTime = modTime
+	return s.cert, nil
+}
+
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("fallo al leer %s: %w", f, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// PEMCertificateSource sirve un certificado fijo cargado desde PEM (p. ej. un Secret).
+type PEMCertificateSource struct {
+	cert *tls.Certificate
+}
+
+// NewPEMCertificateSource valida el par PEM y devuelve una fuente que siempre lo sirve.
+func NewPEMCertificateSource(certPEM, keyPEM []byte) (*PEMCertificateSource, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("certificado de cliente de Vault no válido: %w", err)
+	}
+	return &PEMCertificateSource{cert: &cert}, nil
+}
+
+// Certificate devuelve el certificado cargado.
+func (s *PEMCertificateSource) Certificate() (*tls.Certificate, error) {
+	return s.cert, nil
+}
@@ -0,0 +1,284 @@
+package vault
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+)
+
+// DefaultAddress es la dirección de Vault dentro de K8s.
+const DefaultAddress = "http://vault.vault-system:8200"
+
+// Métodos de autenticación soportados.
+const (
+	// AuthToken usa el token de VAULT_TOKEN (o ninguno, en modo mock).
+	AuthToken = "token"
+	// AuthCert usa el método cert con un certificado de cliente TLS.
+	AuthCert = "cert"
+	// AuthJWT usa el método jwt con el token de JWTFile (e.g., un JWT-SVID de SPIFFE).
+	AuthJWT = "jwt"
+	// AuthAWS usa el método aws (iam) con las credenciales de IRSA o del entorno.
+	AuthAWS = "aws"
+	// AuthGCP usa el método gcp con la identidad del servidor de metadatos.
+	AuthGCP = "gcp"
+	// AuthAzure usa el método azure con Workload Identity o una identidad administrada.
+	AuthAzure = "azure"
+)
+
+// Config describe cómo conectar y autenticarse contra Vault.
+type Config struct {
+	// Address de Vault; si está vacío se usa DefaultAddress.
+	Address string
+	// AuthMethod es uno de AuthToken o AuthCert.
+	AuthMethod string
+	// AuthMount es la ruta de montaje del método de autenticación (por defecto, su nombre).
+	AuthMount string
+	// AuthRole es el rol (parámetro "name" en cert) usado al iniciar sesión.
+	AuthRole string
+	// CACertFile es un bundle PEM opcional para verificar el servidor de Vault.
+	CACertFile string
+	// ClientCert proporciona el certificado de cliente presentado en cada handshake TLS.
+	ClientCert CertificateSource
+	// JWTFile es el fichero con el token del método jwt; se relee en cada login porque
+	// quien lo emite lo renueva en disco.
+	JWTFile string
+	// AWSRegion es la región del endpoint de STS firmado en el método aws; vacía usa el
+	// endpoint global, que es el que Vault espera por defecto.
+	AWSRegion string
+	// AWSServerID es el valor de la cabecera X-Vault-AWS-IAM-Server-ID, si el montaje lo exige.
+	AWSServerID string
+	// GCPRoleType es el tipo del rol gcp: GCPRoleIAM (por defecto) o GCPRoleGCE.
+	GCPRoleType string
+	// AzureResource es el recurso del token presentado en el método azure (por defecto,
+	// el de Azure Resource Manager).
+	AzureResource string
+	// AzureSubscriptionID y AzureResourceGroup identifican el origen del login; si
+	// están vacíos se consultan en IMDS.
+	AzureSubscriptionID string
+	AzureResourceGroup  string
+}
+
+// AttemptKey es la clave del documento que guarda el identificador del intento de rotación.
+const AttemptKey = "rotation_id"
+
+// SecretData construye el documento KV v2 con los valores rotados. Si attemptID no está
+// vacío se guarda junto a ellos para poder reconocer la escritura al reintentar.
+func SecretData(values map[string]string, rotatedBy, attemptID string) map[string]interface{} {
+	data := map[string]interface{}{
+		"rotated_by": rotatedBy,
+	}
+	for key, value := range values {
+		data[key] = value
+	}
+	if attemptID != "" {
+		data[AttemptKey] = attemptID
+	}
+	return map[string]interface{}{"data": data}
+}
+
+// casMismatch es el mensaje con el que KV v2 rechaza una escritura cuyo check-and-set
+// no coincide con la versión vigente.
+const casMismatch = "check-and-set parameter did not match the current version"
+
+// tokenRenewMargin es la antelación con la que se renueva una sesión antes de que caduque.
+const tokenRenewMargin = 30 * time.Second
+
+// Client envuelve el cliente oficial de Vault con la autenticación configurada.
+// Un mismo Client reutiliza su sesión mientras el token sea válido.
+type Client struct {
+	api       *api.Client
+	transport *http.Transport
+	cfg       Config
+
+	mu          sync.Mutex
+	lastCert    *tls.Certificate
+	loggedIn    bool
+	tokenExpiry time.Time
+}
+
+// NewClient crea un cliente de Vault a partir de la configuración indicada.
+func NewClient(cfg Config) (*Client, error) {
+	// DefaultConfig ya respeta VAULT_ADDR; solo usamos la dirección interna si no está definida
+	apiConfig := api.DefaultConfig()
+	switch {
+	case cfg.Address != "":
+		apiConfig.Address = cfg.Address
+	case os.Getenv(api.EnvVaultAddress) == "":
+		apiConfig.Address = DefaultAddress
+	}
+
+	if cfg.CACertFile != "" {
+		if err := apiConfig.ConfigureTLS(&api.TLSConfig{CACert: cfg.CACertFile}); err != nil {
+			return nil, fmt.Errorf("fallo al configurar la CA de Vault: %w", err)
+		}
+	}
+
+	transport, ok := apiConfig.HttpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("transporte HTTP de Vault inesperado: %T", apiConfig.HttpClient.Transport)
+	}
+	if cfg.ClientCert != nil {
+		// El certificado se resuelve en cada handshake para recoger rotaciones en disco
+		transport.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cfg.ClientCert.Certificate()
+		}
+	}
+
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		return nil, fmt.Errorf("fallo al crear el cliente de Vault: %w", err)
+	}
+
+	return &Client{api: client, transport: transport, cfg: cfg}, nil
+}
+
+// Write autentica (si es necesario) y escribe los datos en la ruta indicada.
+func (c *Client) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	log := logf.FromContext(ctx).WithName("VaultWriter").WithValues("path", path)
+
+	if err := c.login(ctx); err != nil {
+		return err
+	}
+
+	// Sin credenciales no hay Vault real al que escribir: mantenemos el modo mock
+	if c.api.Token() == "" {
+		log.Info("ADVERTENCIA: Usando Vault MOCK. Asumiendo éxito en la escritura.")
+		return nil
+	}
+
+	if _, err := c.api.Logical().WriteWithContext(ctx, path, data); err != nil {
+		// Un check-and-set rechazado no invalida la sesión: otro escritor se adelantó
+		if strings.Contains(err.Error(), casMismatch) {
+			return fmt.Errorf("%w: %v", backend.ErrVersionConflict, err)
+		}
+		// La sesión pudo revocarse: la siguiente escritura vuelve a autenticarse
+		c.invalidate()
+		return fmt.Errorf("fallo al escribir en Vault: %w", err)
+	}
+	return nil
+}
+
+// Read autentica (si es necesario) y devuelve los datos KV v2 de la ruta indicada, o
+// nil si no existen (o en modo mock).
+func (c *Client) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	if c.api.Token() == "" {
+		return nil, nil
+	}
+
+	secret, err := c.api.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		c.invalidate()
+		return nil, fmt.Errorf("fallo al leer de Vault: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+	data, _ := secret.Data["data"].(map[string]interface{})
+	return data, nil
+}
+
+// login obtiene un token según el método de autenticación configurado, salvo que
+// la sesión actual siga vigente. Las escrituras concurrentes esperan a un único login.
+func (c *Client) login(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loggedIn && (c.tokenExpiry.IsZero() || time.Until(c.tokenExpiry) > tokenRenewMargin) {
+		return nil
+	}
+
+	switch c.cfg.AuthMethod {
+	case "", AuthToken:
+		// El token, si existe, ya se leyó de VAULT_TOKEN al crear el cliente
+		return nil
+	case AuthCert:
+		return c.loginCert(ctx)
+	case AuthJWT:
+		return c.loginJWT(ctx)
+	case AuthAWS:
+		return c.loginAWS(ctx)
+	case AuthGCP:
+		return c.loginGCP(ctx)
+	case AuthAzure:
+		return c.loginAzure(ctx)
+	default:
+		return fmt.Errorf("método de autenticación de Vault no soportado: %q", c.cfg.AuthMethod)
+	}
+}
+
+func (c *Client) loginCert(ctx context.Context) error {
+	if c.cfg.ClientCert == nil {
+		return fmt.Errorf("el método cert requiere un certificado de cliente")
+	}
+
+	// Si el certificado cambió, descartamos conexiones que aún presentan el anterior
+	cert, err := c.cfg.ClientCert.Certificate()
+	if err != nil {
+		return err
+	}
+	if c.lastCert != nil && c.lastCert != cert {
+		c.transport.CloseIdleConnections()
+	}
+	c.lastCert = cert
+
+	return c.loginWith(ctx, AuthCert, map[string]interface{}{"name": c.cfg.AuthRole})
+}
+
+func (c *Client) loginJWT(ctx context.Context) error {
+	if c.cfg.JWTFile == "" {
+		return fmt.Errorf("el método jwt requiere un fichero de token")
+	}
+	token, err := os.ReadFile(c.cfg.JWTFile)
+	if err != nil {
+		return fmt.Errorf("fallo al leer el token JWT: %w", err)
+	}
+
+	return c.loginWith(ctx, AuthJWT, map[string]interface{}{
+		"role": c.cfg.AuthRole,
+		"jwt":  strings.TrimSpace(string(token)),
+	})
+}
+
+// loginWith inicia sesión en auth/<mount>/login y adopta el token devuelto.
+func (c *Client) loginWith(ctx context.Context, method string, payload map[string]interface{}) error {
+	mount := c.cfg.AuthMount
+	if mount == "" {
+		mount = method
+	}
+
+	secret, err := c.api.Logical().WriteWithContext(ctx, "auth/"+mount+"/login", payload)
+	if err != nil {
+		return fmt.Errorf("fallo al autenticar en Vault con %s: %w", method, err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return fmt.Errorf("no se obtuvo un token de Vault al autenticar con %s", method)
+	}
+
+	c.api.SetToken(secret.Auth.ClientToken)
+	c.loggedIn = true
+	c.tokenExpiry = time.Time{}
+	if secret.Auth.LeaseDuration > 0 {
+		c.tokenExpiry = time.Now().Add(time.Duration(secret.Auth.LeaseDuration) * time.Second)
+	}
+	return nil
+}
+
+// invalidate descarta la sesión para forzar un nuevo login.
+func (c *Client) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loggedIn = false
+}
@@ -0,0 +1,50 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/cloud/gcp"
+)
+
+// Tipos de rol del método gcp de Vault.
+const (
+	// GCPRoleIAM firma el JWT como la cuenta de servicio (Workload Identity en GKE).
+	GCPRoleIAM = "iam"
+	// GCPRoleGCE presenta el token de identidad de la instancia firmado por Google.
+	GCPRoleGCE = "gce"
+)
+
+// loginGCP inicia sesión con el método gcp. La audiencia "vault/<rol>" es la que Vault
+// exige en ambos tipos de rol.
+func (c *Client) loginGCP(ctx context.Context) error {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	audience := "vault/" + c.cfg.AuthRole
+
+	var jwt string
+	var err error
+	switch c.cfg.GCPRoleType {
+	case "", GCPRoleIAM:
+		var email string
+		email, err = gcp.ServiceAccountEmail(ctx, httpClient)
+		if err != nil {
+			return err
+		}
+		jwt, err = gcp.SignJWT(ctx, httpClient, email, map[string]interface{}{
+			"aud": audience,
+			"sub": email,
+			"exp": time.Now().Add(10 * time.Minute).Unix(),
+		})
+	case GCPRoleGCE:
+		jwt, err = gcp.IdentityToken(ctx, httpClient, audience)
+	default:
+		return fmt.Errorf("tipo de rol gcp no soportado: %q", c.cfg.GCPRoleType)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.loginWith(ctx, AuthGCP, map[string]interface{}{"role": c.cfg.AuthRole, "jwt": jwt})
+}
@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sessions reparte las escrituras entre clientes de Vault por montaje, de forma que
+// todas las Rotations bajo un mismo montaje comparten una única sesión: el número de
+// logins pasa a depender de los montajes y no de las rotaciones.
+type Sessions struct {
+	cfg Config
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewSessions crea un conjunto de sesiones con la configuración indicada.
+func NewSessions(cfg Config) *Sessions {
+	return &Sessions{cfg: cfg, clients: map[string]*Client{}}
+}
+
+// Write escribe los datos reutilizando la sesión del montaje de la ruta.
+func (s *Sessions) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return err
+	}
+	return client.Write(ctx, path, data)
+}
+
+// Read lee los datos reutilizando la sesión del montaje de la ruta.
+func (s *Sessions) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return nil, err
+	}
+	return client.Read(ctx, path)
+}
+
+// CurrentVersion devuelve la versión vigente reutilizando la sesión del montaje de la ruta.
+func (s *Sessions) CurrentVersion(ctx context.Context, path string) (int, error) {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return 0, err
+	}
+	return client.CurrentVersion(ctx, path)
+}
+
+// UpdatedTime devuelve cuándo se escribió el valor vigente reutilizando la sesión del
+// montaje de la ruta.
+func (s *Sessions) UpdatedTime(ctx context.Context, path string) (time.Time, error) {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return client.UpdatedTime(ctx, path)
+}
+
+// DestroyVersionsBefore destruye las versiones anteriores reutilizando la sesión del
+// montaje de la ruta.
+func (s *Sessions) DestroyVersionsBefore(ctx context.Context, path string, version int) error {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return err
+	}
+	return client.DestroyVersionsBefore(ctx, path, version)
+}
+
+// List enumera los secretos reutilizando la sesión del montaje de la ruta.
+func (s *Sessions) List(ctx context.Context, path string) ([]string, error) {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return nil, err
+	}
+	return client.List(ctx, path)
+}
+
+// Delete borra el secreto reutilizando la sesión del montaje de la ruta.
+func (s *Sessions) Delete(ctx context.Context, path string) error {
+	client, err := s.client(Mount(path))
+	if err != nil {
+		return err
+	}
+	return client.Delete(ctx, path)
+}
+
+// GenerateDataKey genera una clave de datos reutilizando la sesión del montaje de transit.
+func (s *Sessions) GenerateDataKey(ctx context.Context, mount, key string, bits int) (string, error) {
+	client, err := s.client(Mount(mount))
+	if err != nil {
+		return "", err
+	}
+	return client.GenerateDataKey(ctx, mount, key, bits)
+}
+
+func (s *Sessions) client(mount string) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[mount]; ok {
+		return client, nil
+	}
+	client, err := NewClient(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[mount] = client
+	return client, nil
+}
+
+// Mount devuelve el montaje de una ruta de Vault (su primer segmento).
+func Mount(path string) string {
+	mount, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return mount
+}
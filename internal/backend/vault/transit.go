@@ -0,0 +1,36 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GenerateDataKey genera una clave de datos con transit/datakey/wrapped del motor montado
+// en mount, cifrada con la clave key, y devuelve solo su texto cifrado: Vault no llega a
+// enviar el texto en claro.
+func (c *Client) GenerateDataKey(ctx context.Context, mount, key string, bits int) (string, error) {
+	if err := c.login(ctx); err != nil {
+		return "", err
+	}
+	// En modo mock no hay transit que genere la clave y el operador no la inventa
+	if c.api.Token() == "" {
+		return "", errors.New("la generación en el servidor necesita un Vault real")
+	}
+
+	path := strings.Trim(mount, "/") + "/datakey/wrapped/" + key
+	secret, err := c.api.Logical().WriteWithContext(ctx, path, map[string]interface{}{"bits": bits})
+	if err != nil {
+		c.invalidate()
+		return "", fmt.Errorf("fallo al generar la clave de datos en Vault: %w", err)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("vault no devolvió la clave de datos de %s", path)
+	}
+	ciphertext, _ := secret.Data["ciphertext"].(string)
+	if ciphertext == "" {
+		return "", fmt.Errorf("vault no devolvió el texto cifrado de la clave de datos de %s", path)
+	}
+	return ciphertext, nil
+}
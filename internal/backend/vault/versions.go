@@ -0,0 +1,179 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CurrentVersion devuelve la versión KV v2 vigente de la ruta, leída de sus metadatos;
+// cero si no existe (o en modo mock).
+func (c *Client) CurrentVersion(ctx context.Context, path string) (int, error) {
+	metadata, err := c.metadata(ctx, path)
+	if err != nil || metadata == nil {
+		return 0, err
+	}
+	return intValue(metadata["current_version"])
+}
+
+// UpdatedTime devuelve cuándo se escribió la versión KV v2 vigente de la ruta, leído de
+// sus metadatos; cero si no existe (o en modo mock).
+func (c *Client) UpdatedTime(ctx context.Context, path string) (time.Time, error) {
+	metadata, err := c.metadata(ctx, path)
+	if err != nil || metadata == nil {
+		return time.Time{}, err
+	}
+	updated, _ := metadata["updated_time"].(string)
+	if updated == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, updated)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha de actualización de Vault no válida %q: %w", updated, err)
+	}
+	return t, nil
+}
+
+// DestroyVersionsBefore destruye de forma permanente las versiones KV v2 de la ruta
+// anteriores a version que aún no lo estén. A diferencia de un borrado, una versión
+// destruida no se puede recuperar.
+func (c *Client) DestroyVersionsBefore(ctx context.Context, path string, version int) error {
+	metadata, err := c.metadata(ctx, path)
+	if err != nil || metadata == nil {
+		return err
+	}
+
+	versions, _ := metadata["versions"].(map[string]interface{})
+	var destroy []int
+	for key, raw := range versions {
+		number, err := strconv.Atoi(key)
+		if err != nil || number >= version {
+			continue
+		}
+		if info, _ := raw.(map[string]interface{}); info["destroyed"] == true {
+			continue
+		}
+		destroy = append(destroy, number)
+	}
+	if len(destroy) == 0 {
+		return nil
+	}
+	sort.Ints(destroy)
+
+	destroyPath, err := kvPath(path, "destroy")
+	if err != nil {
+		return err
+	}
+	if _, err := c.api.Logical().WriteWithContext(ctx, destroyPath, map[string]interface{}{"versions": destroy}); err != nil {
+		c.invalidate()
+		return fmt.Errorf("fallo al destruir las versiones anteriores en Vault: %w", err)
+	}
+	return nil
+}
+
+// List devuelve los nombres de los secretos KV v2 bajo la ruta; ninguno si no existe
+// (o en modo mock).
+func (c *Client) List(ctx context.Context, path string) ([]string, error) {
+	metadataPath, err := kvPath(path, "metadata")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	if c.api.Token() == "" {
+		return nil, nil
+	}
+
+	secret, err := c.api.Logical().ListWithContext(ctx, metadataPath)
+	if err != nil {
+		c.invalidate()
+		return nil, fmt.Errorf("fallo al listar en Vault: %w", err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	keys, _ := secret.Data["keys"].([]interface{})
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if name, ok := key.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Delete borra de forma permanente el secreto KV v2 de la ruta con todas sus versiones
+// y metadatos.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	metadataPath, err := kvPath(path, "metadata")
+	if err != nil {
+		return err
+	}
+	if err := c.login(ctx); err != nil {
+		return err
+	}
+	if c.api.Token() == "" {
+		return nil
+	}
+
+	if _, err := c.api.Logical().DeleteWithContext(ctx, metadataPath); err != nil {
+		c.invalidate()
+		return fmt.Errorf("fallo al borrar en Vault: %w", err)
+	}
+	return nil
+}
+
+// metadata devuelve los metadatos KV v2 de la ruta, o nil si no existen (o en modo mock).
+func (c *Client) metadata(ctx context.Context, path string) (map[string]interface{}, error) {
+	metadataPath, err := kvPath(path, "metadata")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	if c.api.Token() == "" {
+		return nil, nil
+	}
+
+	secret, err := c.api.Logical().ReadWithContext(ctx, metadataPath)
+	if err != nil {
+		c.invalidate()
+		return nil, fmt.Errorf("fallo al leer los metadatos de Vault: %w", err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	return secret.Data, nil
+}
+
+// kvPath sustituye el segmento data de una ruta KV v2 ("secret/data/app") por otro de
+// la API del motor ("secret/metadata/app").
+func kvPath(path, segment string) (string, error) {
+	mount, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	rest, ok := strings.CutPrefix(rest, "data/")
+	if !ok || rest == "" {
+		return "", fmt.Errorf("la ruta %q no es una ruta KV v2 (<montaje>/data/<secreto>)", path)
+	}
+	return mount + "/" + segment + "/" + rest, nil
+}
+
+// intValue convierte un número de una respuesta de Vault, que llega como json.Number.
+func intValue(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err
+	case float64:
+		return int(n), nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("versión de Vault inesperada: %v", v)
+	}
+}
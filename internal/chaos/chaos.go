@@ -0,0 +1,88 @@
+// Package chaos inyecta fallos y latencias en las llamadas a los backends para
+// comprobar, en entornos de pruebas o staging, las alertas y los reintentos y
+// restablecimientos del operador antes de llevarlo a producción.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+)
+
+// ErrInjected es la causa de todos los fallos inyectados.
+var ErrInjected = errors.New("fallo inyectado")
+
+// Injector decide, en cada llamada, cuánto se retrasa y si falla. Un Injector nil no
+// inyecta nada.
+type Injector struct {
+	// FailureRate es la probabilidad, entre 0 y 1, de que una llamada falle.
+	FailureRate float64
+	// MaxLatency es el retraso máximo añadido a cada llamada; el retraso real es
+	// aleatorio entre cero y este valor.
+	MaxLatency time.Duration
+}
+
+// New crea un Injector con la probabilidad de fallo y la latencia máxima indicadas; nil
+// si ambas son cero.
+func New(failureRate float64, maxLatency time.Duration) (*Injector, error) {
+	if failureRate < 0 || failureRate > 1 {
+		return nil, fmt.Errorf("la probabilidad de fallo %v no está entre 0 y 1", failureRate)
+	}
+	if maxLatency < 0 {
+		return nil, fmt.Errorf("la latencia máxima %v es negativa", maxLatency)
+	}
+	if failureRate == 0 && maxLatency == 0 {
+		return nil, nil
+	}
+	return &Injector{FailureRate: failureRate, MaxLatency: maxLatency}, nil
+}
+
+// Inject retrasa la operación op y devuelve un error que envuelve ErrInjected con la
+// probabilidad configurada. Devuelve el error del contexto si se cancela en la espera.
+func (i *Injector) Inject(ctx context.Context, op string) error {
+	if i == nil {
+		return nil
+	}
+
+	if i.MaxLatency > 0 {
+		timer := time.NewTimer(rand.N(i.MaxLatency + 1))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if rand.Float64() < i.FailureRate {
+		return fmt.Errorf("%s: %w", op, ErrInjected)
+	}
+	return nil
+}
+
+// WrapBackend devuelve b con fallos y latencias inyectados en sus escrituras; con un
+// Injector nil devuelve b tal cual.
+func WrapBackend(b backend.SecretBackend, injector *Injector) backend.SecretBackend {
+	if injector == nil {
+		return b
+	}
+	return &faultyBackend{SecretBackend: b, injector: injector}
+}
+
+// faultyBackend inyecta fallos antes de escribir: un fallo inyectado no llega a
+// modificar el backend.
+type faultyBackend struct {
+	backend.SecretBackend
+	injector *Injector
+}
+
+// Write escribe en el backend salvo que se inyecte un fallo.
+func (b *faultyBackend) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	if err := b.injector.Inject(ctx, "escritura en "+path); err != nil {
+		return err
+	}
+	return b.SecretBackend.Write(ctx, path, data)
+}
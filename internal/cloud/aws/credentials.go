@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultRegion es la región del endpoint global de STS.
+const DefaultRegion = "us-east-1"
+
+// Credentials son credenciales de AWS, temporales si incluyen SessionToken.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expiration es cero para credenciales estáticas.
+	Expiration time.Time
+}
+
+// Region devuelve la región configurada en el entorno (AWS_REGION o AWS_DEFAULT_REGION).
+func Region() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// STSEndpoint devuelve el endpoint de STS de la región (el global si está vacía).
+func STSEndpoint(region string) string {
+	if region == "" || region == DefaultRegion {
+		return "https://sts.amazonaws.com/"
+	}
+	return "https://sts." + region + ".amazonaws.com/"
+}
+
+// CredentialsFromEnv obtiene credenciales del Pod: con IRSA (AWS_ROLE_ARN y
+// AWS_WEB_IDENTITY_TOKEN_FILE) intercambia el token proyectado por credenciales
+// temporales; en otro caso usa AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY.
+func CredentialsFromEnv(ctx context.Context, httpClient *http.Client) (Credentials, error) {
+	roleARN := os.Getenv("AWS_ROLE_ARN")
+	tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN != "" && tokenFile != "" {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("fallo al leer el token de IRSA: %w", err)
+		}
+		session := os.Getenv("AWS_ROLE_SESSION_NAME")
+		if session == "" {
+			session = "secret-rotator-operator"
+		}
+		return AssumeRoleWithWebIdentity(ctx, httpClient, Region(), roleARN, session, strings.TrimSpace(string(token)))
+	}
+
+	creds := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, errors.New("no hay credenciales de AWS: configure IRSA o AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY")
+	}
+	return creds, nil
+}
+
+// AssumeRoleWithWebIdentity intercambia un token OIDC por credenciales temporales.
+// La llamada no va firmada: el propio token autentica la petición.
+func AssumeRoleWithWebIdentity(ctx context.Context, httpClient *http.Client, region, roleARN, session, token string) (Credentials, error) {
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {token},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, STSEndpoint(region),
+		strings.NewReader(query.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("fallo al llamar a STS: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("STS respondió con estado %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return Credentials{}, fmt.Errorf("respuesta de STS no válida: %w", err)
+	}
+	return Credentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+		Expiration:      result.Credentials.Expiration,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vectores de la suite de pruebas de SigV4 de AWS (aws-sig-v4-test-suite) y del ejemplo
+// de IAM de la documentación de firma, todos con las credenciales de ejemplo de AWS.
+func TestSign(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		service string
+		want    string
+	}{
+		{
+			name:    "get-vanilla",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:    "get-vanilla-query-order-key-case",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			service: "service",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:    "post-x-www-form-urlencoded",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:    "Param1=value1",
+			service: "service",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:    "iam ListUsers",
+			method:  http.MethodGet,
+			url:     "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			service: "iam",
+			want: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			Sign(req, []byte(tt.body), creds, "us-east-1", tt.service, now)
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization =\n  %s\nse esperaba\n  %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignSessionToken(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://sts.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secreto", SessionToken: "token-de-sesion"}
+	Sign(req, nil, creds, "us-east-1", "sts", time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC))
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token-de-sesion" {
+		t.Errorf("X-Amz-Security-Token = %q", got)
+	}
+	// El token forma parte de las cabeceras firmadas
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization = %q, el token no está firmado", got)
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "empty", query: "", want: ""},
+		{name: "sorted keys", query: "b=2&a=1", want: "a=1&b=2"},
+		{name: "sorted values", query: "a=2&a=1", want: "a=1&a=2"},
+		{name: "space", query: "a=b%20c", want: "a=b%20c"},
+		{name: "reserved", query: "a=%2F%3D%26", want: "a=%2F%3D%26"},
+		{name: "unreserved", query: "a=-_.~", want: "a=-_.~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := canonicalQuery(req.URL.Query()); got != tt.want {
+				t.Errorf("canonicalQuery(%q) = %q, se esperaba %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Package azure obtiene tokens de Microsoft Entra ID con la identidad del Pod
+// (Workload Identity de AKS) o de la máquina (identidad administrada) sin el SDK.
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultResource es el recurso para el que Vault espera el token por defecto.
+const DefaultResource = "https://management.azure.com/"
+
+// imdsEndpoint es el servicio de metadatos de instancia de Azure.
+const imdsEndpoint = "http://169.254.169.254/metadata"
+
+// Token devuelve un token de acceso para el recurso indicado. Con Workload Identity
+// (AZURE_FEDERATED_TOKEN_FILE, AZURE_CLIENT_ID, AZURE_TENANT_ID) intercambia el token
+// federado del Pod; en otro caso lo pide a la identidad administrada vía IMDS.
+func Token(ctx context.Context, httpClient *http.Client, resource string) (string, error) {
+	if tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" {
+		return workloadIdentityToken(ctx, httpClient, tokenFile, resource)
+	}
+	return managedIdentityToken(ctx, httpClient, resource)
+}
+
+func workloadIdentityToken(ctx context.Context, httpClient *http.Client, tokenFile, resource string) (string, error) {
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("fallo al leer el token federado: %w", err)
+	}
+	authority := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authority == "" {
+		authority = "https://login.microsoftonline.com/"
+	}
+	if !strings.HasSuffix(authority, "/") {
+		authority += "/"
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {os.Getenv("AZURE_CLIENT_ID")},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {strings.TrimSuffix(resource, "/") + "/.default"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		authority+os.Getenv("AZURE_TENANT_ID")+"/oauth2/v2.0/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return accessToken(httpClient, req)
+}
+
+func managedIdentityToken(ctx context.Context, httpClient *http.Client, resource string) (string, error) {
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		query.Set("client_id", clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsEndpoint+"/identity/oauth2/token?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+	return accessToken(httpClient, req)
+}
+
+func accessToken(httpClient *http.Client, req *http.Request) (string, error) {
+	body, err := do(httpClient, req)
+	if err != nil {
+		return "", fmt.Errorf("fallo al obtener el token de Azure: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("respuesta de token de Azure no válida: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// Instance describe la máquina (o el nodo de AKS) según IMDS.
+type Instance struct {
+	SubscriptionID    string `json:"subscriptionId"`
+	ResourceGroupName string `json:"resourceGroupName"`
+	Name              string `json:"name"`
+	VMScaleSetName    string `json:"vmScaleSetName"`
+}
+
+// InstanceMetadata consulta los datos de cómputo de la instancia en IMDS.
+func InstanceMetadata(ctx context.Context, httpClient *http.Client) (Instance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsEndpoint+"/instance/compute?api-version=2021-02-01", nil)
+	if err != nil {
+		return Instance{}, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	body, err := do(httpClient, req)
+	if err != nil {
+		return Instance{}, fmt.Errorf("fallo al consultar IMDS: %w", err)
+	}
+	var instance Instance
+	if err := json.Unmarshal(body, &instance); err != nil {
+		return Instance{}, fmt.Errorf("respuesta de IMDS no válida: %w", err)
+	}
+	return instance, nil
+}
+
+func do(httpClient *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("estado %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,117 @@
+// Package gcp obtiene identidades de Google Cloud a través del servidor de metadatos
+// (GCE o Workload Identity de GKE) sin depender de las bibliotecas cliente.
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// metadataHost es el servidor de metadatos; GCE_METADATA_HOST lo sustituye en pruebas.
+func metadataHost() string {
+	if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+		return host
+	}
+	return "metadata.google.internal"
+}
+
+// metadata lee una ruta del servidor de metadatos de la cuenta de servicio por defecto.
+func metadata(ctx context.Context, httpClient *http.Client, path string, query url.Values) ([]byte, error) {
+	endpoint := "http://" + metadataHost() + "/computeMetadata/v1/instance/service-accounts/default/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return do(httpClient, req)
+}
+
+// IdentityToken devuelve un JWT firmado por Google para la audiencia indicada, con el
+// formato completo (incluye los datos de la instancia en GCE).
+func IdentityToken(ctx context.Context, httpClient *http.Client, audience string) (string, error) {
+	token, err := metadata(ctx, httpClient, "identity", url.Values{"audience": {audience}, "format": {"full"}})
+	if err != nil {
+		return "", fmt.Errorf("fallo al obtener el token de identidad de GCP: %w", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// ServiceAccountEmail devuelve el email de la cuenta de servicio del Pod o la instancia.
+func ServiceAccountEmail(ctx context.Context, httpClient *http.Client) (string, error) {
+	email, err := metadata(ctx, httpClient, "email", nil)
+	if err != nil {
+		return "", fmt.Errorf("fallo al obtener la cuenta de servicio de GCP: %w", err)
+	}
+	return strings.TrimSpace(string(email)), nil
+}
+
+// SignJWT firma las claims como la cuenta de servicio indicada mediante la API IAM
+// Credentials, autenticándose con el token de acceso del servidor de metadatos.
+func SignJWT(ctx context.Context, httpClient *http.Client, email string, claims map[string]interface{}) (string, error) {
+	raw, err := metadata(ctx, httpClient, "token", nil)
+	if err != nil {
+		return "", fmt.Errorf("fallo al obtener el token de acceso de GCP: %w", err)
+	}
+	var access struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(raw, &access); err != nil {
+		return "", fmt.Errorf("token de acceso de GCP no válido: %w", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"payload": string(payload)})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/" + url.PathEscape(email) + ":signJwt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+access.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	raw, err = do(httpClient, req)
+	if err != nil {
+		return "", fmt.Errorf("fallo al firmar el JWT con IAM Credentials: %w", err)
+	}
+	var signed struct {
+		SignedJWT string `json:"signedJwt"`
+	}
+	if err := json.Unmarshal(raw, &signed); err != nil {
+		return "", fmt.Errorf("respuesta de signJwt no válida: %w", err)
+	}
+	return signed.SignedJWT, nil
+}
+
+func do(httpClient *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("estado %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
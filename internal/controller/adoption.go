@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// adoptExisting registra, con spec.adoptExisting y antes de la primera rotación, el
+// valor que ya hay en spec.vaultPath como si el operador lo hubiera rotado cuando se
+// escribió: así la primera rotación llega un intervalo después y no al incorporar la
+// Rotation. Sin valor en la ruta no adopta nada y la Rotation rota como siempre.
+func (r *RotationReconciler) adoptExisting(ctx context.Context, rotation *rotationv1alpha1.Rotation, interval time.Duration) error {
+	if !rotation.Spec.AdoptExisting || rotation.Status.LastRotatedTime != nil {
+		return nil
+	}
+
+	doc, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return fmt.Errorf("fallo al leer el valor que se va a adoptar: %w", err)
+	}
+	value, err := valueFromVault(rotation, doc)
+	if err != nil || value == nil {
+		return err
+	}
+	fingerprint, err := security.Fingerprint(value.value())
+	if err != nil {
+		return err
+	}
+
+	// Sin fecha de escritura en el backend, el valor cuenta como rotado al adoptarlo
+	now := r.now()
+	written, err := r.writtenTime(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return err
+	}
+	if written.IsZero() || written.After(now) {
+		written = now
+	}
+
+	rotated := metav1.NewTime(written)
+	recordRotated(rotation, value, fingerprint, rotated, metav1.NewTime(nextRotationAfter(written, interval, value)))
+	recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{
+		Time:        metav1.NewTime(now),
+		Trigger:     "Adoption",
+		Result:      "Adopted",
+		Fingerprint: fingerprint,
+	})
+	message := fmt.Sprintf("Valor existente en %s adoptado sin rotarlo; escrito el %s", rotation.Spec.VaultPath, written.UTC().Format(time.RFC3339))
+	logf.FromContext(ctx).Info("Valor existente adoptado", "path", rotation.Spec.VaultPath, "escrito", written)
+	r.event(rotation, corev1.EventTypeNormal, "SecretAdopted", message)
+	return nil
+}
+
+// writtenTime devuelve cuándo se escribió el valor vigente de path si el backend lo sabe;
+// cero si no.
+func (r *RotationReconciler) writtenTime(ctx context.Context, path string) (time.Time, error) {
+	var store backend.SecretBackend = r.Backend
+	if store == nil {
+		store = r.vaultSessions()
+	}
+	dated, ok := store.(backend.DatedBackend)
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	var written time.Time
+	err := r.doVault(ctx, func(ctx context.Context) error {
+		var err error
+		written, err = dated.UpdatedTime(ctx, path)
+		return err
+	})
+	return written, err
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// statusWriters son los gestores que escriben o escribieron el estado con parches en
+// lugar de server-side apply: el operador antes de usarlo ("manager", el nombre de su
+// binario) y el plugin de kubectl. Sus campos pasan a FieldManager antes de aplicar el
+// estado; si no, omitirlos no los borraría.
+var statusWriters = sets.New("manager", rotationv1alpha1.FieldManager, rotationv1alpha1.PluginFieldManager)
+
+// applyStatus declara con server-side apply, como FieldManager, el estado completo de
+// obj: status es un puntero a su campo Status. Los campos que el operador dejó de
+// declarar se borran y los del spec y los metadatos nunca se tocan, así no entra en
+// conflicto con quien aplica el spec. La aplicación exige la resourceVersion de obj: si
+// la caché iba por detrás de otro escritor del estado (p. ej. una aprobación de
+// kubectl-rotate), falla con un conflicto en lugar de pisar su cambio, y la conciliación
+// se repite con el objeto al día.
+func applyStatus(ctx context.Context, c client.Client, obj client.Object, status any) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, statusWriters, rotationv1alpha1.FieldManager,
+		csaupgrade.Subresource("status"))
+	if err != nil {
+		return err
+	}
+	resourceVersion := obj.GetResourceVersion()
+	if patch != nil {
+		// Se parchea una copia: la respuesta sustituiría el estado que se va a aplicar. El
+		// parche ya exige la resourceVersion de obj y solo cambia managedFields
+		upgraded := obj.DeepCopyObject().(client.Object)
+		if err := c.Patch(ctx, upgraded, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+			return err
+		}
+		resourceVersion = upgraded.GetResourceVersion()
+	}
+
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return err
+	}
+	applied, err := applyObject(c, obj)
+	if err != nil {
+		return err
+	}
+	applied.SetResourceVersion(resourceVersion)
+	applied.Object["status"] = fields
+	if err := c.Status().Patch(ctx, applied, client.Apply, client.FieldOwner(rotationv1alpha1.FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	// Una segunda aplicación en la misma conciliación parte de la versión recién escrita
+	obj.SetResourceVersion(applied.GetResourceVersion())
+	obj.SetManagedFields(applied.GetManagedFields())
+	return nil
+}
+
+// applyObject devuelve la configuración de server-side apply vacía de obj: solo su
+// tipo, nombre y namespace.
+func applyObject(c client.Client, obj client.Object) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+	return applied, nil
+}
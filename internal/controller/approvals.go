@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// defaultApprovalTimeout es la validez de una solicitud de aprobación si el spec no la fija.
+const defaultApprovalTimeout = 24 * time.Hour
+
+// checkApproval abre o renueva la solicitud de aprobación de una rotación vencida y
+// devuelve cuánto debe esperar; cero si ya está aprobada y puede continuar.
+func (r *RotationReconciler) checkApproval(rotation *rotationv1alpha1.Rotation, trigger string, now time.Time) (time.Duration, error) {
+	if !rotation.Spec.ApprovalRequired {
+		rotation.Status.PendingApproval = nil
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionPendingApproval)
+		return 0, nil
+	}
+
+	timeout := defaultApprovalTimeout
+	if rotation.Spec.ApprovalTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(rotation.Spec.ApprovalTimeout); err != nil {
+			return 0, fmt.Errorf("approvalTimeout %q no es una duración válida: %w", rotation.Spec.ApprovalTimeout, err)
+		}
+	}
+
+	pending := rotation.Status.PendingApproval
+	if pending != nil && !now.Before(pending.ExpirationTime.Time) {
+		// Una aprobación caducada no vale para la siguiente solicitud
+		message := fmt.Sprintf("La solicitud de aprobación %s caducó sin aprobarse", pending.ID)
+		if pending.ApprovedBy != "" {
+			message = fmt.Sprintf("La solicitud de aprobación %s caducó antes de completar la rotación", pending.ID)
+		}
+		r.event(rotation, corev1.EventTypeWarning, "ApprovalExpired", message)
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(now), Trigger: pending.Trigger, Result: "ApprovalExpired"})
+		pending = nil
+	}
+	if pending == nil {
+		pending = &rotationv1alpha1.PendingApproval{
+			ID:             rand.Text(),
+			RequestedTime:  metav1.NewTime(now),
+			ExpirationTime: metav1.NewTime(now.Add(timeout)),
+			Trigger:        trigger,
+		}
+		rotation.Status.PendingApproval = pending
+		r.event(rotation, corev1.EventTypeNormal, "ApprovalRequested",
+			fmt.Sprintf("Rotación pendiente de aprobación hasta %s (solicitud %s)", pending.ExpirationTime.UTC().Format(time.RFC3339), pending.ID))
+	}
+
+	if pending.ApprovedBy == "" {
+		rotation.Status.Status = rotationv1alpha1.ConditionPendingApproval
+		meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+			Type:               rotationv1alpha1.ConditionPendingApproval,
+			Status:             metav1.ConditionTrue,
+			Reason:             "AwaitingApproval",
+			Message:            fmt.Sprintf("Solicitud %s pendiente de aprobación en status.pendingApproval", pending.ID),
+			ObservedGeneration: rotation.Generation,
+		})
+		return pending.ExpirationTime.Sub(now), nil
+	}
+
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionPendingApproval,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Approved",
+		Message:            fmt.Sprintf("Solicitud %s aprobada por %s", pending.ID, pending.ApprovedBy),
+		ObservedGeneration: rotation.Generation,
+	})
+	return 0, nil
+}
+
+// event publica un Event sobre la Rotation si hay Recorder.
+func (r *RotationReconciler) event(rotation *rotationv1alpha1.Rotation, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(rotation, eventType, reason, message)
+	}
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+)
+
+// pendingAttempt devuelve el intento registrado en la anotación attempt y su valor si
+// ese intento ya llegó a escribirse en Vault pero no a completarse en el estado.
+// Sin intento pendiente devuelve un valor nil.
+func (r *RotationReconciler) pendingAttempt(ctx context.Context, rotation *rotationv1alpha1.Rotation) (string, *rotatedValue, error) {
+	attempt := rotation.Annotations[rotationv1alpha1.AttemptAnnotation]
+	if attempt == "" || attempt == rotation.Status.LastAttemptID {
+		return "", nil, nil
+	}
+
+	data, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if id, _ := data[vault.AttemptKey].(string); id != attempt {
+		// El intento no llegó a escribirse: se empieza uno nuevo
+		return "", nil, nil
+	}
+	value, err := valueFromVault(rotation, data)
+	if err != nil || value == nil {
+		return "", nil, err
+	}
+	return attempt, value, nil
+}
+
+// startAttempt registra un intento nuevo en la anotación attempt de la Rotation.
+func (r *RotationReconciler) startAttempt(ctx context.Context, rotation *rotationv1alpha1.Rotation) (string, error) {
+	attempt := rand.Text()
+
+	// Se parchea una copia: la Rotation en curso conserva los metadatos observados y
+	// el parche de estado posterior no arrastra la anotación.
+	marked := rotation.DeepCopy()
+	if marked.Annotations == nil {
+		marked.Annotations = map[string]string{}
+	}
+	marked.Annotations[rotationv1alpha1.AttemptAnnotation] = attempt
+	if err := r.Patch(ctx, marked, client.MergeFrom(rotation)); err != nil {
+		return "", err
+	}
+	// El estado se aplica con la resourceVersion de la Rotation como precondición: sin
+	// la del parche, la aplicación final chocaría con este mismo cambio
+	rotation.SetResourceVersion(marked.ResourceVersion)
+	rotation.SetManagedFields(marked.ManagedFields)
+	return attempt, nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// authorizeTargets comprueba, antes de rotar, que la Rotation puede escribir en los
+// Secrets de destino de otros namespaces: el operador tiene permisos en todo el clúster
+// y no debe servir para escribir en namespaces que el tenant no controla.
+func (r *RotationReconciler) authorizeTargets(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if rotation.Spec.Targets == nil || r.AllowCrossNamespaceTargets {
+		return nil
+	}
+	for _, target := range rotation.Spec.Targets.Secrets {
+		if target.Namespace == "" || target.Namespace == rotation.Namespace {
+			continue
+		}
+		if err := r.authorizeTarget(ctx, rotation, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeTarget acepta el destino si su namespace concede acceso al de la Rotation
+// con AllowedSourceNamespacesAnnotation o, si no, si la ServiceAccount default del
+// namespace de la Rotation puede crear y actualizar el Secret (SubjectAccessReview).
+func (r *RotationReconciler) authorizeTarget(ctx context.Context, rotation *rotationv1alpha1.Rotation, target rotationv1alpha1.SecretTarget) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: target.Namespace}, ns); err != nil {
+		return fmt.Errorf("fallo al obtener el namespace %q del destino: %w", target.Namespace, err)
+	}
+	for _, allowed := range strings.Split(ns.Annotations[rotationv1alpha1.AllowedSourceNamespacesAnnotation], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || allowed == rotation.Namespace {
+			return nil
+		}
+	}
+
+	for _, verb := range []string{"create", "update"} {
+		review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   "system:serviceaccount:" + rotation.Namespace + ":default",
+			Groups: []string{"system:serviceaccounts", "system:serviceaccounts:" + rotation.Namespace, "system:authenticated"},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: target.Namespace,
+				Verb:      verb,
+				Resource:  "secrets",
+				Name:      target.Name,
+			},
+		}}
+		if err := r.Create(ctx, review); err != nil {
+			return fmt.Errorf("fallo al revisar el acceso al Secret %s/%s: %w", target.Namespace, target.Name, err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("la Rotation no tiene permiso %s sobre el Secret %s/%s: concédelo con la anotación %s "+
+				"en el namespace de destino o con RBAC para la ServiceAccount default de %q",
+				verb, target.Namespace, target.Name, rotationv1alpha1.AllowedSourceNamespacesAnnotation, rotation.Namespace)
+		}
+	}
+	return nil
+}
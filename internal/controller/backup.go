@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+)
+
+// backupTimeFormat nombra cada copia por su hora UTC; el orden alfabético es el cronológico.
+const backupTimeFormat = "20060102T150405Z"
+
+// defaultBackupRetain es el número de copias conservadas si el spec no lo fija.
+const defaultBackupRetain = 5
+
+// backupCurrent copia, con spec.backup, el valor vigente de la ruta antes de que la
+// rotación lo sobrescriba.
+func (r *RotationReconciler) backupCurrent(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if rotation.Spec.Backup == nil {
+		return nil
+	}
+	doc, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return fmt.Errorf("fallo al leer el valor vigente: %w", err)
+	}
+	return r.writeBackup(ctx, rotation, doc)
+}
+
+// writeBackup escribe doc como una copia nueva bajo la ruta de copias de la Rotation y
+// poda las más antiguas. Sin documento no hay nada que copiar.
+func (r *RotationReconciler) writeBackup(ctx context.Context, rotation *rotationv1alpha1.Rotation, doc map[string]interface{}) error {
+	spec := rotation.Spec.Backup
+	if spec == nil || doc == nil {
+		return nil
+	}
+	root := backupRoot(rotation)
+	path := root + "/" + r.now().UTC().Format(backupTimeFormat)
+	if err := r.restoreVault(ctx, path, doc); err != nil {
+		return fmt.Errorf("fallo al copiar el valor vigente en %s: %w", path, err)
+	}
+	logf.FromContext(ctx).Info("Valor vigente copiado antes de rotar", "backup", path)
+
+	// La copia ya está escrita: un fallo al podar no detiene la rotación
+	retain := defaultBackupRetain
+	if spec.Retain > 0 {
+		retain = int(spec.Retain)
+	}
+	if err := r.pruneBackups(ctx, root, retain); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al podar las copias antiguas", "path", root)
+	}
+	return nil
+}
+
+// pruneBackups borra las copias más antiguas bajo root hasta dejar retain. Solo cuenta
+// las nombradas con backupTimeFormat: nada más bajo la ruta se borra.
+func (r *RotationReconciler) pruneBackups(ctx context.Context, root string, retain int) error {
+	lister, ok := r.listingBackend()
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	err := r.doVault(ctx, func(ctx context.Context) error {
+		var err error
+		names, err = lister.List(ctx, root)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, name := range names {
+		if isBackupName(name) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > retain {
+		path := root + "/" + backups[0]
+		if err := r.doVault(ctx, func(ctx context.Context) error {
+			return lister.Delete(ctx, path)
+		}); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backupRoot devuelve la ruta bajo la que se escriben las copias de la Rotation.
+func backupRoot(rotation *rotationv1alpha1.Rotation) string {
+	if path := rotation.Spec.Backup.Path; path != "" {
+		return strings.TrimSuffix(path, "/")
+	}
+	return strings.TrimSuffix(rotation.Spec.VaultPath, "/") + "-backup"
+}
+
+func isBackupName(name string) bool {
+	parsed, err := time.Parse(backupTimeFormat, name)
+	return err == nil && parsed.Format(backupTimeFormat) == name
+}
+
+// listingBackend devuelve el almacén de los valores rotados si puede enumerar y borrar rutas.
+func (r *RotationReconciler) listingBackend() (backend.ListingBackend, bool) {
+	var store backend.SecretBackend = r.Backend
+	if store == nil {
+		store = r.vaultSessions()
+	}
+	lister, ok := store.(backend.ListingBackend)
+	return lister, ok
+}
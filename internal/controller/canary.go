@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+)
+
+// defaultCanaryTimeout es el plazo del Job del canario si el spec no lo fija.
+const defaultCanaryTimeout = 15 * time.Minute
+
+// statusCanaryRunning es el estado de una rotación cuyo valor nuevo se está probando.
+const statusCanaryRunning = "CanaryRunning"
+
+// Variables de entorno con las que el Job del canario localiza el valor a probar.
+const (
+	canaryPathEnv    = "ROTATION_STAGING_PATH"
+	canaryAttemptEnv = "ROTATION_ATTEMPT"
+)
+
+// canaryRunning indica si la Rotation tiene un canario en curso. Ese canario ya superó
+// las retenciones de la rotación al lanzarse y se sigue hasta su resultado.
+func canaryRunning(rotation *rotationv1alpha1.Rotation) bool {
+	return rotation.Spec.Strategy == rotationv1alpha1.StrategyCanary &&
+		rotation.Status.Canary != nil && rotation.Status.Canary.Phase == rotationv1alpha1.CanaryRunning
+}
+
+// runCanary aplica la estrategia Canary a un intento de rotación. Sin canario en curso
+// genera el valor, lo escribe en la ruta de staging, lanza el Job del canario y retiene
+// la rotación; con uno en curso espera a su Job. Si el Job termina bien devuelve el
+// intento y el valor probados para promoverlos a vaultPath; si falla o vence su plazo,
+// aborta la rotación. Con otra estrategia, o si se retoma un intento ya escrito en
+// Vault, devuelve attempt y value sin cambios. Con held a true la rotación queda
+// retenida con el estado parcheado.
+func (r *RotationReconciler) runCanary(ctx, opCtx context.Context, rotation, observed *rotationv1alpha1.Rotation,
+	trigger, attempt string, value *rotatedValue) (string, *rotatedValue, bool, error) {
+	if value != nil || rotation.Spec.Strategy != rotationv1alpha1.StrategyCanary {
+		return attempt, value, false, nil
+	}
+	if !canaryRunning(rotation) {
+		return "", nil, true, r.startCanary(ctx, opCtx, rotation, observed, trigger)
+	}
+
+	canary := rotation.Status.Canary
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: canary.Job}, job)
+	if apierrors.IsNotFound(err) {
+		return "", nil, true, r.abortCanary(ctx, rotation, observed, trigger, "el Job del canario ya no existe")
+	}
+	if err != nil {
+		return "", nil, true, err
+	}
+
+	complete, failure := jobResult(job)
+	switch {
+	case failure != "":
+		return "", nil, true, r.abortCanary(ctx, rotation, observed, trigger, failure)
+	case !complete && r.now().Sub(canary.StartTime.Time) >= canaryTimeout(rotation):
+		return "", nil, true, r.abortCanary(ctx, rotation, observed, trigger,
+			fmt.Sprintf("el Job del canario no terminó en %s", canaryTimeout(rotation)))
+	case !complete:
+		logf.FromContext(ctx).V(1).Info("Esperando al canario", "job", canary.Job)
+		return "", nil, true, nil
+	}
+
+	// El valor promovido es el que se probó: se relee de la ruta de staging
+	data, err := r.readFromVault(opCtx, canary.StagingPath)
+	if err != nil {
+		return "", nil, true, err
+	}
+	if id, _ := data[vault.AttemptKey].(string); id != canary.Attempt {
+		return "", nil, true, r.abortCanary(ctx, rotation, observed, trigger,
+			fmt.Sprintf("la ruta de staging %s cambió durante el canario", canary.StagingPath))
+	}
+	staged, err := valueFromVault(rotation, data)
+	if err != nil || staged == nil {
+		return "", nil, true, r.abortCanary(ctx, rotation, observed, trigger,
+			fmt.Sprintf("la ruta de staging %s no contiene el valor probado", canary.StagingPath))
+	}
+
+	logf.FromContext(ctx).Info("Canario superado, promoviendo el valor", "job", canary.Job)
+	r.event(rotation, corev1.EventTypeNormal, "CanaryPromoted", fmt.Sprintf("El Job %s validó el valor nuevo; se promueve a %s", canary.Job, rotation.Spec.VaultPath))
+	now := metav1.NewTime(r.now())
+	canary.Phase = rotationv1alpha1.CanaryPromoted
+	canary.CompletionTime = &now
+	return canary.Attempt, staged, false, nil
+}
+
+// startCanary genera el valor de un intento nuevo, lo escribe en la ruta de staging y
+// lanza el Job del canario contra él.
+func (r *RotationReconciler) startCanary(ctx, opCtx context.Context, rotation, observed *rotationv1alpha1.Rotation, trigger string) error {
+	log := logf.FromContext(ctx)
+	fail := func(status string, err error) error {
+		log.Error(err, "Fallo al lanzar el canario")
+		rotation.Status.Status = r.failedStatus(opCtx, rotation, status)
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+		return r.patchStatus(ctx, rotation, observed)
+	}
+
+	value, err := r.generateValue(opCtx, rotation)
+	if err != nil {
+		return fail("ErrorGeneracion", err)
+	}
+	attempt, err := r.startAttempt(ctx, rotation)
+	if err != nil {
+		return err
+	}
+
+	staging := stagingPath(rotation)
+	doc := vault.SecretData(value.vaultData(), "secret-rotator-operator", attempt)
+	if err := r.doVault(opCtx, func(ctx context.Context) error {
+		return r.secretBackend().Write(ctx, staging, doc)
+	}); err != nil {
+		return fail("ErrorVault", fmt.Errorf("fallo al escribir en la ruta de staging %s: %w", staging, err))
+	}
+
+	job, err := r.createCanaryJob(ctx, rotation, staging, attempt)
+	if err != nil {
+		return fail("ErrorCanary", err)
+	}
+
+	log.Info("Canario lanzado", "job", job, "staging", staging)
+	r.event(rotation, corev1.EventTypeNormal, "CanaryStarted", fmt.Sprintf("Valor nuevo escrito en %s; el Job %s lo está probando", staging, job))
+	rotation.Status.Status = statusCanaryRunning
+	rotation.Status.Canary = &rotationv1alpha1.CanaryStatus{
+		Attempt:     attempt,
+		StagingPath: staging,
+		Job:         job,
+		Phase:       rotationv1alpha1.CanaryRunning,
+		StartTime:   metav1.NewTime(r.now()),
+	}
+	return r.patchStatus(ctx, rotation, observed)
+}
+
+// abortCanary registra el fallo del canario en curso. vaultPath no llegó a cambiar y la
+// rotación se reintenta con un valor nuevo; el Job se conserva para consultar sus logs.
+func (r *RotationReconciler) abortCanary(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation, trigger, message string) error {
+	canary := rotation.Status.Canary
+	logf.FromContext(ctx).Info("Canario fallido, rotación abortada", "job", canary.Job, "motivo", message)
+	r.event(rotation, corev1.EventTypeWarning, "CanaryFailed", fmt.Sprintf("Job %s: %s; %s no se modificó", canary.Job, message, rotation.Spec.VaultPath))
+	now := metav1.NewTime(r.now())
+	canary.Phase = rotationv1alpha1.CanaryFailed
+	canary.Message = message
+	canary.CompletionTime = &now
+	rotation.Status.Status = "ErrorCanary"
+	recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: now, Trigger: trigger, Result: rotation.Status.Status})
+	return r.patchStatus(ctx, rotation, observed)
+}
+
+// createCanaryJob crea, a partir del jobTemplate del CronJob de spec.canary, el Job que
+// prueba el valor de un intento, y devuelve su nombre. El Job pertenece a la Rotation y
+// se borra con ella.
+func (r *RotationReconciler) createCanaryJob(ctx context.Context, rotation *rotationv1alpha1.Rotation, staging, attempt string) (string, error) {
+	cronJob := &batchv1.CronJob{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: rotation.Spec.Canary.CronJobName}, cronJob); err != nil {
+		return "", fmt.Errorf("fallo al leer el CronJob %q del canario: %w", rotation.Spec.Canary.CronJobName, err)
+	}
+
+	template := cronJob.Spec.JobTemplate.DeepCopy()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			// El nombre se deriva del intento: un reintento tras un fallo al registrar el
+			// estado encuentra el Job ya creado en lugar de lanzar otro
+			Name:        fmt.Sprintf("%s-canary-%s", strings.TrimRight(rotation.Name[:min(len(rotation.Name), 40)], ".-"), strings.ToLower(attempt[:8])),
+			Namespace:   rotation.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	env := []corev1.EnvVar{{Name: canaryPathEnv, Value: staging}, {Name: canaryAttemptEnv, Value: attempt}}
+	pod := &job.Spec.Template.Spec
+	for i := range pod.InitContainers {
+		pod.InitContainers[i].Env = append(pod.InitContainers[i].Env, env...)
+	}
+	for i := range pod.Containers {
+		pod.Containers[i].Env = append(pod.Containers[i].Env, env...)
+	}
+	if err := controllerutil.SetControllerReference(rotation, job, r.Scheme); err != nil {
+		return "", err
+	}
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("fallo al crear el Job del canario: %w", err)
+	}
+	return job.Name, nil
+}
+
+// jobResult indica si el Job terminó bien o, si falló, el motivo.
+func jobResult(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, ""
+		case batchv1.JobFailed:
+			return false, fmt.Sprintf("el Job del canario falló (%s): %s", condition.Reason, condition.Message)
+		}
+	}
+	return false, ""
+}
+
+// stagingPath devuelve la ruta de staging de spec.canary o, si no se fijó,
+// "<vaultPath>-canary".
+func stagingPath(rotation *rotationv1alpha1.Rotation) string {
+	if rotation.Spec.Canary != nil && rotation.Spec.Canary.StagingPath != "" {
+		return rotation.Spec.Canary.StagingPath
+	}
+	return strings.TrimSuffix(rotation.Spec.VaultPath, "/") + "-canary"
+}
+
+// canaryTimeout devuelve el plazo del Job del canario.
+func canaryTimeout(rotation *rotationv1alpha1.Rotation) time.Duration {
+	if rotation.Spec.Canary != nil {
+		if timeout, err := time.ParseDuration(rotation.Spec.Canary.Timeout); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return defaultCanaryTimeout
+}
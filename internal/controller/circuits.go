@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// Valores por defecto del circuito de cada backend.
+const (
+	DefaultCircuitThreshold = 5
+	DefaultCircuitCooldown  = time.Minute
+)
+
+// backendCircuits lleva la cuenta de los fallos consecutivos de cada backend, común a
+// todas las Rotations y RotationGroups. Tras threshold fallos seguidos el circuito se
+// abre: las rotaciones no se intentan durante cooldown y después pasa una sola de prueba
+// (semiabierto); si la prueba funciona el circuito se cierra y, si falla, se vuelve a
+// abrir otro cooldown. Se lleva en memoria: un reinicio del operador lo cierra.
+type backendCircuits struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	// retryAt es cuándo se admite la próxima prueba de un circuito abierto
+	retryAt time.Time
+}
+
+// allow devuelve cero si se puede operar contra el backend, bien porque su circuito
+// está cerrado o porque toca una prueba, o cuánto falta para la próxima prueba. Admitir
+// una prueba aplaza la siguiente otro cooldown, así solo pasa una a la vez.
+func (c *backendCircuits) allow(name string, threshold int, cooldown time.Duration, now time.Time) time.Duration {
+	if threshold <= 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.circuits[name]
+	if !ok || state.failures < threshold {
+		return 0
+	}
+	if wait := state.retryAt.Sub(now); wait > 0 {
+		return wait
+	}
+	state.retryAt = now.Add(cooldown)
+	return 0
+}
+
+// record anota el resultado de una operación contra el backend y devuelve si con ella
+// el circuito cambió de estado: opened si acaba de abrirse y closed si estaba abierto y
+// vuelve a cerrarse.
+func (c *backendCircuits) record(name string, err error, threshold int, cooldown time.Duration, now time.Time) (opened, closed bool) {
+	if threshold <= 0 {
+		return false, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.circuits == nil {
+		c.circuits = map[string]*circuit{}
+	}
+	state, ok := c.circuits[name]
+	if err == nil {
+		delete(c.circuits, name)
+		return false, ok && state.failures >= threshold
+	}
+	if !ok {
+		state = &circuit{}
+		c.circuits[name] = state
+	}
+	state.failures++
+	if state.failures >= threshold {
+		state.retryAt = now.Add(cooldown)
+	}
+	return state.failures == threshold, false
+}
+
+// circuitCooldown devuelve CircuitCooldown o, si no se configuró, el valor por defecto.
+func (r *RotationReconciler) circuitCooldown() time.Duration {
+	if r.CircuitCooldown > 0 {
+		return r.CircuitCooldown
+	}
+	return DefaultCircuitCooldown
+}
+
+// doVault ejecuta fn contra Vault en el pool de ejecutores y anota su resultado en el
+// circuito de Vault.
+func (r *RotationReconciler) doVault(ctx context.Context, fn func(context.Context) error) error {
+	err := r.Executors.Do(ctx, "vault", fn)
+	opened, closed := r.circuits.record("vault", err, r.CircuitThreshold, r.circuitCooldown(), r.now())
+	switch {
+	case opened:
+		logf.FromContext(ctx).Info("Circuito de Vault abierto tras fallos consecutivos",
+			"fallos", r.CircuitThreshold, "próximaPrueba", r.now().Add(r.circuitCooldown()))
+		backendCircuitOpen.WithLabelValues("vault").Set(1)
+	case closed:
+		logf.FromContext(ctx).Info("Circuito de Vault cerrado: el backend vuelve a responder")
+		backendCircuitOpen.WithLabelValues("vault").Set(0)
+	}
+	return err
+}
+
+// checkCircuit aplaza una rotación vencida mientras el circuito de Vault esté abierto,
+// sin intentar escribir en un backend que se sabe caído. Devuelve cuánto esperar hasta
+// la próxima prueba; cero si puede continuar.
+func (r *RotationReconciler) checkCircuit(rotation *rotationv1alpha1.Rotation) time.Duration {
+	wait := r.circuits.allow("vault", r.CircuitThreshold, r.circuitCooldown(), r.now())
+	if wait <= 0 {
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionBackendCircuitOpen)
+		return 0
+	}
+
+	message := fmt.Sprintf("Vault falló %d veces seguidas; la rotación se reintentará tras la próxima prueba del backend", r.CircuitThreshold)
+	// El aplazamiento se notifica una sola vez por apertura
+	if !meta.IsStatusConditionTrue(rotation.Status.Conditions, rotationv1alpha1.ConditionBackendCircuitOpen) {
+		r.event(rotation, corev1.EventTypeWarning, "BackendCircuitOpen", message)
+	}
+	rotation.Status.Status = rotationv1alpha1.ConditionBackendCircuitOpen
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionBackendCircuitOpen,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ConsecutiveFailures",
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return wait
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// vaultPathIndex indexa las Rotations por cada ruta en la que escriben, spec.vaultPath y
+// spec.vaultPaths, para detectar rutas compartidas.
+const vaultPathIndex = "spec.vaultPath"
+
+// checkPathConflict actualiza la condición PathConflict y devuelve si la Rotation debe
+// esperar porque otra más antigua escribe en alguna de sus rutas.
+func (r *RotationReconciler) checkPathConflict(ctx context.Context, rotation *rotationv1alpha1.Rotation) (bool, error) {
+	others, err := r.rotationsSharingPaths(ctx, rotation)
+	if err != nil {
+		return false, fmt.Errorf("fallo al buscar Rotations con la misma ruta: %w", err)
+	}
+
+	if len(others) == 0 {
+		meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+			Type:               rotationv1alpha1.ConditionPathConflict,
+			Status:             metav1.ConditionFalse,
+			Reason:             "UniquePath",
+			Message:            "Ninguna otra Rotation usa esta ruta de Vault",
+			ObservedGeneration: rotation.Generation,
+		})
+		return false, nil
+	}
+
+	blocked := false
+	names := make([]string, 0, len(others))
+	for _, other := range others {
+		names = append(names, other.Namespace+"/"+other.Name)
+		if olderThan(&other, rotation) {
+			blocked = true
+		}
+	}
+	sort.Strings(names)
+
+	message := "Ruta de Vault compartida con " + strings.Join(names, ", ") + "; solo rota la Rotation más antigua"
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionPathConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SharedPath",
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return blocked, nil
+}
+
+// olderThan ordena las Rotations por antigüedad y, a igualdad, por namespace/nombre.
+func olderThan(a, b *rotationv1alpha1.Rotation) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
+// rotationsSharingPaths devuelve las demás Rotations que escriben en alguna de las rutas
+// de rotation, cada una una sola vez.
+func (r *RotationReconciler) rotationsSharingPaths(ctx context.Context, rotation *rotationv1alpha1.Rotation) ([]rotationv1alpha1.Rotation, error) {
+	seen := map[types.NamespacedName]bool{{Namespace: rotation.Namespace, Name: rotation.Name}: true}
+	var others []rotationv1alpha1.Rotation
+	for _, path := range rotationPaths(rotation) {
+		list := &rotationv1alpha1.RotationList{}
+		if err := r.List(ctx, list, client.MatchingFields{vaultPathIndex: path}); err != nil {
+			return nil, err
+		}
+		for _, other := range list.Items {
+			key := types.NamespacedName{Namespace: other.Namespace, Name: other.Name}
+			if !seen[key] {
+				seen[key] = true
+				others = append(others, other)
+			}
+		}
+	}
+	return others, nil
+}
+
+// rotationsSharingPath encola las Rotations que comparten alguna ruta con la modificada,
+// para que reevalúen el conflicto cuando cambia o se borra.
+func (r *RotationReconciler) rotationsSharingPath(ctx context.Context, obj client.Object) []reconcile.Request {
+	rotation, ok := obj.(*rotationv1alpha1.Rotation)
+	if !ok {
+		return nil
+	}
+
+	others, err := r.rotationsSharingPaths(ctx, rotation)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al buscar Rotations con la misma ruta", "path", rotation.Spec.VaultPath)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(others))
+	for _, other := range others {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: other.Namespace, Name: other.Name},
+		})
+	}
+	return requests
+}
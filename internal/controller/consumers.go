@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// Índices del caché usados para el inventario de consumidores.
+const (
+	// podSecretRefsIndex indexa los Pods por los Secrets que montan o referencian en su entorno.
+	podSecretRefsIndex = ".spec.secretRefs"
+	// podConsumesIndex indexa los Pods por las Rotations que declaran consumir.
+	podConsumesIndex = ".metadata.annotations.consumes"
+	// rotationTargetSecretsIndex indexa las Rotations por sus Secrets de destino ("namespace/nombre").
+	rotationTargetSecretsIndex = ".spec.targets.secrets"
+)
+
+// setupConsumerIndexes registra los índices de Pods y Rotations en el caché del manager.
+func setupConsumerIndexes(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(ctx, &corev1.Pod{}, podSecretRefsIndex, func(obj client.Object) []string {
+		return podSecretRefs(obj.(*corev1.Pod))
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &corev1.Pod{}, podConsumesIndex, func(obj client.Object) []string {
+		return consumedRotations(obj)
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &rotationv1alpha1.Rotation{}, rotationTargetSecretsIndex, func(obj client.Object) []string {
+		return targetSecretKeys(obj.(*rotationv1alpha1.Rotation))
+	})
+}
+
+// podSecretRefs devuelve los Secrets que un Pod monta como volumen o usa en env/envFrom.
+func podSecretRefs(pod *corev1.Pod) []string {
+	refs := map[string]bool{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil {
+			refs[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					refs[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				refs[envFrom.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				refs[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+	return sortedKeys(refs)
+}
+
+// consumedRotations devuelve las Rotations listadas en la anotación consumes.
+func consumedRotations(obj client.Object) []string {
+	value := obj.GetAnnotations()[rotationv1alpha1.ConsumesAnnotation]
+	if value == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// targetSecretKeys devuelve los Secrets de destino de una Rotation como "namespace/nombre".
+func targetSecretKeys(rotation *rotationv1alpha1.Rotation) []string {
+	if rotation.Spec.Targets == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(rotation.Spec.Targets.Secrets))
+	for _, target := range rotation.Spec.Targets.Secrets {
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = rotation.Namespace
+		}
+		keys = append(keys, namespace+"/"+target.Name)
+	}
+	return keys
+}
+
+// refreshConsumers recalcula status.consumers. El inventario es informativo: si no se
+// puede calcular se conserva el anterior.
+func (r *RotationReconciler) refreshConsumers(ctx context.Context, rotation *rotationv1alpha1.Rotation) {
+	log := logf.FromContext(ctx)
+
+	pods := map[types.UID]corev1.Pod{}
+	for _, key := range targetSecretKeys(rotation) {
+		namespace, name, _ := strings.Cut(key, "/")
+		list := &corev1.PodList{}
+		if err := r.List(ctx, list, client.InNamespace(namespace), client.MatchingFields{podSecretRefsIndex: name}); err != nil {
+			log.Error(err, "Fallo al listar los Pods consumidores", "secret", key)
+			return
+		}
+		for _, pod := range list.Items {
+			pods[pod.UID] = pod
+		}
+	}
+
+	list := &corev1.PodList{}
+	if err := r.List(ctx, list, client.InNamespace(rotation.Namespace), client.MatchingFields{podConsumesIndex: rotation.Name}); err != nil {
+		log.Error(err, "Fallo al listar los Pods con la anotación consumes")
+		return
+	}
+	for _, pod := range list.Items {
+		pods[pod.UID] = pod
+	}
+
+	seen := map[rotationv1alpha1.ConsumerReference]bool{}
+	consumers := []rotationv1alpha1.ConsumerReference{}
+	for _, pod := range pods {
+		ref := r.workloadFor(ctx, &pod)
+		if !seen[ref] {
+			seen[ref] = true
+			consumers = append(consumers, ref)
+		}
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		a, b := consumers[i], consumers[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	rotation.Status.Consumers = consumers
+}
+
+// workloadFor resuelve el workload que controla un Pod (Deployment a través de su ReplicaSet).
+func (r *RotationReconciler) workloadFor(ctx context.Context, pod *corev1.Pod) rotationv1alpha1.ConsumerReference {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return rotationv1alpha1.ConsumerReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		rs := &appsv1.ReplicaSet{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, rs); err == nil {
+			if deployment := metav1.GetControllerOf(rs); deployment != nil {
+				return rotationv1alpha1.ConsumerReference{Kind: deployment.Kind, Namespace: pod.Namespace, Name: deployment.Name}
+			}
+		}
+	}
+	return rotationv1alpha1.ConsumerReference{Kind: owner.Kind, Namespace: pod.Namespace, Name: owner.Name}
+}
+
+// rotationsForPod encola las Rotations cuyo inventario puede cambiar por un Pod.
+func (r *RotationReconciler) rotationsForPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	requests := map[types.NamespacedName]bool{}
+	for _, name := range consumedRotations(pod) {
+		requests[types.NamespacedName{Namespace: pod.Namespace, Name: name}] = true
+	}
+	for _, secret := range podSecretRefs(pod) {
+		list := &rotationv1alpha1.RotationList{}
+		if err := r.List(ctx, list, client.MatchingFields{rotationTargetSecretsIndex: pod.Namespace + "/" + secret}); err != nil {
+			logf.FromContext(ctx).Error(err, "Fallo al buscar Rotations por Secret de destino", "secret", secret)
+			continue
+		}
+		for _, rotation := range list.Items {
+			requests[types.NamespacedName{Namespace: rotation.Namespace, Name: rotation.Name}] = true
+		}
+	}
+
+	result := make([]reconcile.Request, 0, len(requests))
+	for nn := range requests {
+		result = append(result, reconcile.Request{NamespacedName: nn})
+	}
+	return result
+}
+
+// podLifecycle limita los eventos de Pods a altas, bajas y cambios de la anotación consumes.
+func podLifecycle() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[rotationv1alpha1.ConsumesAnnotation] !=
+				e.ObjectNew.GetAnnotations()[rotationv1alpha1.ConsumesAnnotation]
+		},
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
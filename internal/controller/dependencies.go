@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// dependsOnIndex indexa las Rotations por las Rotations de las que dependen.
+const dependsOnIndex = "spec.dependsOn"
+
+// dependencyCycle devuelve el ciclo de spec.dependsOn que pasa por la Rotation, como
+// lista de nombres que empieza y acaba en ella; nil si no hay ciclo. Las dependencias
+// que no existen se ignoran aquí: las informa checkDependencies.
+func (r *RotationReconciler) dependencyCycle(ctx context.Context, rotation *rotationv1alpha1.Rotation) ([]string, error) {
+	visited := map[string]bool{}
+	var visit func(name string, path []string) ([]string, error)
+	visit = func(name string, path []string) ([]string, error) {
+		current := &rotationv1alpha1.Rotation{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: name}, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("fallo al obtener la Rotation %q: %w", name, err)
+		}
+		for _, dependency := range current.Spec.DependsOn {
+			if dependency == rotation.Name {
+				return append(path, dependency), nil
+			}
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+			cycle, err := visit(dependency, append(path, dependency))
+			if cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+
+	for _, dependency := range rotation.Spec.DependsOn {
+		if dependency == rotation.Name {
+			return []string{rotation.Name, rotation.Name}, nil
+		}
+		if visited[dependency] {
+			continue
+		}
+		visited[dependency] = true
+		cycle, err := visit(dependency, []string{rotation.Name, dependency})
+		if cycle != nil || err != nil {
+			return cycle, err
+		}
+	}
+	return nil, nil
+}
+
+// checkDependencies actualiza la condición Blocked y devuelve el motivo por el que una
+// rotación vencida debe esperar a sus dependencias; vacío si puede continuar. Una
+// dependencia está completa cuando su último intento tuvo éxito y rotó después que esta
+// Rotation; en una petición manual basta con que su último intento tuviera éxito. Las
+// dependencias de together, los miembros del RotationGroup que rotan a la vez que esta,
+// no se esperan.
+func (r *RotationReconciler) checkDependencies(ctx context.Context, rotation *rotationv1alpha1.Rotation, manual bool, together []string) (string, error) {
+	if len(rotation.Spec.DependsOn) == 0 {
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionBlocked)
+		return "", nil
+	}
+
+	cycle, err := r.dependencyCycle(ctx, rotation)
+	if err != nil {
+		return "", err
+	}
+	if cycle != nil {
+		message := "spec.dependsOn forma un ciclo: " + strings.Join(cycle, " -> ")
+		setBlocked(rotation, "DependencyCycle", message)
+		return message, nil
+	}
+
+	var pending []string
+	for _, name := range rotation.Spec.DependsOn {
+		if slices.Contains(together, name) {
+			continue
+		}
+		dependency := &rotationv1alpha1.Rotation{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: name}, dependency); err != nil {
+			if apierrors.IsNotFound(err) {
+				message := fmt.Sprintf("La dependencia %q no existe", name)
+				setBlocked(rotation, "DependencyNotFound", message)
+				return message, nil
+			}
+			return "", fmt.Errorf("fallo al obtener la dependencia %q: %w", name, err)
+		}
+		if !dependencyCompleted(rotation, dependency, manual) {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) > 0 {
+		message := "Esperando a que roten las dependencias " + strings.Join(pending, ", ")
+		setBlocked(rotation, "WaitingForDependencies", message)
+		return message, nil
+	}
+
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionBlocked,
+		Status:             metav1.ConditionFalse,
+		Reason:             "DependenciesRotated",
+		Message:            "Todas las dependencias rotaron en este ciclo",
+		ObservedGeneration: rotation.Generation,
+	})
+	return "", nil
+}
+
+// dependencyCompleted indica si la dependencia ya rotó con éxito en el ciclo actual.
+func dependencyCompleted(rotation, dependency *rotationv1alpha1.Rotation, manual bool) bool {
+	if dependency.Status.Status != "Ready" || dependency.Status.LastRotatedTime == nil {
+		return false
+	}
+	if manual || rotation.Status.LastRotatedTime == nil {
+		return true
+	}
+	return dependency.Status.LastRotatedTime.After(rotation.Status.LastRotatedTime.Time)
+}
+
+func setBlocked(rotation *rotationv1alpha1.Rotation, reason, message string) {
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionBlocked,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+}
+
+// indexDependsOn devuelve las dependencias de una Rotation para dependsOnIndex.
+func indexDependsOn(obj client.Object) []string {
+	return obj.(*rotationv1alpha1.Rotation).Spec.DependsOn
+}
+
+// rotationsDependingOn encola las Rotations que dependen de la modificada, para que
+// continúen en cuanto complete su rotación.
+func (r *RotationReconciler) rotationsDependingOn(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{dependsOnIndex: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al buscar las Rotations dependientes", "rotation", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, dependent := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: dependent.Namespace, Name: dependent.Name},
+		})
+	}
+	return requests
+}
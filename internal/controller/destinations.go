@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/consul"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/sopsgit"
+)
+
+// writeDestinations escribe el valor rotado en los destinos adicionales a Vault. Las
+// escrituras son repetibles, así un reintento puede volver a escribir el mismo valor.
+func (r *RotationReconciler) writeDestinations(ctx context.Context, rotation *rotationv1alpha1.Rotation, password string) error {
+	if rotation.Spec.Destinations == nil {
+		return nil
+	}
+
+	if git := rotation.Spec.Destinations.Git; git != nil {
+		if err := r.commitToGit(ctx, rotation, git, password); err != nil {
+			return fmt.Errorf("fallo al publicar en Git %s: %w", git.Repository, err)
+		}
+	}
+	if consul := rotation.Spec.Destinations.Consul; consul != nil {
+		if err := r.writeToConsul(ctx, rotation, consul, password); err != nil {
+			return fmt.Errorf("fallo al escribir en Consul %s: %w", consul.Key, err)
+		}
+	}
+	return nil
+}
+
+func (r *RotationReconciler) writeToConsul(ctx context.Context, rotation *rotationv1alpha1.Rotation, dest *rotationv1alpha1.ConsulDestination, password string) error {
+	var token string
+	if dest.TokenSecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: dest.TokenSecretRef}, secret); err != nil {
+			return fmt.Errorf("fallo al leer el token de Consul: %w", err)
+		}
+		token = string(secret.Data["token"])
+	}
+
+	httpClient, err := r.endpointClient(ctx, rotation.Namespace, dest.TLS)
+	if err != nil {
+		return err
+	}
+	consulClient := consul.NewClient(httpClient, dest.Address, token, dest.Datacenter)
+
+	return r.Executors.Do(ctx, "consul", func(ctx context.Context) error {
+		return consulClient.Put(ctx, dest.Key, []byte(password))
+	})
+}
+
+func (r *RotationReconciler) commitToGit(ctx context.Context, rotation *rotationv1alpha1.Rotation, dest *rotationv1alpha1.GitDestination, password string) error {
+	cfg := sopsgit.Config{
+		Repository:    dest.Repository,
+		Branch:        dest.Branch,
+		Path:          dest.Path,
+		AgeRecipients: dest.AgeRecipients,
+		KMSKeys:       dest.KMSKeys,
+	}
+	if dest.CredentialsSecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: dest.CredentialsSecretRef}, secret); err != nil {
+			return fmt.Errorf("fallo al leer las credenciales de Git: %w", err)
+		}
+		cfg.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+		cfg.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+		cfg.SSHKey = secret.Data[corev1.SSHAuthPrivateKey]
+		cfg.KnownHosts = secret.Data["known_hosts"]
+	}
+
+	key := dest.Key
+	if key == "" {
+		key = rotationv1alpha1.DefaultSecretKey
+	}
+	message := fmt.Sprintf("Rotate %s/%s", rotation.Namespace, rotation.Name)
+
+	// Los push concurrentes a un mismo repositorio se rechazarían entre sí
+	unlock := r.pathLocks.Lock("git:" + dest.Repository)
+	defer unlock()
+
+	return r.Executors.Do(ctx, "git", func(ctx context.Context) error {
+		return sopsgit.Commit(ctx, cfg, map[string]string{key: password}, message)
+	})
+}
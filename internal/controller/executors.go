@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor/ldap"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor/smtp"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor/snowflake"
+)
+
+// runExecutor aplica la contraseña nueva en el sistema configurado en spec.executor y
+// comprueba que funciona, antes de que se publique en Vault. Devuelve la contraseña que
+// seguía publicada en Vault, la que se restablece si la nueva no llega a publicarse. Con
+// spec.revocationGracePeriod, un ejecutor que lo admite añade la contraseña nueva sin
+// retirar la anterior, que se revoca al terminar el periodo de gracia.
+func (r *RotationReconciler) runExecutor(ctx context.Context, rotation *rotationv1alpha1.Rotation, password string) (string, error) {
+	exec, backend, err := r.executorFor(ctx, rotation)
+	if err != nil || exec == nil {
+		return "", err
+	}
+
+	// La contraseña vigente se lee antes de cambiarla: después no constaría en ningún sitio
+	current, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return "", fmt.Errorf("fallo al leer la contraseña vigente: %w", err)
+	}
+	previous, _ := current[valueKey(rotation)].(string)
+	revoker, graceful := exec.(executor.Revoker)
+	graceful = graceful && revokesAtSource(rotation)
+
+	return previous, r.Executors.Do(ctx, backend, func(ctx context.Context) error {
+		if err := r.Chaos.Inject(ctx, "rotación en "+backend); err != nil {
+			return err
+		}
+		if graceful {
+			if err := r.revokeSuperseded(ctx, rotation, revoker, current); err != nil {
+				return err
+			}
+			if err := revoker.Add(ctx, password); err != nil {
+				return err
+			}
+		} else if err := exec.Rotate(ctx, password); err != nil {
+			return err
+		}
+		// Un fallo inyectado en la verificación recorre el mismo restablecimiento que uno real
+		verifyErr := r.Chaos.Inject(ctx, "verificación en "+backend)
+		if verifyErr == nil {
+			verifyErr = exec.Verify(ctx, password)
+		}
+		if verifyErr == nil {
+			return nil
+		}
+
+		// La credencial anterior, aún publicada en Vault, se restablece para no dejar
+		// a los consumidores con una contraseña que el sistema no acepta
+		if err := undoExecutor(ctx, exec, rotation, previous, password); err != nil {
+			return fmt.Errorf("%w; además falló al restablecer la contraseña anterior: %v", verifyErr, err)
+		}
+		return verifyErr
+	})
+}
+
+// rollbackExecutor restablece en el sistema del ejecutor la contraseña anterior cuando
+// la nueva no llegó a publicarse en Vault: si no, los consumidores quedarían con una
+// contraseña que solo conoce ese sistema. Si la escritura sí llegó a Vault (e.g., se
+// cortó al esperar la respuesta), no se restablece: el reintento retoma ese intento.
+// Los fallos se registran y se notifican.
+func (r *RotationReconciler) rollbackExecutor(ctx context.Context, rotation *rotationv1alpha1.Rotation, previous, password, attempt string) {
+	if rotation.Spec.Executor == nil {
+		return
+	}
+	log := logf.FromContext(ctx)
+	if previous == "" && !revokesAtSource(rotation) {
+		log.Info("Sin contraseña anterior en Vault que restablecer en el sistema del ejecutor")
+		return
+	}
+	current, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err == nil && attempt != "" && current[vault.AttemptKey] == attempt {
+		return
+	}
+	if err = r.restoreExecutor(ctx, rotation, previous, password); err != nil {
+		log.Error(err, "Fallo al restablecer la contraseña anterior en el sistema del ejecutor")
+		r.event(rotation, corev1.EventTypeWarning, "ExecutorRollbackFailed",
+			fmt.Sprintf("La contraseña nueva no se publicó en Vault y no se pudo restablecer la anterior: %v", err))
+		return
+	}
+	log.Info("Contraseña anterior restablecida en el sistema del ejecutor")
+}
+
+// restoreExecutor deshace en el sistema del ejecutor el cambio a password, sin verificar
+// la contraseña anterior. No hace nada si la Rotation no tiene ejecutor.
+func (r *RotationReconciler) restoreExecutor(ctx context.Context, rotation *rotationv1alpha1.Rotation, previous, password string) error {
+	exec, backend, err := r.executorFor(ctx, rotation)
+	if err != nil || exec == nil {
+		return err
+	}
+	return r.Executors.Do(ctx, backend, func(ctx context.Context) error {
+		return undoExecutor(ctx, exec, rotation, previous, password)
+	})
+}
+
+// undoExecutor deshace el cambio a password: revoca la contraseña nueva si se añadió
+// junto a la anterior, o vuelve a aplicar previous si la sustituyó.
+func undoExecutor(ctx context.Context, exec executor.Executor, rotation *rotationv1alpha1.Rotation, previous, password string) error {
+	if revoker, ok := exec.(executor.Revoker); ok && revokesAtSource(rotation) {
+		return revoker.Revoke(ctx, password)
+	}
+	if previous == "" {
+		return nil
+	}
+	return exec.Rotate(ctx, previous)
+}
+
+// executorFor construye el ejecutor de la Rotation y el nombre de su backend para el
+// pool; devuelve nil si no tiene ejecutor.
+func (r *RotationReconciler) executorFor(ctx context.Context, rotation *rotationv1alpha1.Rotation) (executor.Executor, string, error) {
+	spec := rotation.Spec.Executor
+	switch {
+	case spec == nil:
+		return nil, "", nil
+	case spec.LDAP != nil:
+		exec, err := r.ldapExecutor(ctx, rotation.Namespace, spec.LDAP)
+		return exec, "ldap", err
+	case spec.SMTP != nil:
+		exec, err := r.smtpExecutor(ctx, rotation.Namespace, spec.SMTP)
+		return exec, "smtp", err
+	case spec.Snowflake != nil:
+		exec, err := r.snowflakeExecutor(ctx, rotation.Namespace, spec.Snowflake)
+		return exec, "snowflake", err
+	default:
+		return nil, "", nil
+	}
+}
+
+func (r *RotationReconciler) smtpExecutor(ctx context.Context, namespace string, spec *rotationv1alpha1.SMTPExecutor) (executor.Executor, error) {
+	store, err := r.ldapExecutor(ctx, namespace, &spec.LDAP)
+	if err != nil {
+		return nil, err
+	}
+	port := spec.Port
+	if port == 0 {
+		port = 587
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if spec.CASecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.CASecretRef}, secret); err != nil {
+			return nil, fmt.Errorf("fallo al leer la CA de SMTP: %w", err)
+		}
+		if tlsConfig.RootCAs, err = certPool(secret.Data["ca.crt"], "SMTP"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &smtp.Executor{
+		Store:     store,
+		Host:      spec.Host,
+		Port:      port,
+		Username:  spec.Username,
+		Mode:      spec.TLSMode,
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+func (r *RotationReconciler) ldapExecutor(ctx context.Context, namespace string, spec *rotationv1alpha1.LDAPExecutor) (executor.Executor, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.BindSecretRef}, secret); err != nil {
+		return nil, fmt.Errorf("fallo al leer las credenciales de LDAP: %w", err)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca := secret.Data["ca.crt"]; len(ca) > 0 {
+		pool, err := certPool(ca, "LDAP")
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &ldap.Executor{
+		URL:          spec.URL,
+		BindDN:       string(secret.Data["bindDN"]),
+		BindPassword: string(secret.Data["bindPassword"]),
+		UserDN:       spec.UserDN,
+		Attribute:    spec.Attribute,
+		TLSConfig:    tlsConfig,
+	}, nil
+}
+
+// certPool devuelve las CAs en PEM de ca, que verifican el servidor del sistema indicado.
+func certPool(ca []byte, system string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no se encontraron certificados válidos en la CA de %s", system)
+	}
+	return pool, nil
+}
+
+func (r *RotationReconciler) snowflakeExecutor(ctx context.Context, namespace string, spec *rotationv1alpha1.SnowflakeExecutor) (executor.Executor, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.AdminSecretRef}, secret); err != nil {
+		return nil, fmt.Errorf("fallo al leer las credenciales de Snowflake: %w", err)
+	}
+
+	exec := &snowflake.Executor{
+		Account:       spec.Account,
+		User:          spec.User,
+		AdminUser:     string(secret.Data["username"]),
+		AdminPassword: string(secret.Data["password"]),
+		AdminRole:     spec.Role,
+	}
+	if key := secret.Data["privateKey"]; len(key) > 0 {
+		privateKey, err := snowflake.ParsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		exec.AdminPrivateKey = privateKey
+	}
+	return exec, nil
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// deferredByIndex indexa Rotations y RotationGroups por el RotationFreeze que las aplaza.
+const deferredByIndex = "status.deferredBy"
+
+// activeFreeze devuelve, de los RotationFreezes activos en now que se aplican al
+// namespace, el que termina más tarde; nil si no hay ninguno.
+func activeFreeze(ctx context.Context, c client.Reader, namespace string, now time.Time) (*rotationv1alpha1.RotationFreeze, error) {
+	list := &rotationv1alpha1.RotationFreezeList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("fallo al listar los RotationFreezes: %w", err)
+	}
+
+	var namespaceLabels labels.Set
+	var active *rotationv1alpha1.RotationFreeze
+	for i := range list.Items {
+		freeze := &list.Items[i]
+		if now.Before(freeze.Spec.Start.Time) || !now.Before(freeze.Spec.End.Time) {
+			continue
+		}
+		if active != nil && !freeze.Spec.End.After(active.Spec.End.Time) {
+			continue
+		}
+
+		if freeze.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(freeze.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("namespaceSelector no válido en el RotationFreeze %q: %w", freeze.Name, err)
+			}
+			if namespaceLabels == nil {
+				ns := &corev1.Namespace{}
+				if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+					return nil, fmt.Errorf("fallo al obtener el namespace %q: %w", namespace, err)
+				}
+				namespaceLabels = labels.Set(ns.Labels)
+			}
+			if !selector.Matches(namespaceLabels) {
+				continue
+			}
+		}
+		active = freeze
+	}
+	return active, nil
+}
+
+// checkFreeze aplaza una rotación vencida mientras un RotationFreeze activo se aplique a
+// su namespace y devuelve cuánto debe esperar; cero si puede continuar.
+func (r *RotationReconciler) checkFreeze(ctx context.Context, rotation *rotationv1alpha1.Rotation, trigger string) (time.Duration, error) {
+	now := r.now()
+	freeze, err := activeFreeze(ctx, r, rotation.Namespace, now)
+	if err != nil {
+		return 0, err
+	}
+	if freeze == nil {
+		rotation.Status.DeferredBy = ""
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionFrozen)
+		return 0, nil
+	}
+
+	message := fmt.Sprintf("Rotación aplazada por el RotationFreeze %s hasta %s", freeze.Name, freeze.Spec.End.UTC().Format(time.RFC3339))
+	if freeze.Spec.Reason != "" {
+		message += ": " + freeze.Spec.Reason
+	}
+	// El aplazamiento se registra una sola vez por freeze
+	if rotation.Status.DeferredBy != freeze.Name {
+		r.event(rotation, corev1.EventTypeNormal, "RotationDeferred", message)
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(now), Trigger: trigger, Result: "Deferred"})
+	}
+	rotation.Status.DeferredBy = freeze.Name
+	rotation.Status.Status = rotationv1alpha1.ConditionFrozen
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionFrozen,
+		Status:             metav1.ConditionTrue,
+		Reason:             "RotationFreeze",
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return freeze.Spec.End.Sub(now), nil
+}
+
+// rotationsDeferredBy encola las Rotations aplazadas por el RotationFreeze modificado,
+// para que roten en cuanto se acorte o se borre.
+func (r *RotationReconciler) rotationsDeferredBy(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, list, client.MatchingFields{deferredByIndex: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al buscar las Rotations aplazadas", "freeze", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, rotation := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: rotation.Namespace, Name: rotation.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// groupMembersIndex indexa los RotationGroups por las Rotations que agrupan.
+const groupMembersIndex = "spec.rotations"
+
+// groupsOf devuelve, ordenados, los RotationGroups del namespace que incluyen la Rotation.
+func groupsOf(ctx context.Context, c client.Reader, rotation *rotationv1alpha1.Rotation) ([]string, error) {
+	list := &rotationv1alpha1.RotationGroupList{}
+	if err := c.List(ctx, list, client.InNamespace(rotation.Namespace),
+		client.MatchingFields{groupMembersIndex: rotation.Name}); err != nil {
+		return nil, fmt.Errorf("fallo al buscar los RotationGroups de la Rotation: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, group := range list.Items {
+		names = append(names, group.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// indexGroupMembers devuelve las Rotations de un RotationGroup para groupMembersIndex.
+func indexGroupMembers(obj client.Object) []string {
+	return obj.(*rotationv1alpha1.RotationGroup).Spec.Rotations
+}
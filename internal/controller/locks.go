@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"slices"
+	"sync"
+)
+
+// pathLocks serializa las rotaciones que escriben en la misma ruta del backend, de
+// modo que dos secuencias generar→escribir→sincronizar nunca se intercalan.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*pathLock
+}
+
+type pathLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// Lock bloquea la ruta indicada y devuelve la función que la libera.
+func (l *pathLocks) Lock(path string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*pathLock{}
+	}
+	lock, ok := l.locks[path]
+	if !ok {
+		lock = &pathLock{}
+		l.locks[path] = lock
+	}
+	lock.waiters++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		// Se descarta el cerrojo cuando nadie más lo espera para no acumular rutas
+		l.mu.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(l.locks, path)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// LockAll bloquea las rutas indicadas, sin repetir, en orden alfabético para que dos
+// conciliaciones que comparten varias nunca se esperen en orden inverso, y devuelve la
+// función que las libera todas.
+func (l *pathLocks) LockAll(paths ...string) func() {
+	paths = slices.Compact(slices.Sorted(slices.Values(paths)))
+	unlocks := make([]func(), 0, len(paths))
+	for _, path := range paths {
+		unlocks = append(unlocks, l.Lock(path))
+	}
+	return func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// rotationTimeouts cuenta los intentos de rotación cortados por spec.timeout.
+var rotationTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "secret_rotator_rotation_timeouts_total",
+	Help: "Rotation attempts cut short by spec.timeout.",
+}, []string{"namespace", "rotation"})
+
+// backendCircuitOpen vale 1 mientras el circuito de un backend está abierto.
+var backendCircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "secret_rotator_backend_circuit_open",
+	Help: "Whether the circuit of a backend is open after consecutive failures (1) or closed (0).",
+}, []string{"backend"})
+
+func init() {
+	metrics.Registry.MustRegister(rotationTimeouts, backendCircuitOpen)
+}
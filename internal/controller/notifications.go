@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/endpoint"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/notify"
+)
+
+// sendNotifications publica el evento de rotación en los webhooks configurados.
+// Los fallos se registran pero no invalidan la rotación ya completada.
+func (r *RotationReconciler) sendNotifications(ctx context.Context, rotation *rotationv1alpha1.Rotation) {
+	if rotation.Spec.Notifications == nil {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	event := notify.Event{
+		Name:        rotation.Name,
+		Namespace:   rotation.Namespace,
+		VaultPath:   rotation.Spec.VaultPath,
+		Fingerprint: rotation.Status.SecretFingerprint,
+		PublicKey:   rotation.Status.PublicKey,
+	}
+	if rotation.Status.LastRotatedTime != nil {
+		event.RotatedAt = rotation.Status.LastRotatedTime.Time
+	}
+
+	for _, hook := range rotation.Spec.Notifications.Webhooks {
+		httpClient, err := r.endpointClient(ctx, rotation.Namespace, hook.TLS)
+		if err != nil {
+			log.Error(err, "Fallo al preparar el cliente TLS del webhook", "url", hook.URL)
+			continue
+		}
+		if err := notify.SendWebhook(ctx, httpClient, hook.URL, event); err != nil {
+			log.Error(err, "Fallo al notificar la rotación", "url", hook.URL)
+		}
+	}
+}
+
+// endpointClient devuelve el cliente HTTP de un endpoint resolviendo su material TLS
+// desde Secrets del namespace de la Rotation. Los clientes se reutilizan mientras el
+// material no cambie.
+func (r *RotationReconciler) endpointClient(ctx context.Context, namespace string, cfg *rotationv1alpha1.EndpointTLS) (*http.Client, error) {
+	material := &endpoint.TLSMaterial{}
+	// Sin certificado propio, el endpoint recibe la identidad del operador si la hay
+	if r.EndpointIdentity != nil {
+		material.ClientCertificate = r.EndpointIdentity.Certificate
+	}
+	if cfg == nil {
+		return r.endpointClients.Get(material)
+	}
+
+	material.PinnedSHA256 = cfg.PinnedSHA256
+	material.ServerName = cfg.ServerName
+
+	if cfg.CASecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cfg.CASecretRef}, secret); err != nil {
+			return nil, fmt.Errorf("fallo al leer el Secret de CA %q: %w", cfg.CASecretRef, err)
+		}
+		// Sin ca.crt no se recurre a las CAs del sistema: el endpoint esperaba una CA propia
+		if material.CAPEM = secret.Data["ca.crt"]; len(material.CAPEM) == 0 {
+			return nil, fmt.Errorf("el Secret de CA %q no contiene la clave ca.crt", cfg.CASecretRef)
+		}
+	}
+
+	if cfg.ClientCertSecretRef != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cfg.ClientCertSecretRef}, secret); err != nil {
+			return nil, fmt.Errorf("fallo al leer el Secret del certificado de cliente %q: %w", cfg.ClientCertSecretRef, err)
+		}
+		material.CertPEM = secret.Data[corev1.TLSCertKey]
+		material.KeyPEM = secret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	return r.endpointClients.Get(material)
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// certificateGVK es el tipo Certificate de cert-manager; se usa sin tipar para no
+// depender de la API de cert-manager.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// Recursos GitOps que se refrescan tras una rotación, y la anotación que lo solicita.
+var (
+	argoCDApplicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+	fluxKustomizationGVK = schema.GroupVersionKind{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Kind: "Kustomization"}
+)
+
+const (
+	// argoCDRefreshAnnotation pide a Argo CD que compare de nuevo la Application con su origen.
+	argoCDRefreshAnnotation = "argocd.argoproj.io/refresh"
+	// fluxReconcileAnnotation pide a Flux una reconciliación inmediata ("flux reconcile").
+	fluxReconcileAnnotation = "reconcile.fluxcd.io/requestedAt"
+)
+
+// runPostRotation refresca los recursos dependientes configurados en spec.postRotation.
+// Como las notificaciones, los fallos se registran sin invalidar la rotación.
+func (r *RotationReconciler) runPostRotation(ctx context.Context, rotation *rotationv1alpha1.Rotation) {
+	if rotation.Spec.PostRotation == nil {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	for _, ref := range rotation.Spec.PostRotation.Certificates {
+		key := referenceKey(rotation, ref)
+		if err := r.renewCertificate(ctx, key); err != nil {
+			log.Error(err, "Fallo al renovar el Certificate", "certificate", key)
+		}
+	}
+
+	requestedAt := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, ref := range rotation.Spec.PostRotation.ArgoCDApplications {
+		key := referenceKey(rotation, ref)
+		if err := r.annotate(ctx, argoCDApplicationGVK, key, argoCDRefreshAnnotation, "normal"); err != nil {
+			log.Error(err, "Fallo al refrescar la Application de Argo CD", "application", key)
+		}
+	}
+	for _, ref := range rotation.Spec.PostRotation.FluxKustomizations {
+		key := referenceKey(rotation, ref)
+		if err := r.annotate(ctx, fluxKustomizationGVK, key, fluxReconcileAnnotation, requestedAt); err != nil {
+			log.Error(err, "Fallo al reconciliar la Kustomization de Flux", "kustomization", key)
+		}
+	}
+}
+
+// referenceKey resuelve una referencia con el namespace de la Rotation por defecto.
+func referenceKey(rotation *rotationv1alpha1.Rotation, ref rotationv1alpha1.ResourceReference) types.NamespacedName {
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = rotation.Namespace
+	}
+	return key
+}
+
+// annotate fija una anotación en un recurso sin tipar mediante un merge patch.
+func (r *RotationReconciler) annotate(ctx context.Context, gvk schema.GroupVersionKind, key types.NamespacedName, annotation, value string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := r.Get(ctx, key, obj); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotation] = value
+	obj.SetAnnotations(annotations)
+	return r.Patch(ctx, obj, patch)
+}
+
+// renewCertificate solicita la reemisión de un Certificate marcando la condición
+// Issuing en su estado, igual que "cmctl renew".
+func (r *RotationReconciler) renewCertificate(ctx context.Context, key types.NamespacedName) error {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	if err := r.Get(ctx, key, cert); err != nil {
+		return err
+	}
+
+	conditions, _, err := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("estado del Certificate no válido: %w", err)
+	}
+
+	issuing := map[string]interface{}{
+		"type":               "Issuing",
+		"status":             "True",
+		"reason":             "ManuallyTriggered",
+		"message":            "Certificate re-issuance requested after a secret rotation",
+		"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+	}
+	replaced := false
+	for i, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Issuing" {
+			continue
+		}
+		// Una emisión en curso ya recogerá el material nuevo
+		if condition["status"] == "True" {
+			return nil
+		}
+		conditions[i] = issuing
+		replaced = true
+	}
+	if !replaced {
+		conditions = append(conditions, issuing)
+	}
+
+	if err := unstructured.SetNestedSlice(cert.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+	return r.Status().Update(ctx, cert)
+}
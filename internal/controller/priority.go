@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// overdueLevels es el número de horas de retraso que distingue la prioridad dentro de
+// un mismo spec.priority; a partir de ahí todas las rotaciones cuentan igual de atrasadas.
+const overdueLevels = 1000
+
+// rotationPriority devuelve la prioridad en la cola de una Rotation en now: spec.priority
+// manda y, a igualdad, las horas que lleva vencida. Una Rotation que nunca rotó está
+// vencida desde su creación.
+func rotationPriority(rotation *rotationv1alpha1.Rotation, now time.Time) int {
+	due := rotation.CreationTimestamp.Time
+	if rotation.Status.LastRotatedTime != nil {
+		next, ok := reportNextRotation(rotation)
+		if !ok {
+			return int(rotation.Spec.Priority) * overdueLevels
+		}
+		due = next
+	}
+
+	overdue := 0
+	if now.After(due) {
+		overdue = min(int(now.Sub(due)/time.Hour), overdueLevels-1)
+	}
+	return int(rotation.Spec.Priority)*overdueLevels + overdue
+}
+
+// enqueueByPriority encola cada Rotation con su prioridad (rotationPriority) en la cola
+// de prioridad del controlador. Tras una caída, la lista inicial ya llega ordenada:
+// las Rotations vencidas se atienden antes que el resto.
+func (r *RotationReconciler) enqueueByPriority() handler.EventHandler {
+	enqueue := func(obj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		rotation, ok := obj.(*rotationv1alpha1.Rotation)
+		if !ok {
+			return
+		}
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(rotation)}
+		if queue, ok := q.(priorityqueue.PriorityQueue[reconcile.Request]); ok {
+			queue.AddWithOpts(priorityqueue.AddOpts{Priority: ptr.To(rotationPriority(rotation, r.now()))}, req)
+			return
+		}
+		q.Add(req)
+	}
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.Object, q)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.ObjectNew, q)
+		},
+		GenericFunc: func(_ context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.Object, q)
+		},
+	}
+}
+
+// startupOrder reparte StartupSpread entre las Rotations vencidas al arrancar, en orden
+// de prioridad: las más prioritarias y atrasadas reciben los primeros desfases. Las que
+// no estaban vencidas no aparecen y usan el desfase por nombre.
+func (r *RotationReconciler) startupOrder(ctx context.Context) map[string]time.Duration {
+	list := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, list); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al ordenar las rotaciones vencidas en el arranque")
+		return nil
+	}
+
+	type dueRotation struct {
+		name     string
+		priority int
+	}
+	var due []dueRotation
+	for i := range list.Items {
+		rotation := &list.Items[i]
+		if rotation.Spec.Suspend {
+			continue
+		}
+		if next, ok := reportNextRotation(rotation); ok && next.After(r.startedAt) {
+			continue
+		}
+		due = append(due, dueRotation{
+			name:     rotation.Namespace + "/" + rotation.Name,
+			priority: rotationPriority(rotation, r.startedAt),
+		})
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].priority != due[j].priority {
+			return due[i].priority > due[j].priority
+		}
+		return due[i].name < due[j].name
+	})
+
+	offsets := make(map[string]time.Duration, len(due))
+	for i, rotation := range due {
+		offsets[rotation.name] = time.Duration(i) * r.StartupSpread / time.Duration(len(due))
+	}
+	return offsets
+}
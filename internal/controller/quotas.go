@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// quotaPeriod es el periodo al que se aplica la cuota de rotaciones por namespace.
+const quotaPeriod = time.Hour
+
+// statusThrottled es el estado de una rotación vencida aplazada por la cuota del namespace.
+const statusThrottled = "Throttled"
+
+// namespaceQuota limita las rotaciones que se inician en cada namespace dentro de una
+// ventana deslizante de quotaPeriod, para que un tenant con intervalos muy cortos no
+// agote los límites de los backends compartidos. Se lleva en memoria: un reinicio del
+// operador la vacía.
+type namespaceQuota struct {
+	mu      sync.Mutex
+	started map[string][]time.Time
+}
+
+// reserve anota n rotaciones en el namespace si caben dentro de limit y devuelve cero;
+// si no caben no anota ninguna y devuelve cuánto falta para que haya hueco.
+func (q *namespaceQuota) reserve(namespace string, n, limit int, now time.Time) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started == nil {
+		q.started = map[string][]time.Time{}
+	}
+
+	// Se descartan los inicios que ya salieron de la ventana
+	recent := q.started[namespace]
+	for len(recent) > 0 && !now.Before(recent[0].Add(quotaPeriod)) {
+		recent = recent[1:]
+	}
+
+	// Un grupo con más miembros que la cuota se admite cuando la ventana queda vacía
+	need := min(n, limit)
+	if free := limit - len(recent); need > free {
+		q.started[namespace] = recent
+		// Hace falta que salgan de la ventana los inicios más antiguos que ocupan el hueco
+		return recent[need-free-1].Add(quotaPeriod).Sub(now)
+	}
+	for range n {
+		recent = append(recent, now)
+	}
+	q.started[namespace] = recent
+	return 0
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/registry"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/cloud/aws"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// rotateRegistryCredential emite una credencial nueva para la cuenta robot del registro
+// y compone con ella el .dockerconfigjson. La credencial anterior deja de ser válida en
+// Harbor y Quay; los tokens de ECR caducan por sí solos.
+func (r *RotationReconciler) rotateRegistryCredential(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	spec := rotation.Spec.Registry
+	if spec == nil {
+		return nil, fmt.Errorf("el tipo %s requiere spec.registry", rotationv1alpha1.TypeDockerConfigJSON)
+	}
+
+	var cred registry.Credential
+	server := spec.Server
+	err := r.Executors.Do(ctx, "registry", func(ctx context.Context) error {
+		switch spec.Provider {
+		case "Harbor":
+			return r.refreshHarborRobot(ctx, rotation, spec, &cred)
+		case "Quay":
+			return r.regenerateQuayRobot(ctx, rotation, spec, &cred)
+		case "ECR":
+			auth, err := r.ecrAuthorization(ctx, spec)
+			if err != nil {
+				return err
+			}
+			cred = auth.Credential
+			if server == "" {
+				server = auth.Server
+			}
+			return nil
+		}
+		return fmt.Errorf("proveedor de registro %q no soportado", spec.Provider)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := registry.DockerConfigJSON(server, cred)
+	if err != nil {
+		return nil, err
+	}
+	v := &rotatedValue{
+		key: rotationv1alpha1.DockerConfigSecretKey,
+		data: map[string]string{
+			rotationv1alpha1.DockerConfigSecretKey: string(payload),
+			"username":                             cred.Username,
+			"password":                             cred.Password,
+		},
+	}
+	if !cred.Expires.IsZero() {
+		v.expires = &cred.Expires
+	}
+	return v, nil
+}
+
+func (r *RotationReconciler) refreshHarborRobot(ctx context.Context, rotation *rotationv1alpha1.Rotation, spec *rotationv1alpha1.RegistrySpec, cred *registry.Credential) error {
+	api, err := r.registryAPI(ctx, rotation, spec)
+	if err != nil {
+		return err
+	}
+	length := rotation.Spec.PasswordLength
+	if length == 0 {
+		length = security.DefaultPasswordLength
+	}
+	secret, err := harborSecret(length)
+	if err != nil {
+		return err
+	}
+
+	client := registry.NewHarborClient(api.http, api.url, string(api.secret.Data["username"]), string(api.secret.Data["password"]))
+	if err := client.RefreshRobotSecret(ctx, spec.RobotID, secret); err != nil {
+		return err
+	}
+	*cred = registry.Credential{Username: spec.Robot, Password: secret}
+	return nil
+}
+
+// harborSecret genera un secreto que cumple la política de Harbor: una mayúscula, una
+// minúscula y un dígito como mínimo. Sin símbolos, que Harbor no admite.
+func harborSecret(length int) (string, error) {
+	for {
+		secret, err := security.GeneratePassword(length, false)
+		if err != nil {
+			return "", err
+		}
+		if strings.ContainsAny(secret, security.CharUpper) && strings.ContainsAny(secret, security.CharLower) &&
+			strings.ContainsAny(secret, security.CharDigits) {
+			return secret, nil
+		}
+	}
+}
+
+func (r *RotationReconciler) regenerateQuayRobot(ctx context.Context, rotation *rotationv1alpha1.Rotation, spec *rotationv1alpha1.RegistrySpec, cred *registry.Credential) error {
+	api, err := r.registryAPI(ctx, rotation, spec)
+	if err != nil {
+		return err
+	}
+	client := registry.NewQuayClient(api.http, api.url, string(api.secret.Data["token"]))
+	*cred, err = client.RegenerateRobotToken(ctx, spec.Robot)
+	return err
+}
+
+func (r *RotationReconciler) ecrAuthorization(ctx context.Context, spec *rotationv1alpha1.RegistrySpec) (registry.ECRAuthorization, error) {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	creds, err := aws.CredentialsFromEnv(ctx, httpClient)
+	if err != nil {
+		return registry.ECRAuthorization{}, err
+	}
+	region := spec.Region
+	if region == "" {
+		region = aws.Region()
+	}
+	if region == "" {
+		return registry.ECRAuthorization{}, fmt.Errorf("no se pudo determinar la región de ECR: fije spec.registry.region")
+	}
+	return registry.ECRAuthorizationToken(ctx, httpClient, creds, region, spec.RegistryID)
+}
+
+// registryAPI reúne lo necesario para llamar a la API de Harbor o Quay.
+type registryAPI struct {
+	http   *http.Client
+	url    string
+	secret *corev1.Secret
+}
+
+func (r *RotationReconciler) registryAPI(ctx context.Context, rotation *rotationv1alpha1.Rotation, spec *rotationv1alpha1.RegistrySpec) (*registryAPI, error) {
+	if spec.CredentialsSecretRef == "" {
+		return nil, fmt.Errorf("el proveedor %s requiere credentialsSecretRef", spec.Provider)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: spec.CredentialsSecretRef}, secret); err != nil {
+		return nil, fmt.Errorf("fallo al leer las credenciales del registro: %w", err)
+	}
+	httpClient, err := r.endpointClient(ctx, rotation.Namespace, spec.TLS)
+	if err != nil {
+		return nil, err
+	}
+	url := spec.APIURL
+	if url == "" {
+		url = "https://" + spec.Server
+	}
+	return &registryAPI{http: httpClient, url: url, secret: secret}, nil
+}
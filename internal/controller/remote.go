@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// defaultKubeconfigKey es la clave del kubeconfig en el Secret de un clúster remoto.
+const defaultKubeconfigKey = "kubeconfig"
+
+// remoteClusterTimeout limita cada petición a un clúster remoto.
+const remoteClusterTimeout = 30 * time.Second
+
+// syncRemoteClusters copia los Secrets de destino en cada clúster remoto. Las copias no
+// se vinculan a la Rotation: el recolector de basura de otro clúster no la conoce.
+func (r *RotationReconciler) syncRemoteClusters(ctx context.Context, rotation *rotationv1alpha1.Rotation, value *rotatedValue) error {
+	for _, cluster := range rotation.Spec.Targets.RemoteClusters {
+		remote, err := r.remoteClient(ctx, rotation, cluster)
+		if err != nil {
+			return fmt.Errorf("clúster remoto %s: %w", cluster.Name, err)
+		}
+		for _, target := range rotation.Spec.Targets.Secrets {
+			namespace := cluster.Namespace
+			if namespace == "" {
+				namespace = target.Namespace
+			}
+			if namespace == "" {
+				namespace = rotation.Namespace
+			}
+			key := target.Key
+			if key == "" {
+				key = valueKey(rotation)
+			}
+			rendered, err := renderTemplates(target.Templates, value)
+			if err != nil {
+				return fmt.Errorf("fallo al componer el Secret %s/%s: %w", namespace, target.Name, err)
+			}
+
+			err = r.Executors.Do(ctx, "cluster", func(ctx context.Context) error {
+				return writeRemoteSecret(ctx, remote, rotation, types.NamespacedName{Namespace: namespace, Name: target.Name}, key, value.value(), rendered)
+			})
+			if err != nil {
+				return fmt.Errorf("fallo al sincronizar el Secret %s/%s en el clúster remoto %s: %w", namespace, target.Name, cluster.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// remoteClient construye un cliente para el clúster remoto con el kubeconfig de su Secret.
+func (r *RotationReconciler) remoteClient(ctx context.Context, rotation *rotationv1alpha1.Rotation, cluster rotationv1alpha1.RemoteCluster) (client.Client, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: cluster.KubeconfigSecretRef}, secret); err != nil {
+		return nil, fmt.Errorf("fallo al leer el Secret del kubeconfig: %w", err)
+	}
+	key := cluster.Key
+	if key == "" {
+		key = defaultKubeconfigKey
+	}
+	kubeconfig, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("el Secret %s no contiene la clave %q", cluster.KubeconfigSecretRef, key)
+	}
+
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig no válido: %w", err)
+	}
+	if err := checkKubeconfig(config); err != nil {
+		return nil, err
+	}
+	cfg, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig no válido: %w", err)
+	}
+	cfg.Timeout = remoteClusterTimeout
+	return client.New(cfg, client.Options{Scheme: r.Scheme})
+}
+
+// checkKubeconfig rechaza los kubeconfigs que leen ficheros o ejecutan comandos: los
+// escribe el tenant y se evalúan dentro del operador, con acceso a su token y a sus
+// certificados.
+func checkKubeconfig(config *clientcmdapi.Config) error {
+	for name, auth := range config.AuthInfos {
+		if auth.Exec != nil || auth.AuthProvider != nil {
+			return fmt.Errorf("el usuario %q del kubeconfig usa un plugin de credenciales, no admitido", name)
+		}
+		if auth.TokenFile != "" || auth.ClientCertificate != "" || auth.ClientKey != "" {
+			return fmt.Errorf("el usuario %q del kubeconfig lee credenciales de ficheros, no admitido", name)
+		}
+	}
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("el clúster %q del kubeconfig lee su CA de un fichero, no admitido", name)
+		}
+	}
+	return nil
+}
+
+// writeRemoteSecret crea o actualiza la copia de un Secret de destino en un clúster remoto,
+// con sus claves compuestas por plantilla.
+func writeRemoteSecret(ctx context.Context, remote client.Client, rotation *rotationv1alpha1.Rotation, key types.NamespacedName,
+	dataKey, password string, rendered map[string][]byte) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, remote, secret, func() error {
+		if secret.CreationTimestamp.IsZero() {
+			secret.Labels = map[string]string{rotationv1alpha1.ManagedByLabel: rotationv1alpha1.ManagedByValue}
+			// El tipo es inmutable: solo se fija al crear el Secret
+			if rotation.Spec.Type == rotationv1alpha1.TypeDockerConfigJSON {
+				secret.Type = corev1.SecretTypeDockerConfigJson
+			}
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		if current := string(secret.Data[dataKey]); rotation.Spec.RetainPrevious && current != password &&
+			security.MatchFingerprint(rotation.Status.SecretFingerprint, current) {
+			secret.Data[rotationv1alpha1.PreviousSecretKey] = []byte(current)
+		}
+		secret.Data[dataKey] = []byte(password)
+		maps.Copy(secret.Data, rendered)
+		return nil
+	})
+	return err
+}
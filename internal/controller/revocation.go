@@ -0,0 +1,356 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor/ldap"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// scheduleRevocation programa, tras una rotación correcta en now, la revocación de la
+// credencial anterior al terminar spec.revocationGracePeriod: en el sistema del ejecutor,
+// si la rotación la dejó válida junto a la nueva, y en el backend, donde se destruyen las
+// versiones anteriores a la recién escrita.
+func (r *RotationReconciler) scheduleRevocation(ctx context.Context, rotation *rotationv1alpha1.Rotation, value *rotatedValue, now time.Time) {
+	if rotation.Spec.RevocationGracePeriod == "" {
+		return
+	}
+	log := logf.FromContext(ctx)
+
+	grace, err := time.ParseDuration(rotation.Spec.RevocationGracePeriod)
+	if err != nil {
+		log.Error(err, "revocationGracePeriod no válido, no se revocará la credencial anterior")
+		return
+	}
+	pending := rotationv1alpha1.PendingRevocation{RevokeAfter: metav1.NewTime(now.Add(grace))}
+
+	// La credencial anterior se conserva en Vault junto a la nueva hasta revocarla
+	if previous := value.data[value.key+rotationv1alpha1.PreviousValueSuffix]; previous != "" && revokesAtSource(rotation) {
+		if pending.CredentialFingerprint, err = security.Fingerprint(previous); err != nil {
+			log.Error(err, "Fallo al calcular la huella de la credencial anterior, no se revocará")
+		}
+	}
+	if versioned, ok := r.versionedBackend(); ok {
+		version, err := versioned.CurrentVersion(ctx, rotation.Spec.VaultPath)
+		if err != nil {
+			log.Error(err, "Fallo al leer la versión vigente, no se destruirán las versiones anteriores")
+			r.event(rotation, corev1.EventTypeWarning, "RevocationFailed", err.Error())
+		}
+		if version > 1 {
+			pending.BeforeVersion = version
+		}
+	}
+	if pending.CredentialFingerprint == "" && pending.BeforeVersion == 0 {
+		return
+	}
+
+	revocations := append(rotation.Status.PendingRevocations, pending)
+	if len(revocations) > rotationv1alpha1.MaxPendingRevocations {
+		revocations = revocations[len(revocations)-rotationv1alpha1.MaxPendingRevocations:]
+	}
+	rotation.Status.PendingRevocations = revocations
+}
+
+// revokePrevious cumple las revocaciones vencidas y las retira del estado; las que siguen
+// en su periodo de gracia se conservan. La credencial anterior se revoca en el sistema del
+// ejecutor y se destruyen las versiones que cubren. Cuando vence la de la última rotación,
+// el valor anterior deja de hacer falta y también se retira del documento vigente y de
+// los Secrets de destino.
+func (r *RotationReconciler) revokePrevious(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation) error {
+	now := r.now()
+	var before int
+	var due bool
+	var fingerprints []string
+	var remaining []rotationv1alpha1.PendingRevocation
+	for _, pending := range rotation.Status.PendingRevocations {
+		if pending.RevokeAfter.After(now) {
+			remaining = append(remaining, pending)
+			continue
+		}
+		due = true
+		before = max(before, pending.BeforeVersion)
+		if pending.CredentialFingerprint != "" {
+			fingerprints = append(fingerprints, pending.CredentialFingerprint)
+		}
+	}
+	if !due {
+		return nil
+	}
+	clearPrevious := len(remaining) == 0
+
+	path := rotation.Spec.VaultPath
+	unlock := r.pathLocks.Lock(path)
+	err := func() error {
+		if len(fingerprints) > 0 {
+			if err := r.revokeRetained(ctx, rotation, fingerprints); err != nil {
+				return err
+			}
+		}
+
+		// Un backend sin versiones (p. ej. tras cambiar de backend) no tiene nada que destruir
+		versioned, ok := r.versionedBackend()
+		if !ok {
+			return nil
+		}
+		if clearPrevious {
+			// La versión sin el valor anterior pasa a ser la vigente: las previas lo contienen
+			written, err := r.dropRetainedPrevious(ctx, rotation, versioned)
+			if err != nil {
+				return err
+			}
+			before = max(before, written)
+		}
+		if before == 0 {
+			return nil
+		}
+		if err := versioned.DestroyVersionsBefore(ctx, path, before); err != nil {
+			return fmt.Errorf("fallo al destruir las versiones anteriores de %s: %w", path, err)
+		}
+		r.event(rotation, corev1.EventTypeNormal, "PreviousVersionsDestroyed",
+			fmt.Sprintf("Destruidas las versiones de %s anteriores a la %d", path, before))
+		return nil
+	}()
+	unlock()
+	if err != nil {
+		return err
+	}
+	if clearPrevious {
+		if err := r.clearPreviousSecrets(ctx, rotation); err != nil {
+			return err
+		}
+	}
+
+	rotation.Status.PendingRevocations = remaining
+	return r.patchStatus(ctx, rotation, observed)
+}
+
+// revokeRetained revoca en el sistema del ejecutor la credencial anterior conservada en
+// Vault, si su huella es una de las de las revocaciones vencidas. Si el valor ya no consta
+// en Vault no hay nada que revocar: se avisa con un evento, porque sigue siendo válido.
+func (r *RotationReconciler) revokeRetained(ctx context.Context, rotation *rotationv1alpha1.Rotation, fingerprints []string) error {
+	log := logf.FromContext(ctx)
+	revoker, backend, err := r.revokerFor(ctx, rotation)
+	if err != nil {
+		return fmt.Errorf("fallo al preparar el ejecutor para revocar la credencial anterior: %w", err)
+	}
+	if revoker == nil {
+		log.Info("La Rotation ya no tiene un ejecutor que revoque la credencial anterior")
+		r.event(rotation, corev1.EventTypeWarning, "PreviousCredentialNotRevoked",
+			"spec.executor ya no admite revocar la credencial anterior, que sigue siendo válida en su sistema")
+		return nil
+	}
+
+	doc, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return fmt.Errorf("fallo al leer la credencial anterior: %w", err)
+	}
+	previous, _ := doc[valueKey(rotation)+rotationv1alpha1.PreviousValueSuffix].(string)
+	matches := func(fingerprint string) bool { return security.MatchFingerprint(fingerprint, previous) }
+	if previous == "" || !slices.ContainsFunc(fingerprints, matches) {
+		log.Info("La credencial anterior ya no consta en Vault, no se puede revocar")
+		r.event(rotation, corev1.EventTypeWarning, "PreviousCredentialNotRevoked",
+			"La credencial anterior ya no consta en Vault y sigue siendo válida en el sistema del ejecutor")
+		return nil
+	}
+
+	err = r.Executors.Do(ctx, backend, func(ctx context.Context) error {
+		return revoker.Revoke(ctx, previous)
+	})
+	if err != nil {
+		return fmt.Errorf("fallo al revocar la credencial anterior: %w", err)
+	}
+	r.event(rotation, corev1.EventTypeNormal, "PreviousCredentialRevoked",
+		"Revocada la credencial anterior en el sistema del ejecutor")
+	return nil
+}
+
+// revokeSuperseded revoca, antes de añadir una contraseña nueva, la credencial anterior
+// cuya revocación sigue pendiente: Vault solo conserva el último valor anterior, así que
+// tras esta rotación ya no constaría en ningún sitio y no se podría revocar al vencer.
+func (r *RotationReconciler) revokeSuperseded(ctx context.Context, rotation *rotationv1alpha1.Rotation, revoker executor.Revoker, current map[string]interface{}) error {
+	previous, _ := current[valueKey(rotation)+rotationv1alpha1.PreviousValueSuffix].(string)
+	for i := range rotation.Status.PendingRevocations {
+		pending := &rotation.Status.PendingRevocations[i]
+		if pending.CredentialFingerprint == "" {
+			continue
+		}
+		if previous == "" || !security.MatchFingerprint(pending.CredentialFingerprint, previous) {
+			logf.FromContext(ctx).Info("La credencial anterior pendiente ya no consta en Vault, no se puede revocar")
+			pending.CredentialFingerprint = ""
+			continue
+		}
+		if err := revoker.Revoke(ctx, previous); err != nil {
+			return fmt.Errorf("fallo al revocar la credencial anterior pendiente: %w", err)
+		}
+		pending.CredentialFingerprint = ""
+		r.event(rotation, corev1.EventTypeNormal, "PreviousCredentialRevoked",
+			"Revocada antes de plazo la credencial anterior: la rotación nueva la sustituye en Vault")
+	}
+	return nil
+}
+
+// revokesAtSource indica si el ejecutor de la Rotation añade la contraseña nueva sin
+// retirar la anterior, que se revoca al terminar spec.revocationGracePeriod. Solo el
+// atributo userPassword de LDAP admite varias contraseñas vigentes.
+func revokesAtSource(rotation *rotationv1alpha1.Rotation) bool {
+	spec := rotation.Spec.Executor
+	if rotation.Spec.RevocationGracePeriod == "" || spec == nil {
+		return false
+	}
+	var attribute string
+	switch {
+	case spec.LDAP != nil:
+		attribute = spec.LDAP.Attribute
+	case spec.SMTP != nil:
+		attribute = spec.SMTP.LDAP.Attribute
+	default:
+		return false
+	}
+	return attribute == "" || attribute == ldap.AttributeUserPassword
+}
+
+// revokerFor construye el ejecutor de la Rotation si revoca la credencial anterior en
+// su origen, y el nombre de su backend para el pool; devuelve nil si no lo hace.
+func (r *RotationReconciler) revokerFor(ctx context.Context, rotation *rotationv1alpha1.Rotation) (executor.Revoker, string, error) {
+	if !revokesAtSource(rotation) {
+		return nil, "", nil
+	}
+	exec, backend, err := r.executorFor(ctx, rotation)
+	if err != nil || exec == nil {
+		return nil, "", err
+	}
+	revoker, ok := exec.(executor.Revoker)
+	if !ok {
+		return nil, "", nil
+	}
+	return revoker, backend, nil
+}
+
+// dropRetainedPrevious reescribe el documento vigente de vaultPath sin el valor anterior
+// que conservan spec.retainPrevious y la revocación pendiente, y devuelve la versión escrita; 0 si no lo contenía.
+// La escritura usa check-and-set para no pisar un valor escrito entretanto.
+func (r *RotationReconciler) dropRetainedPrevious(ctx context.Context, rotation *rotationv1alpha1.Rotation, versioned backend.VersionedBackend) (int, error) {
+	path := rotation.Spec.VaultPath
+	previousKey := valueKey(rotation) + rotationv1alpha1.PreviousValueSuffix
+	version, err := versioned.CurrentVersion(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	doc, err := r.readFromVault(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := doc[previousKey]; !ok {
+		return 0, nil
+	}
+
+	cleared := maps.Clone(doc)
+	delete(cleared, previousKey)
+	err = r.doVault(ctx, func(ctx context.Context) error {
+		return r.secretBackend().Write(ctx, path, map[string]interface{}{
+			"data":    cleared,
+			"options": map[string]interface{}{"cas": version},
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("fallo al retirar el valor anterior de %s: %w", path, err)
+	}
+	return version + 1, nil
+}
+
+// clearPreviousSecrets retira la clave PreviousSecretKey de los Secrets de destino y de
+// sus copias en los clústeres remotos.
+func (r *RotationReconciler) clearPreviousSecrets(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if rotation.Spec.Targets == nil {
+		return nil
+	}
+	// Los destinos de otros namespaces se modifican con la misma autorización que al rotar
+	if err := r.authorizeTargets(ctx, rotation); err != nil {
+		return err
+	}
+	for _, target := range rotation.Spec.Targets.Secrets {
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = rotation.Namespace
+		}
+		if err := clearPreviousSecret(ctx, r.Client, types.NamespacedName{Namespace: namespace, Name: target.Name}); err != nil {
+			return fmt.Errorf("fallo al retirar el valor anterior del Secret %s/%s: %w", namespace, target.Name, err)
+		}
+	}
+
+	for _, cluster := range rotation.Spec.Targets.RemoteClusters {
+		remote, err := r.remoteClient(ctx, rotation, cluster)
+		if err != nil {
+			return fmt.Errorf("clúster remoto %s: %w", cluster.Name, err)
+		}
+		for _, target := range rotation.Spec.Targets.Secrets {
+			namespace := cluster.Namespace
+			if namespace == "" {
+				namespace = target.Namespace
+			}
+			if namespace == "" {
+				namespace = rotation.Namespace
+			}
+			err = r.Executors.Do(ctx, "cluster", func(ctx context.Context) error {
+				return clearPreviousSecret(ctx, remote, types.NamespacedName{Namespace: namespace, Name: target.Name})
+			})
+			if err != nil {
+				return fmt.Errorf("fallo al retirar el valor anterior del Secret %s/%s en el clúster remoto %s: %w", namespace, target.Name, cluster.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// clearPreviousSecret borra la clave PreviousSecretKey de un Secret, si existe.
+func clearPreviousSecret(ctx context.Context, c client.Client, key types.NamespacedName) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, ok := secret.Data[rotationv1alpha1.PreviousSecretKey]; !ok {
+		return nil
+	}
+	delete(secret.Data, rotationv1alpha1.PreviousSecretKey)
+	return c.Update(ctx, secret)
+}
+
+// withPendingRevocations adelanta la próxima conciliación a la siguiente revocación
+// pendiente si vence antes; una revocación vencida que falló se reintenta en 30 segundos.
+func (r *RotationReconciler) withPendingRevocations(rotation *rotationv1alpha1.Rotation, result ctrl.Result) ctrl.Result {
+	for _, pending := range rotation.Status.PendingRevocations {
+		wait := pending.RevokeAfter.Sub(r.now())
+		if wait <= 0 {
+			wait = 30 * time.Second
+		}
+		if result.RequeueAfter == 0 || wait < result.RequeueAfter {
+			result.RequeueAfter = wait
+		}
+	}
+	return result
+}
+
+// versionedBackend devuelve el almacén de los valores rotados si conserva versiones
+// anteriores. No pasa por la inyección de fallos, que solo afecta a las escrituras.
+func (r *RotationReconciler) versionedBackend() (backend.VersionedBackend, bool) {
+	var store backend.SecretBackend = r.Backend
+	if store == nil {
+		store = r.vaultSessions()
+	}
+	versioned, ok := store.(backend.VersionedBackend)
+	return versioned, ok
+}
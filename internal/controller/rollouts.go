@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// rolloutRecheck es la frecuencia con la que se vuelve a comprobar un despliegue en curso.
+const rolloutRecheck = 30 * time.Second
+
+// checkRollout aplaza una rotación vencida, con spec.waitForRollout, mientras alguno de
+// sus consumidores esté a mitad de un despliegue: sus Pods nuevos arrancarían con la
+// credencial que se va a sustituir. Devuelve cuánto esperar; cero si puede continuar.
+// Una rotación manual no espera.
+func (r *RotationReconciler) checkRollout(ctx context.Context, rotation *rotationv1alpha1.Rotation, manual bool) (time.Duration, error) {
+	var rolling []string
+	if rotation.Spec.WaitForRollout && !manual {
+		for _, consumer := range rotation.Status.Consumers {
+			progressing, err := r.rolloutInProgress(ctx, consumer)
+			if err != nil {
+				return 0, err
+			}
+			if progressing {
+				rolling = append(rolling, consumer.Kind+" "+consumer.Namespace+"/"+consumer.Name)
+			}
+		}
+	}
+	if len(rolling) == 0 {
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionWaitingForRollout)
+		return 0, nil
+	}
+
+	message := "Rotación aplazada hasta que terminen los despliegues de " + strings.Join(rolling, ", ")
+	// El aplazamiento se notifica una sola vez por espera
+	if !meta.IsStatusConditionTrue(rotation.Status.Conditions, rotationv1alpha1.ConditionWaitingForRollout) {
+		r.event(rotation, corev1.EventTypeNormal, "WaitingForRollout", message)
+	}
+	rotation.Status.Status = rotationv1alpha1.ConditionWaitingForRollout
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionWaitingForRollout,
+		Status:             metav1.ConditionTrue,
+		Reason:             "RolloutInProgress",
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return rolloutRecheck, nil
+}
+
+// rolloutInProgress indica si el workload consumidor está desplegando una revisión nueva,
+// con el mismo criterio que "kubectl rollout status". Un Deployment que superó su
+// progressDeadlineSeconds no se considera en curso: no retiene la rotación para siempre.
+func (r *RotationReconciler) rolloutInProgress(ctx context.Context, consumer rotationv1alpha1.ConsumerReference) (bool, error) {
+	key := types.NamespacedName{Namespace: consumer.Namespace, Name: consumer.Name}
+	var obj client.Object
+	switch consumer.Kind {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	case "DaemonSet":
+		obj = &appsv1.DaemonSet{}
+	default:
+		return false, nil
+	}
+	if err := r.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("fallo al obtener el %s %s: %w", consumer.Kind, key, err)
+	}
+
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return deploymentRollingOut(workload), nil
+	case *appsv1.StatefulSet:
+		return statefulSetRollingOut(workload), nil
+	case *appsv1.DaemonSet:
+		return daemonSetRollingOut(workload), nil
+	}
+	return false, nil
+}
+
+func deploymentRollingOut(d *appsv1.Deployment) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return true
+	}
+	for _, condition := range d.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return false
+		}
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas < replicas ||
+		d.Status.Replicas > d.Status.UpdatedReplicas ||
+		d.Status.AvailableReplicas < d.Status.UpdatedReplicas
+}
+
+func statefulSetRollingOut(s *appsv1.StatefulSet) bool {
+	if s.Generation > s.Status.ObservedGeneration {
+		return true
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return false
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	if s.Status.ReadyReplicas < replicas {
+		return true
+	}
+	// Con partition solo se actualizan los Pods de ordinal igual o superior
+	if rolling := s.Spec.UpdateStrategy.RollingUpdate; rolling != nil && rolling.Partition != nil {
+		return s.Status.UpdatedReplicas < replicas-*rolling.Partition
+	}
+	return s.Status.UpdateRevision != s.Status.CurrentRevision
+}
+
+func daemonSetRollingOut(d *appsv1.DaemonSet) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return true
+	}
+	if d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return false
+	}
+	return d.Status.UpdatedNumberScheduled < d.Status.DesiredNumberScheduled ||
+		d.Status.NumberAvailable < d.Status.DesiredNumberScheduled
+}
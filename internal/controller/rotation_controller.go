@@ -2,32 +2,118 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"maps"
+	"strconv"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	// Importación de tu API (CRD) y el nuevo paquete de seguridad
 	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/chaos"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/endpoint"
 	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/workpool"
 
 	// Dependencias externas
-	"github.com/hashicorp/vault/api"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 )
 
 // RotationReconciler reconciles a Rotation object
 type RotationReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// VaultConfig contiene la dirección y autenticación de Vault.
+	VaultConfig vault.Config
+	// VaultCertSecret, si tiene nombre, referencia un Secret kubernetes.io/tls con
+	// el certificado de cliente para el método cert de Vault.
+	VaultCertSecret types.NamespacedName
+	// EndpointIdentity, si existe, es el certificado de cliente que se presenta a webhooks y
+	// destinos sin certificado propio (e.g., el X.509-SVID de SPIFFE del operador).
+	EndpointIdentity vault.CertificateSource
+	// Recorder publica Events sobre las Rotations; nil no publica.
+	Recorder record.EventRecorder
+	// Executors limita las escrituras concurrentes en los backends; nil no limita.
+	Executors *workpool.Pool
+	// StartupSpread es la ventana tras el arranque en la que se reparten las rotaciones
+	// vencidas mientras el operador no estaba en marcha; cero las ejecuta de inmediato.
+	StartupSpread time.Duration
+	// NamespaceQuota es el máximo de rotaciones que se inician por namespace en una hora,
+	// comunes a Rotations y RotationGroups; cero no limita.
+	NamespaceQuota int
+	// AllowCrossNamespaceTargets desactiva la comprobación de acceso a los Secrets de
+	// destino en otros namespaces.
+	AllowCrossNamespaceTargets bool
+	// Backend es el almacén en el que se publican los valores rotados; nil usa las
+	// sesiones de Vault construidas a partir de VaultConfig.
+	Backend backend.SecretBackend
+	// Clock da la hora con la que se evalúan intervalos, plazos y freezes; nil usa el
+	// reloj del sistema. Los tests lo sustituyen para adelantar el tiempo.
+	Clock clock.PassiveClock
+	// CircuitThreshold es el número de fallos consecutivos de Vault que abren su circuito:
+	// las rotaciones se aplazan sin intentarse hasta la próxima prueba. Cero no lo abre.
+	CircuitThreshold int
+	// CircuitCooldown es el tiempo entre pruebas de un circuito abierto; cero usa
+	// DefaultCircuitCooldown.
+	CircuitCooldown time.Duration
+	// Chaos inyecta fallos y latencias en las escrituras en el backend y en los
+	// ejecutores, solo para pruebas y staging; nil no inyecta nada.
+	Chaos *chaos.Injector
+
+	startedAt      time.Time
+	startupOnce    sync.Once
+	startupOffsets map[string]time.Duration
+	sessionsOnce   sync.Once
+	sessions       *vault.Sessions
+	pathLocks      pathLocks
+	quotas         namespaceQuota
+	circuits       backendCircuits
+	// endpointClients guarda los clientes HTTP de webhooks, registros y destinos
+	endpointClients endpoint.Clients
 }
 
 // +kubebuilder:rbac:groups=rotation.security.io,resources=rotations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rotation.security.io,resources=rotations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=rotation.security.io,resources=rotations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationgroups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationfreezes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=update
+// +kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;patch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
 
 // Reconcile es la función principal del bucle de control.
 func (r *RotationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -39,132 +125,616 @@ func (r *RotationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		// Si el recurso no se encuentra (fue borrado), ignorar la solicitud.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// Una Rotation borrada no rota: solo se limpian los Secrets que creó en otros namespaces
+	if !rotation.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.cleanupTargets(ctx, rotation)
+	}
+	if err := r.ensureCleanupFinalizer(ctx, rotation); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Copia observada: el estado se envía como parche contra ella y no como Update,
+	// así otros escritores del estado no provocan conflictos ni reintentos.
+	observed := rotation.DeepCopy()
 
 	// 2. Determinar si se necesita rotar
 	rotationInterval, err := time.ParseDuration(rotation.Spec.RotationInterval)
 	if err != nil {
-		log.Error(err, "Intervalo de rotación no válido, saltando reconciliación", "interval", rotation.Spec.RotationInterval)
-		// No se puede continuar, pero no reintentar a menos que el CRD sea corregido.
+		// No se puede continuar ni reintentar hasta que cambie el spec (nueva generación)
+		return ctrl.Result{}, r.markInvalidSpec(ctx, rotation, observed,
+			fmt.Sprintf("rotationInterval %q no es una duración válida: %v", rotation.Spec.RotationInterval, err))
+	}
+	clearInvalidSpec(rotation)
+
+	// Una Rotation suspendida no rota ni se reprograma hasta que se reanude
+	if rotation.Spec.Suspend {
+		log.V(1).Info("Rotación suspendida")
 		return ctrl.Result{}, nil
 	}
 
-	// Comprobar la última rotación
-	needsRotation := true
-	if rotation.Status.LastRotatedTime != nil {
-		timeSinceLastRotation := time.Since(rotation.Status.LastRotatedTime.Time)
-		if timeSinceLastRotation < rotationInterval {
-			needsRotation = false
-			log.V(1).Info("No se necesita rotación",
-				"tiempoRestante", rotationInterval-timeSinceLastRotation,
-				"próximaRotación", rotation.Status.LastRotatedTime.Add(rotationInterval),
-			)
-			// Reintentar justo cuando se cumpla el intervalo
-			return ctrl.Result{RequeueAfter: rotationInterval - timeSinceLastRotation}, nil
-		}
+	// Un valor nuevo en la anotación rotate-now fuerza la rotación aunque no haya vencido el intervalo
+	rotateNow := rotation.Annotations[rotationv1alpha1.RotateNowAnnotation]
+	manualRequest := rotateNow != "" && rotateNow != rotation.Status.LastRotateNowRequest
+	trigger := "Schedule"
+	if manualRequest {
+		trigger = "Manual"
 	}
 
-	if !needsRotation {
-		return ctrl.Result{}, nil
+	// Inventario de consumidores afectados por la rotación
+	r.refreshConsumers(ctx, rotation)
+
+	// Los Secrets de destino borrados o modificados se restablecen sin esperar a la próxima rotación
+	if err := r.healTargets(ctx, rotation); err != nil {
+		log.Error(err, "Fallo al restablecer los Secrets de destino")
+		r.event(rotation, corev1.EventTypeWarning, "TargetHealFailed", err.Error())
+	}
+
+	// Las credenciales anteriores cuyo periodo de gracia terminó se revocan
+	if err := r.revokePrevious(ctx, rotation, observed); err != nil {
+		log.Error(err, "Fallo al revocar la credencial anterior")
+		r.event(rotation, corev1.EventTypeWarning, "RevocationFailed", err.Error())
+	}
+
+	// Con spec.adoptExisting el valor ya publicado cuenta como la primera rotación
+	if err := r.adoptExisting(ctx, rotation, rotationInterval); err != nil {
+		log.Error(err, "Fallo al adoptar el valor existente", "path", rotation.Spec.VaultPath)
+		return ctrl.Result{}, err
+	}
+
+	// La rotación solo continúa si ha vencido y ninguna condición la retiene
+	if result, held, err := r.holdRotation(ctx, rotation, observed, rotationInterval, trigger, manualRequest); held || err != nil {
+		return r.withPendingRevocations(rotation, result), err
 	}
 
 	// ----------------------------------------------------
 	// 3. Generar, Escribir en Vault, y Actualizar Estado
 	// ----------------------------------------------------
 
-	log.Info("Iniciando rotación de secreto")
+	// Ninguna otra rotación sobre alguna de sus rutas puede intercalarse con esta
+	unlock := r.pathLocks.LockAll(rotationPaths(rotation)...)
+	defer unlock()
 
-	// A. Generación Segura de Contraseña con Go
-	passwordLength := rotation.Spec.PasswordLength
-	if passwordLength == 0 {
-		passwordLength = 16 // Usar valor por defecto si no se especifica
-	}
+	log.Info("Iniciando rotación de secreto", "trigger", trigger)
 
-	newPassword, err := security.GeneratePassword(passwordLength, rotation.Spec.IncludeSymbols)
+	// spec.timeout limita los pasos que hablan con otros sistemas; el estado se sigue
+	// registrando con el contexto de la conciliación aunque el plazo haya vencido
+	opCtx, cancel := context.WithTimeout(ctx, rotationTimeout(rotation))
+	defer cancel()
+
+	// A. Un intento anterior pudo escribir en Vault sin llegar a registrarse en el
+	// estado: si es así se retoma su valor en lugar de invalidarlo con otro nuevo.
+	vaultPath := rotation.Spec.VaultPath
+	attempt, value, err := r.pendingAttempt(opCtx, rotation)
 	if err != nil {
-		log.Error(err, "Fallo al generar la contraseña segura")
-		rotation.Status.Status = "ErrorGeneracion"
-		r.Status().Update(ctx, rotation)
-		return ctrl.Result{}, err // Reintentar la generación
+		log.Error(err, "Fallo al comprobar el intento de rotación pendiente", "path", vaultPath)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	resumed := value != nil
+	if resumed {
+		log.Info("Retomando un intento de rotación ya escrito en Vault", "attempt", attempt)
 	}
 
-	// B. Conexión y Escritura en Vault
-	// NOTA: Esta es una implementación mock. En un entorno real, la autenticación
-	// sería la parte más compleja (Auth/Kubernetes).
+	// Con la estrategia Canary el valor nuevo se prueba en una ruta de staging y solo
+	// continúa, para promoverlo, cuando el canario lo valida
+	attempt, value, held, err := r.runCanary(ctx, opCtx, rotation, observed, trigger, attempt, value)
+	if held || err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
 
-	vaultPath := rotation.Spec.VaultPath
-	err = r.writeToVault(vaultPath, newPassword)
+	// B. Generación Segura del nuevo valor según el tipo de la Rotation
+	if value == nil {
+		value, err = r.generateValue(opCtx, rotation)
+		if err != nil {
+			log.Error(err, "Fallo al generar el nuevo valor", "type", rotation.Spec.Type)
+			rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorGeneracion")
+			recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+			_ = r.patchStatus(ctx, rotation, observed)
+			return ctrl.Result{}, err // Reintentar la generación
+		}
+
+		// El intento se registra antes de escribir para reconocerlo si el estado no llega a guardarse
+		attempt, err = r.startAttempt(ctx, rotation)
+		if err != nil {
+			log.Error(err, "Fallo al registrar el intento de rotación")
+			return ctrl.Result{}, err
+		}
+	}
+	secretValue := value.value()
+
+	// La huella se calcula antes de escribir para no dejar un valor en Vault sin registrar
+	fingerprint, err := security.Fingerprint(secretValue)
 	if err != nil {
-		log.Error(err, "Fallo al escribir en HashiCorp Vault", "path", vaultPath)
-		rotation.Status.Status = "ErrorVault"
-		r.Status().Update(ctx, rotation)
+		log.Error(err, "Fallo al calcular la huella del secreto")
+		return ctrl.Result{}, err
+	}
+
+	// C. Conexión y Escritura en Vault
+	if !resumed {
+		// El valor vigente se copia antes de sustituirlo en ningún sistema
+		if err := r.backupCurrent(opCtx, rotation); err != nil {
+			log.Error(err, "Fallo al copiar el valor vigente", "path", vaultPath)
+			rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorBackup")
+			recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+			_ = r.patchStatus(ctx, rotation, observed)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
+		}
+
+		// La credencial se cambia y se verifica en el sistema que la usa antes de publicarla
+		previous, err := r.runExecutor(opCtx, rotation, secretValue)
+		if err != nil {
+			log.Error(err, "Fallo al aplicar la contraseña en el sistema de destino")
+			rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorEjecutor")
+			recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+			_ = r.patchStatus(ctx, rotation, observed)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
+		}
+
+		if err := r.writeToVault(opCtx, rotation, value, attempt); err != nil {
+			log.Error(err, "Fallo al escribir en HashiCorp Vault", "path", vaultPath)
+			// El sistema del ejecutor ya tiene la contraseña nueva y Vault no: se le
+			// devuelve la anterior, que sigue publicada
+			r.rollbackExecutor(ctx, rotation, previous, secretValue, attempt)
+			rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorVault")
+			recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+			_ = r.patchStatus(ctx, rotation, observed)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
+		}
+
+		log.Info("Secreto escrito exitosamente en Vault", "path", vaultPath)
+	}
+
+	// Los destinos adicionales se escriben también al retomar un intento: no consta si
+	// el intento anterior llegó a hacerlo
+	if err := r.writeDestinations(opCtx, rotation, secretValue); err != nil {
+		log.Error(err, "Fallo al escribir en los destinos adicionales")
+		rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorDestino")
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+		_ = r.patchStatus(ctx, rotation, observed)
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
 	}
 
-	log.Info("Secreto escrito exitosamente en Vault", "path", vaultPath)
+	// D. Sincronizar los Secrets de destino con el nuevo valor
+	if err := r.syncTargets(opCtx, rotation, value); err != nil {
+		log.Error(err, "Fallo al sincronizar los Secrets de destino")
+		rotation.Status.Status = r.failedStatus(opCtx, rotation, "ErrorSync")
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+		_ = r.patchStatus(ctx, rotation, observed)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
+	}
 
-	// C. Actualizar el Estado del CRD
-	now := metav1.Now()
-	rotation.Status.LastRotatedTime = &now
-	rotation.Status.Status = "Ready"
-	if err := r.Status().Update(ctx, rotation); err != nil {
+	// E. Actualizar el Estado del CRD
+	now := metav1.NewTime(r.now())
+	// Un valor que caduca se renueva antes de su vencimiento aunque no se cumpla el intervalo
+	next := metav1.NewTime(nextRotationAfter(now.Time, rotationInterval, value))
+	recordRotated(rotation, value, fingerprint, now, next)
+	if manualRequest {
+		rotation.Status.LastRotateNowRequest = rotateNow
+	}
+	rotation.Status.LastAttemptID = attempt
+	// La aprobación vale solo para esta rotación
+	rotation.Status.PendingApproval = nil
+	r.scheduleRevocation(ctx, rotation, value, now.Time)
+	recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{
+		Time:        now,
+		Trigger:     trigger,
+		Result:      rotation.Status.Status,
+		Fingerprint: fingerprint,
+	})
+	if err := r.patchStatus(ctx, rotation, observed); err != nil {
 		log.Error(err, "Fallo al actualizar el estado de rotación")
 		return ctrl.Result{}, err
 	}
 
-	// Reintentar la conciliación cuando el intervalo se cumpla de nuevo
-	return ctrl.Result{RequeueAfter: rotationInterval}, nil
+	// F. Notificar a los endpoints configurados (sin exponer el secreto)
+	r.sendNotifications(ctx, rotation)
+
+	// G. Refrescar los recursos que dependen del valor rotado
+	r.runPostRotation(ctx, rotation)
+
+	// Reintentar la conciliación cuando toque la próxima rotación o revocación
+	return r.withPendingRevocations(rotation, ctrl.Result{RequeueAfter: next.Sub(r.now())}), nil
+}
+
+// holdRotation aplica las condiciones que retienen una rotación: conflicto de ruta,
+// pertenencia a un RotationGroup, plazo sin vencer, freezes, despliegues en curso de los
+// consumidores, dependencias, aprobación, autorización de los destinos y cuota del
+// namespace. Si la retiene, deja el estado parcheado y devuelve el resultado de la
+// conciliación con held a true.
+func (r *RotationReconciler) holdRotation(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation,
+	interval time.Duration, trigger string, manual bool) (ctrl.Result, bool, error) {
+	log := logf.FromContext(ctx)
+
+	// Un canario en curso superó las retenciones al lanzarse: se sigue hasta su resultado
+	if canaryRunning(rotation) {
+		return ctrl.Result{}, false, nil
+	}
+
+	// Dos Rotations sobre la misma ruta se sobrescribirían: solo rota la más antigua
+	blocked, err := r.checkPathConflict(ctx, rotation)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if blocked {
+		log.Info("Ruta de Vault compartida con una Rotation más antigua, saltando rotación", "path", rotation.Spec.VaultPath)
+		rotation.Status.Status = rotationv1alpha1.ConditionPathConflict
+		return ctrl.Result{}, true, r.patchStatus(ctx, rotation, observed)
+	}
+
+	// Los miembros de un RotationGroup solo rotan junto al resto del grupo
+	groups, err := groupsOf(ctx, r, rotation)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if len(groups) > 0 {
+		log.V(1).Info("Rotación gestionada por un RotationGroup", "groups", groups)
+		if !equality.Semantic.DeepEqual(observed.Status, rotation.Status) {
+			return ctrl.Result{}, true, r.patchStatus(ctx, rotation, observed)
+		}
+		return ctrl.Result{}, true, nil
+	}
+
+	// Comprobar la última rotación; la próxima rotación prevista en el estado se
+	// conserva aunque el operador se reinicie
+	var wait time.Duration
+	if rotation.Status.LastRotatedTime != nil && !manual {
+		next := nextRotation(rotation, interval)
+		if wait = next.Sub(r.now()); wait > 0 {
+			log.V(1).Info("No se necesita rotación", "tiempoRestante", wait, "próximaRotación", next)
+		}
+	}
+	// Una rotación vencida aún espera a un día permitido por spec.schedule y, tras un
+	// arranque, a su turno en lugar de ejecutarse todas a la vez
+	if wait <= 0 && !manual {
+		if wait, err = r.dueDelay(ctx, rotation); err != nil {
+			return ctrl.Result{}, true, err
+		}
+	}
+	if wait > 0 {
+		if !equality.Semantic.DeepEqual(observed.Status, rotation.Status) {
+			if err := r.patchStatus(ctx, rotation, observed); err != nil {
+				return ctrl.Result{}, true, err
+			}
+		}
+		// Reintentar justo cuando se cumpla el plazo
+		return ctrl.Result{RequeueAfter: wait}, true, nil
+	}
+
+	// Durante un RotationFreeze las rotaciones vencidas se aplazan hasta que termine
+	frozen, err := r.checkFreeze(ctx, rotation, trigger)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if frozen > 0 {
+		log.Info("Rotación aplazada por un RotationFreeze", "freeze", rotation.Status.DeferredBy, "espera", frozen)
+		return r.holdFor(ctx, rotation, observed, frozen)
+	}
+
+	// No se cambia la credencial bajo Pods que aún arrancan con la anterior
+	rollout, err := r.checkRollout(ctx, rotation, manual)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if rollout > 0 {
+		log.Info("Rotación aplazada por un despliegue en curso de sus consumidores")
+		return r.holdFor(ctx, rotation, observed, rollout)
+	}
+
+	// Una rotación vencida espera a que sus dependencias roten antes en este ciclo
+	waiting, err := r.checkDependencies(ctx, rotation, manual, nil)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if waiting != "" {
+		// La rotación de una dependencia vuelve a encolar esta Rotation
+		log.Info("Rotación bloqueada por sus dependencias", "motivo", waiting)
+		rotation.Status.Status = rotationv1alpha1.ConditionBlocked
+		return ctrl.Result{}, true, r.patchStatus(ctx, rotation, observed)
+	}
+
+	// Con approvalRequired la rotación solo continúa con una solicitud aprobada y vigente
+	approvalWait, err := r.checkApproval(rotation, trigger, r.now())
+	if err != nil {
+		return ctrl.Result{}, true, r.markInvalidSpec(ctx, rotation, observed, err.Error())
+	}
+	if approvalWait > 0 {
+		log.Info("Rotación pendiente de aprobación", "solicitud", rotation.Status.PendingApproval.ID)
+		// La aprobación vuelve a encolar la Rotation; si no llega, la solicitud se renueva al caducar
+		return r.holdFor(ctx, rotation, observed, approvalWait)
+	}
+
+	// Los destinos en otros namespaces deben estar autorizados antes de cambiar nada
+	if err := r.authorizeTargets(ctx, rotation); err != nil {
+		log.Error(err, "Secret de destino no autorizado")
+		r.event(rotation, corev1.EventTypeWarning, "TargetForbidden", err.Error())
+		rotation.Status.Status = "ErrorAutorizacion"
+		recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.now()), Trigger: trigger, Result: rotation.Status.Status})
+		_ = r.patchStatus(ctx, rotation, observed)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, true, nil // Reintentar en 30 segundos
+	}
+
+	// Con el circuito de Vault abierto la rotación espera a la próxima prueba del backend
+	if open := r.checkCircuit(rotation); open > 0 {
+		log.Info("Circuito de Vault abierto, aplazando rotación", "espera", open)
+		return r.holdFor(ctx, rotation, observed, open)
+	}
+
+	// La cuota por namespace espacia las rotaciones de un tenant con intervalos muy cortos
+	if throttled := r.quotas.reserve(rotation.Namespace, 1, r.NamespaceQuota, r.now()); throttled > 0 {
+		log.Info("Cuota de rotaciones del namespace agotada, aplazando rotación", "espera", throttled)
+		rotation.Status.Status = statusThrottled
+		if !equality.Semantic.DeepEqual(observed.Status, rotation.Status) {
+			if err := r.patchStatus(ctx, rotation, observed); err != nil {
+				return ctrl.Result{}, true, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: throttled}, true, nil
+	}
+
+	return ctrl.Result{}, false, nil
+}
+
+// holdFor deja el estado parcheado y retiene la rotación hasta pasado wait.
+func (r *RotationReconciler) holdFor(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation, wait time.Duration) (ctrl.Result, bool, error) {
+	if err := r.patchStatus(ctx, rotation, observed); err != nil {
+		return ctrl.Result{}, true, err
+	}
+	return ctrl.Result{RequeueAfter: wait}, true, nil
+}
+
+// patchStatus declara con server-side apply el estado de la Rotation si cambió respecto
+// a observed; el spec nunca se escribe desde aquí.
+func (r *RotationReconciler) patchStatus(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation) error {
+	if equality.Semantic.DeepEqual(observed.Status, rotation.Status) {
+		return nil
+	}
+	return applyStatus(ctx, r.Client, rotation, &rotation.Status)
+}
+
+// recordRotated registra en el estado el valor de una rotación completada en now.
+func recordRotated(rotation *rotationv1alpha1.Rotation, value *rotatedValue, fingerprint string, now, next metav1.Time) {
+	rotation.Status.LastRotatedTime = &now
+	rotation.Status.Status = "Ready"
+	meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionTimedOut)
+	rotation.Status.SecretFingerprint = fingerprint
+	rotation.Status.FIPSMode = security.FIPSMode()
+	rotation.Status.NextRotationTime = &next
+	rotation.Status.ExpirationTime = nil
+	if value.expires != nil {
+		expires := metav1.NewTime(*value.expires)
+		rotation.Status.ExpirationTime = &expires
+	}
+	rotation.Status.PublicKey = value.data[publicKeyKey]
+	rotation.Status.KeyFingerprint = value.data[keyFingerprintKey]
+	rotation.Status.ActiveKeyID, _ = strconv.ParseInt(value.data[activeKeyIDKey], 10, 64)
+}
+
+// recordHistory añade un intento al historial conservando solo los más recientes.
+func recordHistory(rotation *rotationv1alpha1.Rotation, entry rotationv1alpha1.RotationHistoryEntry) {
+	history := append([]rotationv1alpha1.RotationHistoryEntry{entry}, rotation.Status.History...)
+	if len(history) > rotationv1alpha1.MaxHistoryEntries {
+		history = history[:rotationv1alpha1.MaxHistoryEntries]
+	}
+	rotation.Status.History = history
 }
 
 // ----------------------------------------------------
-// LÓGICA DE VAULT (MOCK para demostración)
+// LÓGICA DE VAULT
 // ----------------------------------------------------
 
-// writeToVaultMock simula la escritura de la contraseña en una ruta de Vault.
-// En un entorno real, esta función contendría la inicialización del cliente de Vault,
-// la autenticación (e.g., usando ServiceAccount), y la llamada a vaultClient.Logical().Write().
-func (r *RotationReconciler) writeToVault(path string, password string) error {
+// mergeRetries es el número de veces que se repite una escritura en modo Merge cuya
+// ruta cambió entre la lectura y la escritura.
+const mergeRetries = 3
 
-	// ** 1. Configuración de Vault (Real) **
-	config := api.DefaultConfig()
-	config.Address = "http://vault.vault-system:8200" // Dirección de Vault dentro de K8s
-	client, err := api.NewClient(config)
+// writeToVault escribe el valor rotado en spec.vaultPath y en las rutas adicionales de
+// spec.vaultPaths usando la autenticación configurada en el operador, junto al
+// identificador del intento. Las rutas adicionales se escriben antes: con AllOrNothing,
+// si después falla vaultPath se restablecen y ninguna queda con un valor sin publicar.
+func (r *RotationReconciler) writeToVault(ctx context.Context, rotation *rotationv1alpha1.Rotation, value *rotatedValue, attempt string) error {
+	doc := vault.SecretData(value.vaultData(), "secret-rotator-operator", attempt)
+	mirrored, err := r.writeVaultPaths(ctx, rotation, doc)
 	if err != nil {
-		return fmt.Errorf("fallo al crear el cliente de Vault: %w", err)
+		return err
+	}
+	if err := r.writeVaultPath(ctx, rotation, rotation.Spec.VaultPath, doc); err != nil {
+		return errors.Join(err, r.rollbackVaultPaths(ctx, rotation, mirrored))
+	}
+	return nil
+}
+
+// writeVaultPath escribe doc en una ruta de Vault según spec.writeMode: en modo Merge
+// solo se sustituyen las claves de la Rotation y el resto del documento se conserva.
+func (r *RotationReconciler) writeVaultPath(ctx context.Context, rotation *rotationv1alpha1.Rotation, path string, doc map[string]interface{}) error {
+	return r.doVault(ctx, func(ctx context.Context) error {
+		if rotation.Spec.WriteMode != rotationv1alpha1.WriteModeMerge {
+			return r.secretBackend().Write(ctx, path, doc)
+		}
+		return r.mergeVault(ctx, path, doc)
+	})
+}
+
+// mergeVault escribe las claves de doc sobre el documento vigente de path con
+// check-and-set: si otro escritor cambia la ruta entre la lectura y la escritura, se
+// vuelve a leer y a combinar en lugar de sobrescribir su cambio.
+func (r *RotationReconciler) mergeVault(ctx context.Context, path string, doc map[string]interface{}) error {
+	versioned, ok := r.versionedBackend()
+	if !ok {
+		return errors.New("el backend no admite escrituras con check-and-set, necesarias en modo Merge")
 	}
+	owned, _ := doc["data"].(map[string]interface{})
 
-	// ** 2. Autenticación (Real: Usar Auth/Kubernetes)**
-	// En producción, el token se obtendría mediante el ServiceAccount del Pod.
-	// client.SetToken("s.xyz123...")
+	var err error
+	for range mergeRetries {
+		// La versión se lee antes que los datos: si cambia entre ambas lecturas la
+		// escritura se rechaza y se reintenta
+		var version int
+		if version, err = versioned.CurrentVersion(ctx, path); err != nil {
+			return err
+		}
+		var current map[string]interface{}
+		if current, err = r.secretBackend().Read(ctx, path); err != nil {
+			return err
+		}
+		merged := maps.Clone(current)
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+		maps.Copy(merged, owned)
+
+		err = r.secretBackend().Write(ctx, path, map[string]interface{}{
+			"data":    merged,
+			"options": map[string]interface{}{"cas": version},
+		})
+		if !errors.Is(err, backend.ErrVersionConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("la ruta %s cambió en cada uno de los %d intentos de combinar el valor: %w", path, mergeRetries, err)
+}
 
-	// ** 3. Escritura del Secreto (Real) **
-	log := logf.Log.WithName("VaultWriter").WithValues("path", path)
+// readFromVault lee el documento vigente de una ruta de Vault; nil si no existe.
+func (r *RotationReconciler) readFromVault(ctx context.Context, path string) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	err := r.doVault(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = r.secretBackend().Read(ctx, path)
+		return err
+	})
+	return data, err
+}
+
+// restoreVault vuelve a escribir en una ruta de Vault un documento leído antes de rotarla.
+func (r *RotationReconciler) restoreVault(ctx context.Context, path string, doc map[string]interface{}) error {
+	return r.doVault(ctx, func(ctx context.Context) error {
+		return r.secretBackend().Write(ctx, path, map[string]interface{}{"data": doc})
+	})
+}
 
-	// Simulación de autenticación exitosa:
-	if client.Token() == "" {
-		log.Info("ADVERTENCIA: Usando Vault MOCK. Asumiendo éxito en la escritura.")
+// secretBackend devuelve el almacén de los valores rotados: Backend si se configuró y,
+// si no, las sesiones de Vault del operador, con los fallos de Chaos si los hay.
+func (r *RotationReconciler) secretBackend() backend.SecretBackend {
+	if r.Backend != nil {
+		return chaos.WrapBackend(r.Backend, r.Chaos)
 	}
+	return chaos.WrapBackend(r.vaultSessions(), r.Chaos)
+}
 
-	// Simulación de la estructura de datos que se escribiría en Vault
-	data := map[string]interface{}{
-		"data": map[string]interface{}{
-			"password":   password,
-			"rotated_by": "secret-rotator-operator",
-		},
+// now devuelve la hora de Clock o, si no se configuró, la del sistema.
+func (r *RotationReconciler) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock.Now()
 	}
+	return time.Now()
+}
 
-	// Simulamos la llamada de escritura:
-	_, err = client.Logical().Write(path, data)
+// vaultSessions devuelve las sesiones de Vault del operador: las Rotations bajo un
+// mismo montaje comparten sesión.
+func (r *RotationReconciler) vaultSessions() *vault.Sessions {
+	r.sessionsOnce.Do(func() {
+		cfg := r.VaultConfig
+		// Un certificado referenciado desde un Secret se relee en cada handshake,
+		// así su rotación se aplica sin reiniciar el operador.
+		if r.VaultCertSecret.Name != "" {
+			cfg.ClientCert = &secretCertificateSource{reader: r.Client, key: r.VaultCertSecret}
+		}
+		r.sessions = vault.NewSessions(cfg)
+	})
+	return r.sessions
+}
 
-	// Si hubiera un error real de red o permisos, lo devolveríamos aquí.
+// secretCertificateSource lee el certificado de cliente de un Secret kubernetes.io/tls
+// y lo conserva mientras el Secret no cambie.
+type secretCertificateSource struct {
+	reader client.Reader
+	key    types.NamespacedName
 
-	log.Info("Vault Mock: Escritura simulada exitosa")
-	return nil
+	mu              sync.Mutex
+	cert            *tls.Certificate
+	resourceVersion string
+}
+
+// Certificate devuelve el certificado vigente del Secret.
+func (s *secretCertificateSource) Certificate() (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret := &corev1.Secret{}
+	if err := s.reader.Get(context.Background(), s.key, secret); err != nil {
+		return nil, fmt.Errorf("fallo al leer el Secret del certificado de Vault: %w", err)
+	}
+	if s.cert != nil && secret.ResourceVersion == s.resourceVersion {
+		return s.cert, nil
+	}
+
+	source, err := vault.NewPEMCertificateSource(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	cert, err := source.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	s.cert, s.resourceVersion = cert, secret.ResourceVersion
+	return cert, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RotationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.startedAt = r.now()
+
+	if err := setupRotationIndexes(mgr); err != nil {
+		return err
+	}
+
+	// Las Rotations se encolan solo con enqueueByPriority, sin For: el manejador de For las
+	// encolaría también con prioridad 0, y en la cola gana la mayor, así que las
+	// prioridades negativas se perderían
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&rotationv1alpha1.Rotation{}).
 		Named("rotation").
+		// Las Rotations listas a la vez se atienden por prioridad, que se conserva en las
+		// reprogramaciones y reintentos
+		Watches(&rotationv1alpha1.Rotation{}, r.enqueueByPriority()).
+		WithOptions(controller.Options{UsePriorityQueue: ptr.To(true)}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.rotationsForPod),
+			builder.WithPredicates(podLifecycle())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.rotationsForTargetSecret),
+			builder.WithPredicates(targetSecretChanged())).
+		Watches(&rotationv1alpha1.Rotation{}, handler.EnqueueRequestsFromMapFunc(r.rotationsSharingPath),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&rotationv1alpha1.Rotation{}, handler.EnqueueRequestsFromMapFunc(r.rotationsDependingOn)).
+		Watches(&rotationv1alpha1.RotationFreeze{}, handler.EnqueueRequestsFromMapFunc(r.rotationsDeferredBy)).
+		// El final del Job de un canario reanuda la rotación
+		Watches(&batchv1.Job{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&rotationv1alpha1.Rotation{}, handler.OnlyControllerOwner())).
 		Complete(r)
 }
+
+// setupRotationIndexes registra en la caché del manager los índices que consulta la
+// conciliación de las Rotations.
+func setupRotationIndexes(mgr ctrl.Manager) error {
+	if err := setupConsumerIndexes(context.Background(), mgr); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &rotationv1alpha1.Rotation{}, vaultPathIndex,
+		func(obj client.Object) []string {
+			return rotationPaths(obj.(*rotationv1alpha1.Rotation))
+		}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &rotationv1alpha1.RotationGroup{}, groupMembersIndex,
+		indexGroupMembers); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &rotationv1alpha1.Rotation{}, dependsOnIndex,
+		indexDependsOn); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &rotationv1alpha1.Rotation{}, deferredByIndex,
+		func(obj client.Object) []string {
+			return []string{obj.(*rotationv1alpha1.Rotation).Status.DeferredBy}
+		}); err != nil {
+		return err
+	}
+	return nil
+}
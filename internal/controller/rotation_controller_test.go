@@ -18,16 +18,21 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
 )
 
 var _ = Describe("Rotation Controller", func() {
@@ -51,7 +56,12 @@ var _ = Describe("Rotation Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					// Suspendida: el reconcile valida el recurso sin escribir en Vault.
+					Spec: rotationv1alpha1.RotationSpec{
+						VaultPath:        "secret/data/test-resource",
+						RotationInterval: "24h",
+						Suspend:          true,
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
@@ -82,3 +92,62 @@ var _ = Describe("Rotation Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Rotation priority", func() {
+	It("keeps negative priorities in the controller queue", func() {
+		now := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+		reconciler := &RotationReconciler{Clock: clocktesting.NewFakeClock(now)}
+		queue := priorityqueue.New[reconcile.Request]("rotation-priority-test")
+		defer queue.ShutDown()
+
+		rotation := func(name string, priority int32) *rotationv1alpha1.Rotation {
+			return &rotationv1alpha1.Rotation{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(now)},
+				Spec:       rotationv1alpha1.RotationSpec{Priority: priority},
+			}
+		}
+		enqueue := reconciler.enqueueByPriority()
+		enqueue.Create(context.Background(), event.CreateEvent{Object: rotation("low", -10)}, queue)
+		enqueue.Create(context.Background(), event.CreateEvent{Object: rotation("default", 0)}, queue)
+		// Una actualización vuelve a encolarla con la misma prioridad, no con la de por defecto
+		enqueue.Update(context.Background(), event.UpdateEvent{ObjectOld: rotation("low", -10), ObjectNew: rotation("low", -10)}, queue)
+
+		item, priority, _ := queue.GetWithPriority()
+		Expect(item.Name).To(Equal("default"))
+		Expect(priority).To(Equal(0))
+		item, priority, _ = queue.GetWithPriority()
+		Expect(item.Name).To(Equal("low"))
+		Expect(priority).To(Equal(-10 * overdueLevels))
+		Expect(queue.Len()).To(Equal(0))
+	})
+})
+
+var _ = Describe("Rotated values", func() {
+	It("reads back only the Rotation's keys with writeMode Merge", func() {
+		rotation := &rotationv1alpha1.Rotation{Spec: rotationv1alpha1.RotationSpec{
+			WriteMode: rotationv1alpha1.WriteModeMerge,
+			Password:  &rotationv1alpha1.PasswordSpec{HashOutputs: []string{security.HashSHA512Crypt}},
+		}}
+		doc := map[string]interface{}{
+			rotationv1alpha1.DefaultSecretKey:                                        "nueva",
+			rotationv1alpha1.DefaultSecretKey + rotationv1alpha1.PreviousValueSuffix: "anterior",
+			hashKey(security.HashSHA512Crypt):                                        "$6$hash",
+			"username":                                                               "app",
+			"api_token":                                                              "ajeno",
+		}
+
+		value, err := valueFromVault(rotation, doc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.data).To(Equal(map[string]string{
+			rotationv1alpha1.DefaultSecretKey:                                        "nueva",
+			rotationv1alpha1.DefaultSecretKey + rotationv1alpha1.PreviousValueSuffix: "anterior",
+			hashKey(security.HashSHA512Crypt):                                        "$6$hash",
+		}))
+
+		By("keeping the whole document with writeMode Replace")
+		rotation.Spec.WriteMode = rotationv1alpha1.WriteModeReplace
+		value, err = valueFromVault(rotation, doc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.data).To(HaveKeyWithValue("username", "app"))
+	})
+})
@@ -0,0 +1,1001 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/fake"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/chaos"
+)
+
+// Estas pruebas rotan contra un almacén en memoria y con un reloj falso: el tiempo se
+// adelanta a mano para recorrer intervalos y reintentos sin esperar.
+var _ = Describe("Rotation schedule", Ordered, func() {
+	const interval = time.Hour
+
+	var (
+		mgrCtx    context.Context
+		mgrCancel context.CancelFunc
+		cached    client.Client
+	)
+
+	// La conciliación consulta índices de la caché: se arranca un manager solo con
+	// ellos, sin controladores, y las conciliaciones se invocan a mano.
+	BeforeAll(func() {
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:                 scheme.Scheme,
+			Metrics:                metricsserver.Options{BindAddress: "0"},
+			HealthProbeBindAddress: "0",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(setupRotationIndexes(mgr)).To(Succeed())
+
+		mgrCtx, mgrCancel = context.WithCancel(ctx)
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+		Expect(mgr.GetCache().WaitForCacheSync(mgrCtx)).To(BeTrue())
+		cached = mgr.GetClient()
+	})
+
+	AfterAll(func() {
+		mgrCancel()
+	})
+
+	var (
+		specs      int
+		key        types.NamespacedName
+		store      *fake.Backend
+		fakeClock  *clocktesting.FakeClock
+		reconciler *RotationReconciler
+	)
+
+	BeforeEach(func() {
+		specs++
+		key = types.NamespacedName{Name: fmt.Sprintf("schedule-%d", specs), Namespace: "default"}
+		store = fake.New()
+		fakeClock = clocktesting.NewFakeClock(time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC))
+		reconciler = &RotationReconciler{
+			Client:  cached,
+			Scheme:  cached.Scheme(),
+			Backend: store,
+			Clock:   fakeClock,
+		}
+
+		Expect(k8sClient.Create(ctx, &rotationv1alpha1.Rotation{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: rotationv1alpha1.RotationSpec{
+				VaultPath:        "secret/data/" + key.Name,
+				RotationInterval: interval.String(),
+			},
+		})).To(Succeed())
+		Eventually(func() error {
+			return cached.Get(ctx, key, &rotationv1alpha1.Rotation{})
+		}).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, &rotationv1alpha1.Rotation{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		})).To(Succeed())
+	})
+
+	reconcileRotation := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	// cachedStatus espera a que la caché refleje el estado parcheado, así la siguiente
+	// conciliación parte de él.
+	cachedStatus := func(status string, history int) *rotationv1alpha1.Rotation {
+		rotation := &rotationv1alpha1.Rotation{}
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Status.Status).To(Equal(status))
+			g.Expect(rotation.Status.History).To(HaveLen(history))
+		}).Should(Succeed())
+		return rotation
+	}
+
+	It("rotates once per interval as the clock advances", func() {
+		path := "secret/data/" + key.Name
+
+		By("rotating on the first reconcile")
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval))
+		Expect(store.Writes(path)).To(Equal(1))
+		Expect(store.Get(path)).To(HaveKey(rotationv1alpha1.DefaultSecretKey))
+
+		rotation := cachedStatus("Ready", 1)
+		Expect(rotation.Status.LastRotatedTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(rotation.Status.NextRotationTime.Time).To(BeTemporally("==", fakeClock.Now().Add(interval)))
+		firstFingerprint := rotation.Status.SecretFingerprint
+		Expect(firstFingerprint).NotTo(BeEmpty())
+
+		By("waiting for the rest of the interval before it is due")
+		fakeClock.Step(interval / 4)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval * 3 / 4))
+		Expect(store.Writes(path)).To(Equal(1))
+
+		By("rotating again once the interval has elapsed")
+		fakeClock.Step(interval * 3 / 4)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval))
+		Expect(store.Writes(path)).To(Equal(2))
+
+		rotation = cachedStatus("Ready", 2)
+		Expect(rotation.Status.LastRotatedTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(rotation.Status.SecretFingerprint).NotTo(Equal(firstFingerprint))
+		Expect(rotation.Status.History[0].Trigger).To(Equal("Schedule"))
+	})
+
+	It("retries after 30 seconds while the backend rejects writes", func() {
+		path := "secret/data/" + key.Name
+
+		By("recording the failed attempt")
+		store.FailWrites(errors.New("vault sellado"))
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		Expect(store.Writes(path)).To(BeZero())
+
+		rotation := cachedStatus("ErrorVault", 1)
+		Expect(rotation.Status.LastRotatedTime).To(BeNil())
+		Expect(rotation.Status.History[0].Result).To(Equal("ErrorVault"))
+
+		By("rotating on the retry once the backend recovers")
+		store.FailWrites(nil)
+		fakeClock.Step(result.RequeueAfter)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval))
+		Expect(store.Writes(path)).To(Equal(1))
+
+		rotation = cachedStatus("Ready", 2)
+		Expect(rotation.Status.LastRotatedTime.Time).To(BeTemporally("==", fakeClock.Now()))
+		Expect(rotation.Status.History[1].Result).To(Equal("ErrorVault"))
+	})
+
+	It("opens the Vault circuit after consecutive failures and closes it on a probe", func() {
+		path := "secret/data/" + key.Name
+		reconciler.CircuitThreshold = 2
+		reconciler.CircuitCooldown = time.Minute
+
+		By("opening the circuit on the second consecutive failure")
+		store.FailReads(errors.New("vault sellado"))
+		store.FailWrites(errors.New("vault sellado"))
+		result := reconcileRotation()
+		cachedStatus("ErrorVault", 1)
+		// El reintento falla ya al leer el intento pendiente
+		fakeClock.Step(result.RequeueAfter)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+
+		By("deferring the retry without calling the backend")
+		fakeClock.Step(result.RequeueAfter)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		rotation := cachedStatus(rotationv1alpha1.ConditionBackendCircuitOpen, 1)
+		Expect(meta.IsStatusConditionTrue(rotation.Status.Conditions, rotationv1alpha1.ConditionBackendCircuitOpen)).To(BeTrue())
+
+		By("keeping it open for another cooldown when the half-open probe fails")
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		By("closing it once a probe succeeds")
+		store.FailReads(nil)
+		store.FailWrites(nil)
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		Expect(store.Writes(path)).To(Equal(1))
+		rotation = cachedStatus("Ready", 2)
+		Expect(meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionBackendCircuitOpen)).To(BeNil())
+	})
+
+	It("keeps unrelated keys at the Vault path with writeMode Merge", func() {
+		path := "secret/data/" + key.Name
+		Expect(store.Write(ctx, path, map[string]interface{}{
+			"data": map[string]interface{}{"username": "app", rotationv1alpha1.DefaultSecretKey: "inicial"},
+		})).To(Succeed())
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.WriteMode = rotationv1alpha1.WriteModeMerge
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.WriteMode).To(Equal(rotationv1alpha1.WriteModeMerge))
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		Expect(store.Get(path)).To(HaveKeyWithValue("username", "app"))
+		Expect(store.Get(path)[rotationv1alpha1.DefaultSecretKey]).NotTo(Equal("inicial"))
+		Expect(store.Writes(path)).To(Equal(2))
+	})
+
+	It("applies the status as its own field manager and clears fields kubectl-rotate wrote", func() {
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		patch := client.MergeFrom(rotation.DeepCopy())
+		rotation.Status.PendingApproval = &rotationv1alpha1.PendingApproval{
+			ID:             "stale",
+			RequestedTime:  metav1.NewTime(fakeClock.Now()),
+			ExpirationTime: metav1.NewTime(fakeClock.Now().Add(time.Hour)),
+			ApprovedBy:     "oncall",
+		}
+		Expect(k8sClient.Status().Patch(ctx, rotation, patch, client.FieldOwner(rotationv1alpha1.PluginFieldManager))).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Status.PendingApproval).NotTo(BeNil())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		rotation = cachedStatus("Ready", 1)
+		Expect(rotation.Status.PendingApproval).To(BeNil())
+
+		var managers []string
+		for _, entry := range rotation.ManagedFields {
+			if entry.Subresource == "status" {
+				managers = append(managers, entry.Manager+"/"+string(entry.Operation))
+			}
+		}
+		Expect(managers).To(ConsistOf(rotationv1alpha1.FieldManager + "/Apply"))
+	})
+
+	It("does not apply a stale status over an approval written by kubectl-rotate", func() {
+		stale := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, stale)).To(Succeed())
+
+		rotation := stale.DeepCopy()
+		patch := client.MergeFrom(rotation.DeepCopy())
+		rotation.Status.PendingApproval = &rotationv1alpha1.PendingApproval{
+			ID:             "current",
+			RequestedTime:  metav1.NewTime(fakeClock.Now()),
+			ExpirationTime: metav1.NewTime(fakeClock.Now().Add(time.Hour)),
+			ApprovedBy:     "oncall",
+		}
+		Expect(k8sClient.Status().Patch(ctx, rotation, patch, client.FieldOwner(rotationv1alpha1.PluginFieldManager))).To(Succeed())
+
+		stale.Status.Status = "Ready"
+		err := applyStatus(ctx, k8sClient, stale, &stale.Status)
+		Expect(apierrors.IsConflict(err)).To(BeTrue(), "applyStatus() = %v", err)
+
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		Expect(rotation.Status.PendingApproval).NotTo(BeNil())
+		Expect(rotation.Status.PendingApproval.ApprovedBy).To(Equal("oncall"))
+
+		By("applying it again once read up to date")
+		rotation.Status.Status = "Ready"
+		Expect(applyStatus(ctx, k8sClient, rotation, &rotation.Status)).To(Succeed())
+		Expect(applyStatus(ctx, k8sClient, rotation, &rotation.Status)).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		Expect(rotation.Status.PendingApproval.ApprovedBy).To(Equal("oncall"))
+	})
+
+	It("applies the status of a fresh rotation after recording its attempt", func() {
+		// La anotación del intento cambia la resourceVersion antes de aplicar el estado
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("~", interval, time.Second))
+
+		rotation := cachedStatus("Ready", 1)
+		Expect(rotation.Annotations).To(HaveKey(rotationv1alpha1.AttemptAnnotation))
+		Expect(rotation.Status.LastAttemptID).To(Equal(rotation.Annotations[rotationv1alpha1.AttemptAnnotation]))
+	})
+
+	It("adopts an existing value instead of rotating it with adoptExisting", func() {
+		path := "secret/data/" + key.Name
+		written := fakeClock.Now().Add(-interval / 4)
+		store.Seed(path, map[string]interface{}{rotationv1alpha1.DefaultSecretKey: "heredada"}, written)
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.AdoptExisting = true
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.AdoptExisting).To(BeTrue())
+		}).Should(Succeed())
+
+		By("recording the value as rotated when it was written")
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval * 3 / 4))
+		Expect(store.Writes(path)).To(BeZero())
+		rotation = cachedStatus("Ready", 1)
+		Expect(rotation.Status.LastRotatedTime.Time).To(BeTemporally("==", written))
+		Expect(rotation.Status.SecretFingerprint).NotTo(BeEmpty())
+		Expect(rotation.Status.History[0].Result).To(Equal("Adopted"))
+
+		By("rotating one interval after the adopted value was written")
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		Expect(store.Writes(path)).To(Equal(1))
+		Expect(store.Get(path)[rotationv1alpha1.DefaultSecretKey]).NotTo(Equal("heredada"))
+		cachedStatus("Ready", 2)
+	})
+
+	It("publishes a transit data key generated in Vault with serverSide", func() {
+		path := "secret/data/" + key.Name
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+
+		By("rejecting hash outputs, which need the plaintext")
+		invalid := rotation.DeepCopy()
+		invalid.Spec.ServerSide = &rotationv1alpha1.ServerSideSpec{TransitKey: "app"}
+		invalid.Spec.Password = &rotationv1alpha1.PasswordSpec{HashOutputs: []string{"bcrypt"}}
+		Expect(k8sClient.Update(ctx, invalid)).NotTo(Succeed())
+
+		rotation.Spec.ServerSide = &rotationv1alpha1.ServerSideSpec{TransitKey: "app"}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.ServerSide).NotTo(BeNil())
+		}).Should(Succeed())
+
+		By("storing only the ciphertext")
+		reconcileRotation()
+		Expect(store.Get(path)).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, HavePrefix("vault:v1:")))
+		rotation = cachedStatus("Ready", 1)
+		Expect(rotation.Spec.ServerSide.Bits).To(Equal(256))
+	})
+
+	It("rejects password lengths bcrypt cannot hash", func() {
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+
+		By("rejecting them in the API")
+		invalid := rotation.DeepCopy()
+		invalid.Spec.PasswordLength = 100
+		invalid.Spec.Password = &rotationv1alpha1.PasswordSpec{HashOutputs: []string{"bcrypt"}}
+		Expect(k8sClient.Update(ctx, invalid)).NotTo(Succeed())
+
+		By("refusing to generate them")
+		_, err := reconciler.generateValue(ctx, invalid)
+		Expect(err).To(MatchError(ContainSubstring("bcrypt")))
+
+		invalid.Spec.Password.HashOutputs = []string{"argon2id"}
+		Expect(k8sClient.Update(ctx, invalid)).To(Succeed())
+	})
+
+	It("handles injected backend failures like real ones", func() {
+		reconciler.Chaos = &chaos.Injector{FailureRate: 1}
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		Expect(store.Writes("secret/data/" + key.Name)).To(BeZero())
+		cachedStatus("ErrorVault", 1)
+	})
+
+	It("restores a deleted or edited target Secret from the backend", func() {
+		target := types.NamespacedName{Name: key.Name + "-target", Namespace: key.Namespace}
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Targets = &rotationv1alpha1.RotationTargets{
+			Secrets: []rotationv1alpha1.SecretTarget{{Name: target.Name}},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Targets).NotTo(BeNil())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		current := store.Get("secret/data/" + key.Name)[rotationv1alpha1.DefaultSecretKey]
+
+		expectHealed := func() {
+			secret := &corev1.Secret{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+				g.Expect(string(secret.Data[rotationv1alpha1.DefaultSecretKey])).To(Equal(current))
+			}).Should(Succeed())
+		}
+		expectHealed()
+
+		By("owning the Secret it created")
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(ContainElement(HaveField("UID", rotation.UID)))
+
+		By("recreating the Secret after it is deleted")
+		Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		Eventually(func() bool {
+			return apierrors.IsNotFound(cached.Get(ctx, target, &corev1.Secret{}))
+		}).Should(BeTrue())
+		reconcileRotation()
+		expectHealed()
+
+		By("restoring the value after it is edited")
+		Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+		secret.Data[rotationv1alpha1.DefaultSecretKey] = []byte("tampered")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, target, secret)).To(Succeed())
+			g.Expect(string(secret.Data[rotationv1alpha1.DefaultSecretKey])).To(Equal("tampered"))
+		}).Should(Succeed())
+		reconcileRotation()
+		expectHealed()
+		Expect(store.Writes("secret/data/" + key.Name)).To(Equal(1))
+	})
+
+	It("restores the templated keys and the previous value of a target Secret", func() {
+		path := "secret/data/" + key.Name
+		target := types.NamespacedName{Name: key.Name + "-target", Namespace: key.Namespace}
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.RetainPrevious = true
+		rotation.Spec.Targets = &rotationv1alpha1.RotationTargets{
+			Secrets: []rotationv1alpha1.SecretTarget{{
+				Name:      target.Name,
+				Templates: map[string]string{"dsn": "app:{{ .password }}@db"},
+			}},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Targets).NotTo(BeNil())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		fakeClock.Step(interval)
+		reconcileRotation()
+		cachedStatus("Ready", 2)
+		current, _ := store.Get(path)[rotationv1alpha1.DefaultSecretKey].(string)
+		previous, _ := store.Get(path)["password_previous"].(string)
+		Expect(previous).NotTo(BeEmpty())
+
+		tamper := func(edit func(data map[string][]byte)) {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+			edit(secret.Data)
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+			Eventually(func(g Gomega) {
+				cachedSecret := &corev1.Secret{}
+				g.Expect(cached.Get(ctx, target, cachedSecret)).To(Succeed())
+				g.Expect(cachedSecret.ResourceVersion).To(Equal(secret.ResourceVersion))
+			}).Should(Succeed())
+			reconcileRotation()
+		}
+		expectHealed := func() {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+			Expect(string(secret.Data[rotationv1alpha1.DefaultSecretKey])).To(Equal(current))
+			Expect(string(secret.Data["dsn"])).To(Equal("app:" + current + "@db"))
+			Expect(string(secret.Data[rotationv1alpha1.PreviousSecretKey])).To(Equal(previous))
+		}
+		expectHealed()
+
+		By("restoring a deleted templated key")
+		tamper(func(data map[string][]byte) { delete(data, "dsn") })
+		expectHealed()
+
+		By("restoring an edited templated key")
+		tamper(func(data map[string][]byte) { data["dsn"] = []byte("tampered") })
+		expectHealed()
+
+		By("restoring the previous value")
+		tamper(func(data map[string][]byte) { delete(data, rotationv1alpha1.PreviousSecretKey) })
+		expectHealed()
+		Expect(store.Writes(path)).To(Equal(2))
+	})
+
+	It("destroys the previous versions once the grace period elapses", func() {
+		const grace = 10 * time.Minute
+		path := "secret/data/" + key.Name
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.RevocationGracePeriod = grace.String()
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.RevocationGracePeriod).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("leaving nothing to destroy after the first value")
+		reconcileRotation()
+		rotation = cachedStatus("Ready", 1)
+		Expect(rotation.Status.PendingRevocations).To(BeEmpty())
+
+		By("scheduling the destruction of the replaced value")
+		fakeClock.Step(interval)
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(grace))
+		rotation = cachedStatus("Ready", 2)
+		Expect(rotation.Status.PendingRevocations).To(HaveLen(1))
+		Expect(rotation.Status.PendingRevocations[0].BeforeVersion).To(Equal(2))
+		Expect(store.Version(path, 1)).NotTo(BeNil())
+
+		By("destroying it when the grace period elapses")
+		fakeClock.Step(grace)
+		result = reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(interval - grace))
+		Expect(store.Version(path, 1)).To(BeNil())
+		Expect(store.Version(path, 2)).NotTo(BeNil())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Status.PendingRevocations).To(BeEmpty())
+		}).Should(Succeed())
+	})
+
+	It("removes the retained previous value once the grace period elapses", func() {
+		const grace = 10 * time.Minute
+		path := "secret/data/" + key.Name
+		target := types.NamespacedName{Name: key.Name + "-target", Namespace: key.Namespace}
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.RetainPrevious = true
+		rotation.Spec.RevocationGracePeriod = grace.String()
+		rotation.Spec.Targets = &rotationv1alpha1.RotationTargets{
+			Secrets: []rotationv1alpha1.SecretTarget{{Name: target.Name}},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.RevocationGracePeriod).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		Eventually(func() error {
+			return cached.Get(ctx, target, &corev1.Secret{})
+		}).Should(Succeed())
+		fakeClock.Step(interval)
+		reconcileRotation()
+		cachedStatus("Ready", 2)
+		current := store.Get(path)[rotationv1alpha1.DefaultSecretKey]
+		Expect(store.Get(path)).To(HaveKey("password_previous"))
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			g.Expect(cached.Get(ctx, target, secret)).To(Succeed())
+			g.Expect(secret.Data).To(HaveKey(rotationv1alpha1.PreviousSecretKey))
+		}).Should(Succeed())
+
+		By("dropping it from vaultPath and destroying every version that held it")
+		fakeClock.Step(grace)
+		reconcileRotation()
+		Expect(store.Get(path)).NotTo(HaveKey("password_previous"))
+		Expect(store.Get(path)).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, current))
+		Expect(store.Version(path, 1)).To(BeNil())
+		Expect(store.Version(path, 2)).To(BeNil())
+		Expect(store.Version(path, 3)).NotTo(BeNil())
+
+		By("dropping it from the target Secrets")
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+		Expect(secret.Data).NotTo(HaveKey(rotationv1alpha1.PreviousSecretKey))
+		Expect(string(secret.Data[rotationv1alpha1.DefaultSecretKey])).To(Equal(current))
+	})
+
+	It("keeps the previous value for one cycle with retainPrevious", func() {
+		path := "secret/data/" + key.Name
+		target := types.NamespacedName{Name: key.Name + "-target", Namespace: key.Namespace}
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.RetainPrevious = true
+		rotation.Spec.Targets = &rotationv1alpha1.RotationTargets{
+			Secrets: []rotationv1alpha1.SecretTarget{{Name: target.Name}},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.RetainPrevious).To(BeTrue())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		first := store.Get(path)[rotationv1alpha1.DefaultSecretKey]
+		Expect(store.Get(path)).NotTo(HaveKey("password_previous"))
+		Eventually(func() error {
+			return cached.Get(ctx, target, &corev1.Secret{})
+		}).Should(Succeed())
+
+		fakeClock.Step(interval)
+		reconcileRotation()
+		cachedStatus("Ready", 2)
+		Expect(store.Get(path)).To(HaveKeyWithValue("password_previous", first))
+
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+		Expect(string(secret.Data[rotationv1alpha1.PreviousSecretKey])).To(Equal(first))
+		Expect(string(secret.Data[rotationv1alpha1.DefaultSecretKey])).To(Equal(store.Get(path)[rotationv1alpha1.DefaultSecretKey]))
+	})
+
+	It("composes templated keys in target Secrets", func() {
+		target := types.NamespacedName{Name: key.Name + "-target", Namespace: key.Namespace}
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Targets = &rotationv1alpha1.RotationTargets{
+			Secrets: []rotationv1alpha1.SecretTarget{{
+				Name:      target.Name,
+				Templates: map[string]string{"DATABASE_URL": "postgres://app:{{ .password | urlquery }}@db:5432/app"},
+			}},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Targets).NotTo(BeNil())
+		}).Should(Succeed())
+
+		reconcileRotation()
+		cachedStatus("Ready", 1)
+		password := store.Get("secret/data/" + key.Name)[rotationv1alpha1.DefaultSecretKey].(string)
+
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, target, secret)).To(Succeed())
+		Expect(string(secret.Data["DATABASE_URL"])).To(Equal("postgres://app:" + url.QueryEscape(password) + "@db:5432/app"))
+	})
+
+	It("waits for a consumer Deployment to finish rolling out", func() {
+		path := "secret/data/" + key.Name
+		labels := map[string]string{"app": key.Name}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name + "-app", Namespace: key.Namespace},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, deployment)
+
+		// Sin controlador de Deployments en envtest, el Pod se vincula a mano
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        key.Name + "-app",
+				Namespace:   key.Namespace,
+				Labels:      labels,
+				Annotations: map[string]string{rotationv1alpha1.ConsumesAnnotation: key.Name},
+			},
+			Spec: deployment.Spec.Template.Spec,
+		}
+		Expect(controllerutil.SetControllerReference(deployment, pod, scheme.Scheme)).To(Succeed())
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, pod)
+		Eventually(func() error {
+			return cached.Get(ctx, client.ObjectKeyFromObject(pod), &corev1.Pod{})
+		}).Should(Succeed())
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.WaitForRollout = true
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.WaitForRollout).To(BeTrue())
+		}).Should(Succeed())
+
+		By("deferring the rotation while the Deployment has not rolled out")
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(rolloutRecheck))
+		Expect(store.Writes(path)).To(BeZero())
+		rotation = cachedStatus(rotationv1alpha1.ConditionWaitingForRollout, 0)
+		Expect(meta.IsStatusConditionTrue(rotation.Status.Conditions, rotationv1alpha1.ConditionWaitingForRollout)).To(BeTrue())
+
+		By("rotating once the rollout completes")
+		deployment.Status = appsv1.DeploymentStatus{
+			ObservedGeneration: deployment.Generation,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			ReadyReplicas:      1,
+			AvailableReplicas:  1,
+		}
+		Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+		Eventually(func(g Gomega) {
+			current := &appsv1.Deployment{}
+			g.Expect(cached.Get(ctx, client.ObjectKeyFromObject(deployment), current)).To(Succeed())
+			g.Expect(current.Status.AvailableReplicas).To(BeEquivalentTo(1))
+		}).Should(Succeed())
+		reconcileRotation()
+		Expect(store.Writes(path)).To(Equal(1))
+		rotation = cachedStatus("Ready", 1)
+		Expect(meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionWaitingForRollout)).To(BeNil())
+	})
+
+	It("backs up the current value before overwriting it and prunes old copies", func() {
+		path := "secret/data/" + key.Name
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Backup = &rotationv1alpha1.BackupSpec{Retain: 2}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Backup).NotTo(BeNil())
+		}).Should(Succeed())
+
+		var previous string
+		for i := 1; i <= 4; i++ {
+			previous, _ = store.Get(path)[rotationv1alpha1.DefaultSecretKey].(string)
+			reconcileRotation()
+			cachedStatus("Ready", i)
+			fakeClock.Step(interval)
+		}
+
+		backups, err := store.List(ctx, path+"-backup")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backups).To(HaveLen(2))
+		latest := store.Get(path + "-backup/" + backups[1])
+		Expect(latest).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, previous))
+	})
+
+	It("cuts a hung backend write short with spec.timeout", func() {
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Timeout = "1s"
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Timeout).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("reporting the timeout")
+		store.HangWrites(true)
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		rotation = cachedStatus(statusTimeout, 1)
+		condition := meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionTimedOut)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("ErrorVault"))
+
+		By("clearing it once a retry succeeds")
+		store.HangWrites(false)
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		rotation = cachedStatus("Ready", 2)
+		Expect(meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionTimedOut)).To(BeNil())
+	})
+
+	It("defers a due rotation past excluded weekdays and holidays", func() {
+		path := "secret/data/" + key.Name
+		calendar := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name + "-holidays", Namespace: key.Namespace},
+			Data: map[string]string{
+				rotationv1alpha1.DefaultHolidaysKey: "# Festivos de marzo\n2025-03-03 Día no laborable\n",
+			},
+		}
+		Expect(k8sClient.Create(ctx, calendar)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, calendar)
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Schedule = &rotationv1alpha1.ScheduleSpec{
+			ExcludeDays:     []string{"Saturday", "Sunday"},
+			HolidayCalendar: &rotationv1alpha1.HolidayCalendarReference{Name: calendar.Name},
+		}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Schedule).NotTo(BeNil())
+		}).Should(Succeed())
+
+		By("waiting from Saturday until Tuesday, skipping the weekend and Monday's holiday")
+		tuesday := time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC)
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(tuesday.Sub(fakeClock.Now())))
+		Expect(store.Writes(path)).To(Equal(0))
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Status.NextRotationTime).NotTo(BeNil())
+			g.Expect(rotation.Status.NextRotationTime.Time).To(BeTemporally("==", tuesday))
+		}).Should(Succeed())
+
+		By("rotating on the first allowed day")
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		Expect(store.Writes(path)).To(Equal(1))
+		cachedStatus("Ready", 1)
+	})
+
+	It("writes the value to every path in vaultPaths, all or nothing unless BestEffort", func() {
+		path := "secret/data/" + key.Name
+		staging, dr, prod := "staging/data/"+key.Name, "dr/data/"+key.Name, "prod/data/"+key.Name
+		store.Seed(staging, map[string]interface{}{rotationv1alpha1.DefaultSecretKey: "anterior"}, fakeClock.Now())
+		store.FailPathWrites(prod, errors.New("permiso denegado"))
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.VaultPaths = []string{staging, dr, prod}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.VaultPathsPolicy).To(Equal(rotationv1alpha1.VaultPathsAllOrNothing))
+		}).Should(Succeed())
+
+		By("restoring the paths already written when one fails")
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		Expect(store.Writes(path)).To(BeZero())
+		Expect(store.Get(staging)).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, "anterior"))
+		Expect(store.Get(dr)).To(BeNil())
+		Expect(store.Version(dr, 1)).To(BeNil())
+		rotation = cachedStatus("ErrorVault", 1)
+		Expect(rotation.Status.VaultPaths).To(HaveLen(3))
+		Expect(rotation.Status.VaultPaths[0].Result).To(Equal(rotationv1alpha1.VaultPathRolledBack))
+		Expect(rotation.Status.VaultPaths[1].Result).To(Equal(rotationv1alpha1.VaultPathRolledBack))
+		Expect(rotation.Status.VaultPaths[2].Result).To(Equal(rotationv1alpha1.VaultPathFailed))
+		Expect(rotation.Status.VaultPaths[2].Message).To(ContainSubstring("permiso denegado"))
+
+		By("completing the rotation and reporting the failed path with BestEffort")
+		rotation.Spec.VaultPathsPolicy = rotationv1alpha1.VaultPathsBestEffort
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.VaultPathsPolicy).To(Equal(rotationv1alpha1.VaultPathsBestEffort))
+		}).Should(Succeed())
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		value := store.Get(path)[rotationv1alpha1.DefaultSecretKey]
+		Expect(value).NotTo(BeNil())
+		Expect(store.Get(staging)).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, value))
+		Expect(store.Get(dr)).To(HaveKeyWithValue(rotationv1alpha1.DefaultSecretKey, value))
+		Expect(store.Get(prod)).To(BeNil())
+		rotation = cachedStatus("Ready", 2)
+		Expect(rotation.Status.VaultPaths[0].Result).To(Equal(rotationv1alpha1.VaultPathWritten))
+		Expect(rotation.Status.VaultPaths[1].Result).To(Equal(rotationv1alpha1.VaultPathWritten))
+		Expect(rotation.Status.VaultPaths[2].Result).To(Equal(rotationv1alpha1.VaultPathFailed))
+	})
+
+	It("reports a path conflict with a Rotation that mirrors to its vaultPath", func() {
+		mirror := &rotationv1alpha1.Rotation{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name + "-mirror", Namespace: key.Namespace},
+			Spec: rotationv1alpha1.RotationSpec{
+				VaultPath:        "secret/data/" + key.Name + "-mirror",
+				VaultPaths:       []string{"secret/data/" + key.Name},
+				RotationInterval: interval.String(),
+			},
+		}
+		Expect(k8sClient.Create(ctx, mirror)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, mirror)).To(Succeed())
+		})
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, client.ObjectKeyFromObject(mirror), mirror)).To(Succeed())
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+		}).Should(Succeed())
+
+		By("holding the newer Rotation")
+		blocked, err := reconciler.checkPathConflict(ctx, mirror)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(mirror.Status.Conditions, rotationv1alpha1.ConditionPathConflict)).To(BeTrue())
+
+		By("letting the older one rotate")
+		blocked, err = reconciler.checkPathConflict(ctx, rotation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeFalse())
+		Expect(meta.IsStatusConditionTrue(rotation.Status.Conditions, rotationv1alpha1.ConditionPathConflict)).To(BeTrue())
+	})
+
+	It("promotes a value only after its canary Job completes with strategy Canary", func() {
+		path := "secret/data/" + key.Name
+		staging := path + "-canary"
+		Expect(k8sClient.Create(ctx, &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name + "-canary", Namespace: key.Namespace},
+			Spec: batchv1.CronJobSpec{
+				Schedule: "@yearly",
+				Suspend:  ptr.To(true),
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{{Name: "canary", Image: "busybox"}},
+				}}}},
+			},
+		})).To(Succeed())
+
+		rotation := &rotationv1alpha1.Rotation{}
+		Expect(k8sClient.Get(ctx, key, rotation)).To(Succeed())
+		rotation.Spec.Strategy = rotationv1alpha1.StrategyCanary
+		rotation.Spec.Canary = &rotationv1alpha1.CanarySpec{CronJobName: key.Name + "-canary"}
+		Expect(k8sClient.Update(ctx, rotation)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(cached.Get(ctx, key, rotation)).To(Succeed())
+			g.Expect(rotation.Spec.Canary).NotTo(BeNil())
+		}).Should(Succeed())
+
+		// finishJob marca el Job como terminado, como haría el controlador de Jobs
+		finishJob := func(name string, succeeded bool) {
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: name}, job)).To(Succeed())
+			start := metav1.NewTime(time.Now().Add(-time.Minute))
+			job.Status.StartTime = &start
+			conditions := []batchv1.JobConditionType{batchv1.JobFailureTarget, batchv1.JobFailed}
+			if succeeded {
+				end := metav1.Now()
+				job.Status.CompletionTime = &end
+				job.Status.Succeeded = 1
+				conditions = []batchv1.JobConditionType{batchv1.JobSuccessCriteriaMet, batchv1.JobComplete}
+			} else {
+				job.Status.Failed = 1
+			}
+			for _, conditionType := range conditions {
+				job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+					Type:               conditionType,
+					Status:             corev1.ConditionTrue,
+					Reason:             "Test",
+					LastTransitionTime: metav1.Now(),
+				})
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(cached.Get(ctx, client.ObjectKeyFromObject(job), job)).To(Succeed())
+				g.Expect(job.Status.Conditions).To(HaveLen(2))
+			}).Should(Succeed())
+		}
+
+		By("staging the value and launching the canary Job")
+		result := reconcileRotation()
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		Expect(store.Get(staging)).To(HaveKey(rotationv1alpha1.DefaultSecretKey))
+		Expect(store.Writes(path)).To(BeZero())
+		rotation = cachedStatus("CanaryRunning", 0)
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: rotation.Status.Canary.Job}, job)).To(Succeed())
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ROTATION_STAGING_PATH", Value: staging}))
+		Expect(metav1.IsControlledBy(job, rotation)).To(BeTrue())
+
+		By("aborting the rotation without touching vaultPath when the Job fails")
+		finishJob(job.Name, false)
+		result = reconcileRotation()
+		Expect(store.Writes(path)).To(BeZero())
+		rotation = cachedStatus("ErrorCanary", 1)
+		Expect(rotation.Status.Canary.Phase).To(Equal(rotationv1alpha1.CanaryFailed))
+
+		By("promoting the staged value once a new canary completes")
+		fakeClock.Step(result.RequeueAfter)
+		reconcileRotation()
+		rotation = cachedStatus("CanaryRunning", 1)
+		Expect(rotation.Status.Canary.Job).NotTo(Equal(job.Name))
+		finishJob(rotation.Status.Canary.Job, true)
+		reconcileRotation()
+		Expect(store.Writes(path)).To(Equal(1))
+		Expect(store.Get(path)[rotationv1alpha1.DefaultSecretKey]).To(Equal(store.Get(staging)[rotationv1alpha1.DefaultSecretKey]))
+		rotation = cachedStatus("Ready", 2)
+		Expect(rotation.Status.Canary.Phase).To(Equal(rotationv1alpha1.CanaryPromoted))
+	})
+})
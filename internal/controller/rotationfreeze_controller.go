@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// Fases de un RotationFreeze.
+const (
+	freezeScheduled = "Scheduled"
+	freezeActive    = "Active"
+	freezeEnded     = "Ended"
+)
+
+// RotationFreezeReconciler mantiene el estado de los RotationFreezes: su fase y las
+// rotaciones que aplazaron. Las Rotations y los RotationGroups consultan los freezes
+// activos por sí mismos.
+type RotationFreezeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationfreezes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationfreezes/status,verbs=get;update;patch
+
+// Reconcile actualiza la fase del freeze y registra las rotaciones aplazadas.
+func (r *RotationFreezeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	freeze := &rotationv1alpha1.RotationFreeze{}
+	if err := r.Get(ctx, req.NamespacedName, freeze); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	observed := freeze.DeepCopy()
+
+	var requeue time.Duration
+	now := time.Now()
+	switch {
+	case now.Before(freeze.Spec.Start.Time):
+		freeze.Status.Phase = freezeScheduled
+		requeue = freeze.Spec.Start.Sub(now)
+	case now.Before(freeze.Spec.End.Time):
+		freeze.Status.Phase = freezeActive
+		requeue = freeze.Spec.End.Sub(now)
+	default:
+		freeze.Status.Phase = freezeEnded
+	}
+
+	// Las rotaciones aplazadas se conservan en el estado aunque ya hayan rotado
+	deferred := map[string]bool{}
+	for _, name := range freeze.Status.DeferredRotations {
+		deferred[name] = true
+	}
+	rotations := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, rotations, client.MatchingFields{deferredByIndex: freeze.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, rotation := range rotations.Items {
+		deferred[rotation.Namespace+"/"+rotation.Name] = true
+	}
+	groups := &rotationv1alpha1.RotationGroupList{}
+	if err := r.List(ctx, groups, client.MatchingFields{deferredByIndex: freeze.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, group := range groups.Items {
+		deferred[group.Namespace+"/"+group.Name] = true
+	}
+	names := make([]string, 0, len(deferred))
+	for name := range deferred {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	freeze.Status.DeferredRotations = names
+
+	if !equality.Semantic.DeepEqual(observed.Status, freeze.Status) {
+		if err := applyStatus(ctx, r.Client, freeze, &freeze.Status); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// freezeOf encola el RotationFreeze que aplaza la Rotation o el RotationGroup modificado.
+func freezeOf(_ context.Context, obj client.Object) []reconcile.Request {
+	var freeze string
+	switch o := obj.(type) {
+	case *rotationv1alpha1.Rotation:
+		freeze = o.Status.DeferredBy
+	case *rotationv1alpha1.RotationGroup:
+		freeze = o.Status.DeferredBy
+	}
+	if freeze == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: freeze}}}
+}
+
+// SetupWithManager sets up the controller with the Manager. Los índices por
+// status.deferredBy los registran los controladores de Rotation y RotationGroup.
+func (r *RotationFreezeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rotationv1alpha1.RotationFreeze{}).
+		Watches(&rotationv1alpha1.Rotation{}, handler.EnqueueRequestsFromMapFunc(freezeOf)).
+		Watches(&rotationv1alpha1.RotationGroup{}, handler.EnqueueRequestsFromMapFunc(freezeOf)).
+		Named("rotationfreeze").
+		Complete(r)
+}
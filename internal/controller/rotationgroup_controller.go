@@ -0,0 +1,632 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// RotationGroupReconciler reconciles a RotationGroup object
+type RotationGroupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Rotations aporta la generación de valores, Vault, ejecutores y destinos con la
+	// configuración del operador; el grupo los aplica a cada miembro.
+	Rotations *RotationReconciler
+	// Recorder publica Events sobre los RotationGroups; nil no publica.
+	Recorder record.EventRecorder
+}
+
+// memberRotation es el trabajo de rotación de un miembro del grupo.
+type memberRotation struct {
+	rotation *rotationv1alpha1.Rotation
+	observed *rotationv1alpha1.Rotation
+	// previous es el documento vigente en Vault antes de rotar; nil si no existía.
+	previous map[string]interface{}
+	// paths son los documentos de cada ruta de rotationPaths antes de rotar, para
+	// restablecerlas todas; nil en las que no existían.
+	paths       map[string]map[string]interface{}
+	attempt     string
+	value       *rotatedValue
+	fingerprint string
+	// executed y written indican qué pasos se aplicaron y deben deshacerse si falla otro miembro.
+	executed bool
+	written  bool
+}
+
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationgroups,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationgroups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationfreezes,verbs=get;list;watch
+
+// Reconcile rota los miembros del grupo como una unidad cuando vence su intervalo.
+func (r *RotationGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	group := &rotationv1alpha1.RotationGroup{}
+	if err := r.Get(ctx, req.NamespacedName, group); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	observed := group.DeepCopy()
+
+	interval, err := time.ParseDuration(group.Spec.RotationInterval)
+	if err != nil {
+		return ctrl.Result{}, r.markInvalidSpec(ctx, group, observed,
+			fmt.Sprintf("rotationInterval %q no es una duración válida: %v", group.Spec.RotationInterval, err))
+	}
+
+	if group.Spec.Suspend {
+		log.V(1).Info("RotationGroup suspendido")
+		return ctrl.Result{}, nil
+	}
+
+	rotateNow := group.Annotations[rotationv1alpha1.RotateNowAnnotation]
+	manualRequest := rotateNow != "" && rotateNow != group.Status.LastRotateNowRequest
+	trigger := "Schedule"
+	if manualRequest {
+		trigger = "Manual"
+	}
+
+	// El grupo solo rota si ha vencido, está en su ventana y ningún freeze lo aplaza
+	if result, held, err := r.holdGroup(ctx, group, observed, interval, trigger, manualRequest); held || err != nil {
+		return result, err
+	}
+
+	members, err := r.members(ctx, group)
+	if errors.Is(err, errInvalidMember) {
+		// Un cambio en el spec del grupo o del miembro vuelve a encolarlo
+		return ctrl.Result{}, r.markInvalidSpec(ctx, group, observed, err.Error())
+	}
+	if err != nil {
+		log.Error(err, "Miembros del RotationGroup no válidos")
+		return r.fail(ctx, group, trigger, "ErrorMiembro", err)
+	}
+
+	// Ningún miembro rota mientras lo retendría su rotación individual
+	if result, held, err := r.holdMembers(ctx, group, observed, members, trigger, manualRequest); held || err != nil {
+		return result, err
+	}
+
+	// Con el circuito de Vault abierto el grupo espera a la próxima prueba del backend.
+	// Los miembros comparten el namespace del grupo, así que el freeze de holdGroup y este
+	// circuito son los mismos que retendrían a cada uno por separado
+	if open := r.Rotations.circuits.allow("vault", r.Rotations.CircuitThreshold, r.Rotations.circuitCooldown(), r.Rotations.now()); open > 0 {
+		log.Info("Circuito de Vault abierto, aplazando el grupo", "espera", open)
+		group.Status.Status = rotationv1alpha1.ConditionBackendCircuitOpen
+		if !equality.Semantic.DeepEqual(observed.Status, group.Status) {
+			if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: open}, nil
+	}
+
+	// Cada miembro cuenta en la cuota de rotaciones del namespace
+	if throttled := r.Rotations.quotas.reserve(group.Namespace, len(members), r.Rotations.NamespaceQuota, r.Rotations.now()); throttled > 0 {
+		log.Info("Cuota de rotaciones del namespace agotada, aplazando el grupo", "espera", throttled)
+		group.Status.Status = statusThrottled
+		if !equality.Semantic.DeepEqual(observed.Status, group.Status) {
+			if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: throttled}, nil
+	}
+
+	// Se bloquean todas las rutas en orden para no cruzarse con otros grupos ni Rotations
+	var paths []string
+	for _, m := range members {
+		paths = append(paths, rotationPaths(m.rotation)...)
+	}
+	unlock := r.Rotations.pathLocks.LockAll(paths...)
+	defer unlock()
+
+	// El plazo del grupo es el más largo de sus miembros, y limita los mismos pasos que
+	// spec.timeout en una rotación individual; el restablecimiento y el estado usan ctx
+	timeout := groupTimeout(members)
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log.Info("Iniciando rotación del grupo", "trigger", trigger, "miembros", len(members))
+	if err := r.rotateMembers(ctx, opCtx, members); err != nil {
+		log.Error(err, "Rotación del grupo fallida; miembros restablecidos")
+		status := "RolledBack"
+		switch {
+		case errors.Is(err, errRollback):
+			status = "ErrorRollback"
+		case errors.Is(opCtx.Err(), context.DeadlineExceeded):
+			status = statusTimeout
+			err = fmt.Errorf("la rotación del grupo superó su plazo de %s: %w", timeout, err)
+		}
+		return r.fail(ctx, group, trigger, status, err)
+	}
+
+	// A partir de aquí Vault ya contiene los valores nuevos de todos los miembros
+	now := metav1.NewTime(r.Rotations.now())
+	next := now.Add(interval)
+	var publishErrs []error
+	for _, m := range members {
+		memberNext := metav1.NewTime(nextRotationAfter(now.Time, interval, m.value))
+		if memberNext.Time.Before(next) {
+			next = memberNext.Time
+		}
+		if err := r.publish(ctx, opCtx, m, now, memberNext, trigger); err != nil {
+			publishErrs = append(publishErrs, err)
+		}
+	}
+
+	group.Status.LastRotatedTime = &now
+	nextTime := metav1.NewTime(next)
+	group.Status.NextRotationTime = &nextTime
+	group.Status.Status = "Ready"
+	if manualRequest {
+		group.Status.LastRotateNowRequest = rotateNow
+	}
+	condition := metav1.Condition{
+		Type:               rotationv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Rotated",
+		Message:            fmt.Sprintf("%d Rotations rotadas juntas", len(members)),
+		ObservedGeneration: group.Generation,
+	}
+	if err := errors.Join(publishErrs...); err != nil {
+		// Los valores ya son válidos: no se deshace la rotación, solo se informa
+		log.Error(err, "Fallo al publicar los valores rotados del grupo")
+		group.Status.Status = "ErrorSync"
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "PublishFailed"
+		condition.Message = err.Error()
+		r.event(group, corev1.EventTypeWarning, "PublishFailed", err.Error())
+	}
+	meta.SetStatusCondition(&group.Status.Conditions, condition)
+	recordGroupHistory(group, rotationv1alpha1.RotationHistoryEntry{Time: now, Trigger: trigger, Result: group.Status.Status})
+	if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: next.Sub(r.Rotations.now())}, nil
+}
+
+// holdGroup retiene un grupo que no ha vencido, está fuera de su ventana de mantenimiento
+// o está aplazado por un RotationFreeze; en ese caso deja el estado parcheado y devuelve
+// el resultado de la conciliación con held a true.
+func (r *RotationGroupReconciler) holdGroup(ctx context.Context, group, observed *rotationv1alpha1.RotationGroup,
+	interval time.Duration, trigger string, manual bool) (ctrl.Result, bool, error) {
+	log := logf.FromContext(ctx)
+
+	// Un grupo vencido espera además a que se abra su ventana de mantenimiento
+	if !manual {
+		var wait time.Duration
+		if group.Status.LastRotatedTime != nil {
+			next := group.Status.LastRotatedTime.Add(interval)
+			if group.Status.NextRotationTime != nil {
+				next = group.Status.NextRotationTime.Time
+			}
+			wait = next.Sub(r.Rotations.now())
+		}
+		if wait <= 0 && group.Spec.MaintenanceWindow != nil {
+			var err error
+			if wait, err = windowDelay(group.Spec.MaintenanceWindow, r.Rotations.now()); err != nil {
+				return ctrl.Result{}, true, r.markInvalidSpec(ctx, group, observed, err.Error())
+			}
+			if wait > 0 {
+				group.Status.Status = "WaitingForWindow"
+			}
+		}
+		if wait > 0 {
+			if !equality.Semantic.DeepEqual(observed.Status, group.Status) {
+				if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+					return ctrl.Result{}, true, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: wait}, true, nil
+		}
+	}
+
+	// Durante un RotationFreeze el grupo vencido se aplaza hasta que termine
+	freeze, err := activeFreeze(ctx, r, group.Namespace, r.Rotations.now())
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if freeze != nil {
+		message := fmt.Sprintf("Rotación aplazada por el RotationFreeze %s hasta %s", freeze.Name, freeze.Spec.End.UTC().Format(time.RFC3339))
+		if group.Status.DeferredBy != freeze.Name {
+			r.event(group, corev1.EventTypeNormal, "RotationDeferred", message)
+			recordGroupHistory(group, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.Rotations.now()), Trigger: trigger, Result: "Deferred"})
+		}
+		log.Info("Rotación del grupo aplazada por un RotationFreeze", "freeze", freeze.Name)
+		group.Status.DeferredBy = freeze.Name
+		group.Status.Status = rotationv1alpha1.ConditionFrozen
+		if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+			return ctrl.Result{}, true, err
+		}
+		return ctrl.Result{RequeueAfter: freeze.Spec.End.Sub(r.Rotations.now())}, true, nil
+	}
+	group.Status.DeferredBy = ""
+
+	return ctrl.Result{}, false, nil
+}
+
+// holdMembers retiene el grupo mientras alguno de sus miembros no pudiera rotar por
+// separado: con spec.waitForRollout, un despliegue en curso de sus consumidores; con
+// spec.dependsOn, una dependencia de fuera del grupo sin rotar; con
+// spec.approvalRequired, una solicitud sin aprobar. Cada miembro registra en su estado lo
+// que lo retiene, como una rotación individual, y el grupo rota cuando ninguno lo hace.
+func (r *RotationGroupReconciler) holdMembers(ctx context.Context, group, observed *rotationv1alpha1.RotationGroup,
+	members []*memberRotation, trigger string, manual bool) (ctrl.Result, bool, error) {
+	log := logf.FromContext(ctx)
+
+	status, wait, err := r.checkMembers(ctx, members, trigger, manual)
+	if errors.Is(err, errInvalidMember) {
+		return ctrl.Result{}, true, r.markInvalidSpec(ctx, group, observed, err.Error())
+	}
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	for _, m := range members {
+		if err := r.Rotations.patchStatus(ctx, m.rotation, m.observed); err != nil {
+			return ctrl.Result{}, true, err
+		}
+		m.observed = m.rotation.DeepCopy()
+	}
+	if status == "" {
+		return ctrl.Result{}, false, nil
+	}
+
+	// Cada aprobación de un miembro y cada rotación de una dependencia vuelven a encolar
+	// el grupo; los despliegues se comprueban de nuevo al vencer la espera
+	log.Info("Rotación del grupo retenida por sus miembros", "estado", status, "espera", wait)
+	group.Status.Status = status
+	if !equality.Semantic.DeepEqual(observed.Status, group.Status) {
+		if err := applyStatus(ctx, r.Client, group, &group.Status); err != nil {
+			return ctrl.Result{}, true, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: wait}, true, nil
+}
+
+// checkMembers aplica a los miembros, en el orden de holdRotation, las comprobaciones que
+// retendrían su rotación individual. Devuelve el estado con el que se retiene el grupo,
+// vacío si pueden rotar, y cuánto esperar antes de comprobarlo de nuevo.
+func (r *RotationGroupReconciler) checkMembers(ctx context.Context, members []*memberRotation,
+	trigger string, manual bool) (string, time.Duration, error) {
+	// No se cambia la credencial bajo Pods que aún arrancan con la anterior
+	var wait time.Duration
+	for _, m := range members {
+		rollout, err := r.Rotations.checkRollout(ctx, m.rotation, manual)
+		if err != nil {
+			return "", 0, fmt.Errorf("miembro %q: %w", m.rotation.Name, err)
+		}
+		wait = max(wait, rollout)
+	}
+	if wait > 0 {
+		return rotationv1alpha1.ConditionWaitingForRollout, wait, nil
+	}
+
+	// Las dependencias de fuera del grupo rotan antes; las de dentro rotan con él
+	together := make([]string, 0, len(members))
+	for _, m := range members {
+		together = append(together, m.rotation.Name)
+	}
+	blocked := false
+	for _, m := range members {
+		waiting, err := r.Rotations.checkDependencies(ctx, m.rotation, manual, together)
+		if err != nil {
+			return "", 0, fmt.Errorf("miembro %q: %w", m.rotation.Name, err)
+		}
+		if waiting != "" {
+			m.rotation.Status.Status = rotationv1alpha1.ConditionBlocked
+			blocked = true
+		}
+	}
+	if blocked {
+		return rotationv1alpha1.ConditionBlocked, 0, nil
+	}
+
+	// Las solicitudes de aprobación se abren a la vez y el grupo espera a que todas se aprueben
+	for _, m := range members {
+		approvalWait, err := r.Rotations.checkApproval(m.rotation, trigger, r.Rotations.now())
+		if err != nil {
+			return "", 0, fmt.Errorf("%w: miembro %q: %v", errInvalidMember, m.rotation.Name, err)
+		}
+		if approvalWait > 0 && (wait == 0 || approvalWait < wait) {
+			wait = approvalWait
+		}
+	}
+	if wait > 0 {
+		return rotationv1alpha1.ConditionPendingApproval, wait, nil
+	}
+	return "", 0, nil
+}
+
+// groupTimeout devuelve el plazo de la rotación de un grupo: el más largo de los de sus
+// miembros.
+func groupTimeout(members []*memberRotation) time.Duration {
+	var timeout time.Duration
+	for _, m := range members {
+		timeout = max(timeout, rotationTimeout(m.rotation))
+	}
+	return timeout
+}
+
+// errRollback marca los fallos en los que algún miembro no pudo restablecerse.
+var errRollback = errors.New("fallo al restablecer los miembros ya rotados")
+
+// errInvalidMember marca los miembros cuyo spec no permite rotarlos con el grupo.
+var errInvalidMember = errors.New("miembro no válido")
+
+// members obtiene las Rotations del grupo y comprueba que puedan rotarse juntas.
+func (r *RotationGroupReconciler) members(ctx context.Context, group *rotationv1alpha1.RotationGroup) ([]*memberRotation, error) {
+	members := make([]*memberRotation, 0, len(group.Spec.Rotations))
+	for _, name := range group.Spec.Rotations {
+		rotation := &rotationv1alpha1.Rotation{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: group.Namespace, Name: name}, rotation); err != nil {
+			return nil, fmt.Errorf("fallo al obtener la Rotation %q: %w", name, err)
+		}
+		if rotation.Spec.Suspend {
+			return nil, fmt.Errorf("la Rotation %q está suspendida", name)
+		}
+		groups, err := groupsOf(ctx, r, rotation)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range groups {
+			if other != group.Name {
+				return nil, fmt.Errorf("la Rotation %q pertenece también al RotationGroup %q", name, other)
+			}
+		}
+		// El grupo escribe el valor directamente en vaultPath: no hay canario que lo pruebe antes
+		if rotation.Spec.Strategy == rotationv1alpha1.StrategyCanary {
+			return nil, fmt.Errorf("%w: la Rotation %q usa la estrategia Canary, que un RotationGroup no ejecuta", errInvalidMember, name)
+		}
+		if err := r.Rotations.authorizeTargets(ctx, rotation); err != nil {
+			return nil, fmt.Errorf("miembro %q: %w", name, err)
+		}
+		members = append(members, &memberRotation{rotation: rotation, observed: rotation.DeepCopy()})
+	}
+	return members, nil
+}
+
+// rotateMembers genera los valores de todos los miembros y los aplica uno a uno con el
+// plazo de opCtx. Si algún paso falla o vence el plazo, los miembros ya cambiados
+// recuperan su valor anterior con ctx.
+func (r *RotationGroupReconciler) rotateMembers(ctx, opCtx context.Context, members []*memberRotation) error {
+	rotations := r.Rotations
+
+	// Preparación: nada se ha cambiado todavía si falla
+	for _, m := range members {
+		m.paths = map[string]map[string]interface{}{}
+		for _, path := range rotationPaths(m.rotation) {
+			doc, err := rotations.readFromVault(opCtx, path)
+			if err != nil {
+				return fmt.Errorf("miembro %q: %w", m.rotation.Name, err)
+			}
+			m.paths[path] = doc
+		}
+		m.previous = m.paths[m.rotation.Spec.VaultPath]
+		var err error
+		if err = rotations.writeBackup(opCtx, m.rotation, m.previous); err != nil {
+			return fmt.Errorf("miembro %q: %w", m.rotation.Name, err)
+		}
+		if m.value, err = rotations.generateValue(opCtx, m.rotation); err != nil {
+			return fmt.Errorf("miembro %q: fallo al generar el valor: %w", m.rotation.Name, err)
+		}
+		if m.fingerprint, err = security.Fingerprint(m.value.value()); err != nil {
+			return err
+		}
+		// Cada miembro escribe con su propio intento, como una rotación individual
+		if m.attempt, err = rotations.startAttempt(opCtx, m.rotation); err != nil {
+			return fmt.Errorf("miembro %q: fallo al registrar el intento: %w", m.rotation.Name, err)
+		}
+	}
+
+	for _, m := range members {
+		// El valor anterior ya se leyó en la preparación y es el que restablece rollback
+		_, err := rotations.runExecutor(opCtx, m.rotation, m.value.value())
+		if err == nil {
+			// Una escritura fallida (o cortada por el plazo) puede haber llegado a alguna
+			// ruta: se restablecen todas
+			m.executed, m.written = true, true
+			err = rotations.writeToVault(opCtx, m.rotation, m.value, m.attempt)
+		}
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("miembro %q: %w", m.rotation.Name, err)
+		if rollbackErr := r.rollback(ctx, members); rollbackErr != nil {
+			return fmt.Errorf("%w: %w; %w", errRollback, err, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// rollback restablece, en orden inverso, el valor anterior de los miembros ya cambiados:
+// en el sistema del ejecutor y en vaultPath y cada ruta de spec.vaultPaths. Una ruta que
+// no existía antes de rotar se borra, para que el valor nuevo no quede publicado.
+func (r *RotationGroupReconciler) rollback(ctx context.Context, members []*memberRotation) error {
+	var errs []error
+	for i := len(members) - 1; i >= 0; i-- {
+		m := members[i]
+		previous, _ := m.previous[valueKey(m.rotation)].(string)
+
+		if m.executed {
+			if err := r.Rotations.restoreExecutor(ctx, m.rotation, previous, m.value.value()); err != nil {
+				errs = append(errs, fmt.Errorf("miembro %q: %w", m.rotation.Name, err))
+			}
+		}
+		if !m.written {
+			continue
+		}
+		for _, path := range rotationPaths(m.rotation) {
+			if err := r.Rotations.restoreVaultPath(ctx, path, m.paths[path]); err != nil {
+				errs = append(errs, fmt.Errorf("miembro %q: fallo al restablecer %s: %w", m.rotation.Name, path, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publish escribe el valor de un miembro en sus destinos y Secrets con el plazo de opCtx
+// y registra la rotación en su estado, igual que una rotación individual.
+func (r *RotationGroupReconciler) publish(ctx, opCtx context.Context, m *memberRotation, now, next metav1.Time, trigger string) error {
+	rotations := r.Rotations
+	rotation := m.rotation
+
+	var errs []error
+	if err := rotations.writeDestinations(opCtx, rotation, m.value.value()); err != nil {
+		errs = append(errs, fmt.Errorf("miembro %q: %w", rotation.Name, err))
+	}
+	if err := rotations.syncTargets(opCtx, rotation, m.value); err != nil {
+		errs = append(errs, fmt.Errorf("miembro %q: %w", rotation.Name, err))
+	}
+
+	recordRotated(rotation, m.value, m.fingerprint, now, next)
+	rotation.Status.LastAttemptID = m.attempt
+	// La aprobación vale solo para esta rotación
+	rotation.Status.PendingApproval = nil
+	rotations.scheduleRevocation(ctx, rotation, m.value, now.Time)
+	recordHistory(rotation, rotationv1alpha1.RotationHistoryEntry{
+		Time:        now,
+		Trigger:     trigger,
+		Result:      rotation.Status.Status,
+		Fingerprint: m.fingerprint,
+	})
+	if err := rotations.patchStatus(ctx, rotation, m.observed); err != nil {
+		errs = append(errs, fmt.Errorf("miembro %q: %w", rotation.Name, err))
+	}
+
+	rotations.sendNotifications(ctx, rotation)
+	rotations.runPostRotation(ctx, rotation)
+	return errors.Join(errs...)
+}
+
+// fail registra un intento fallido del grupo y lo reintenta más tarde.
+func (r *RotationGroupReconciler) fail(ctx context.Context, group *rotationv1alpha1.RotationGroup,
+	trigger, status string, cause error) (ctrl.Result, error) {
+	group.Status.Status = status
+	meta.SetStatusCondition(&group.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             status,
+		Message:            cause.Error(),
+		ObservedGeneration: group.Generation,
+	})
+	r.event(group, corev1.EventTypeWarning, status, cause.Error())
+	recordGroupHistory(group, rotationv1alpha1.RotationHistoryEntry{Time: metav1.NewTime(r.Rotations.now()), Trigger: trigger, Result: status})
+	_ = applyStatus(ctx, r.Client, group, &group.Status)
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil // Reintentar en 30 segundos
+}
+
+// markInvalidSpec marca el grupo como Ready=False/InvalidSpec; no se reintenta hasta
+// que cambie el spec.
+func (r *RotationGroupReconciler) markInvalidSpec(ctx context.Context, group, observed *rotationv1alpha1.RotationGroup, message string) error {
+	group.Status.Status = rotationv1alpha1.ReasonInvalidSpec
+	meta.SetStatusCondition(&group.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             rotationv1alpha1.ReasonInvalidSpec,
+		Message:            message,
+		ObservedGeneration: group.Generation,
+	})
+	if equality.Semantic.DeepEqual(observed.Status, group.Status) {
+		return nil
+	}
+	r.event(group, corev1.EventTypeWarning, rotationv1alpha1.ReasonInvalidSpec, message)
+	return applyStatus(ctx, r.Client, group, &group.Status)
+}
+
+func (r *RotationGroupReconciler) event(group *rotationv1alpha1.RotationGroup, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(group, eventType, reason, message)
+	}
+}
+
+// recordGroupHistory añade un intento al historial del grupo conservando solo los más recientes.
+func recordGroupHistory(group *rotationv1alpha1.RotationGroup, entry rotationv1alpha1.RotationHistoryEntry) {
+	history := append([]rotationv1alpha1.RotationHistoryEntry{entry}, group.Status.History...)
+	if len(history) > rotationv1alpha1.MaxHistoryEntries {
+		history = history[:rotationv1alpha1.MaxHistoryEntries]
+	}
+	group.Status.History = history
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RotationGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &rotationv1alpha1.RotationGroup{}, deferredByIndex,
+		func(obj client.Object) []string {
+			return []string{obj.(*rotationv1alpha1.RotationGroup).Status.DeferredBy}
+		}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rotationv1alpha1.RotationGroup{}).
+		Watches(&rotationv1alpha1.RotationFreeze{}, handler.EnqueueRequestsFromMapFunc(r.groupsDeferredBy)).
+		Watches(&rotationv1alpha1.Rotation{}, handler.EnqueueRequestsFromMapFunc(r.groupsOfMember)).
+		Named("rotationgroup").
+		Complete(r)
+}
+
+// groupsOfMember encola los RotationGroups de la Rotation modificada y de las Rotations
+// que dependen de ella: una aprobación, un cambio de su spec o su rotación pueden
+// desbloquear al grupo. Un grupo que no ha vencido vuelve a esperar sin escribir nada.
+func (r *RotationGroupReconciler) groupsOfMember(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+	rotations := []rotationv1alpha1.Rotation{*obj.(*rotationv1alpha1.Rotation)}
+	dependents := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, dependents, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{dependsOnIndex: obj.GetName()}); err != nil {
+		log.Error(err, "Fallo al buscar las Rotations dependientes", "rotation", obj.GetName())
+	}
+	rotations = append(rotations, dependents.Items...)
+
+	var requests []reconcile.Request
+	for i := range rotations {
+		names, err := groupsOf(ctx, r, &rotations[i])
+		if err != nil {
+			log.Error(err, "Fallo al buscar los RotationGroups de la Rotation", "rotation", rotations[i].Name)
+			continue
+		}
+		for _, name := range names {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name},
+			})
+		}
+	}
+	return requests
+}
+
+// groupsDeferredBy encola los RotationGroups aplazados por el RotationFreeze modificado.
+func (r *RotationGroupReconciler) groupsDeferredBy(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &rotationv1alpha1.RotationGroupList{}
+	if err := r.List(ctx, list, client.MatchingFields{deferredByIndex: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al buscar los RotationGroups aplazados", "freeze", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, group := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: group.Namespace, Name: group.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// defaultReportRefresh es la frecuencia de recálculo de un informe si el spec no la fija.
+const defaultReportRefresh = 5 * time.Minute
+
+// RotationReportReconciler mantiene el resumen de todas las Rotations del clúster en el
+// estado de cada RotationReport.
+type RotationReportReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationreports,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=rotation.security.io,resources=rotationreports/status,verbs=get;update;patch
+
+// Reconcile recalcula el informe a partir de las Rotations del clúster.
+func (r *RotationReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	report := &rotationv1alpha1.RotationReport{}
+	if err := r.Get(ctx, req.NamespacedName, report); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	refresh := defaultReportRefresh
+	if report.Spec.RefreshInterval != "" {
+		if interval, err := time.ParseDuration(report.Spec.RefreshInterval); err == nil {
+			refresh = interval
+		}
+	}
+
+	rotations := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, rotations); err != nil {
+		return ctrl.Result{}, err
+	}
+	now := metav1.Now()
+	report.Status = summarize(rotations.Items, now)
+
+	if err := applyStatus(ctx, r.Client, report, &report.Status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: refresh}, nil
+}
+
+// summarize calcula el informe de las Rotations en now. Una Rotation vencida o fallida
+// se evalúa igual que en el comando due del plugin de kubectl.
+func summarize(rotations []rotationv1alpha1.Rotation, now metav1.Time) rotationv1alpha1.RotationReportStatus {
+	status := rotationv1alpha1.RotationReportStatus{
+		LastUpdateTime: &now,
+		Rotations:      int32(len(rotations)),
+	}
+
+	type overdueRotation struct {
+		name string
+		next time.Time
+	}
+	var overdue []overdueRotation
+	failing := map[string][]string{}
+	for i := range rotations {
+		rotation := &rotations[i]
+		name := rotation.Namespace + "/" + rotation.Name
+
+		if rotation.Status.Status == "Ready" {
+			status.Ready++
+		}
+		if rotation.Spec.Suspend {
+			status.Suspended++
+		} else if next, ok := reportNextRotation(rotation); ok && next.Before(now.Time) {
+			overdue = append(overdue, overdueRotation{name: name, next: next})
+		}
+		if strings.HasPrefix(rotation.Status.Status, "Error") {
+			failing[rotation.Namespace] = append(failing[rotation.Namespace], rotation.Name)
+			status.Failing++
+		}
+
+		if last := rotation.Status.LastRotatedTime; last != nil &&
+			(status.OldestSecret == nil || last.Before(&status.OldestSecret.LastRotatedTime)) {
+			status.OldestSecret = &rotationv1alpha1.OldestSecret{Rotation: name, LastRotatedTime: *last}
+		}
+	}
+	if status.OldestSecret != nil {
+		status.OldestSecret.Age = now.Sub(status.OldestSecret.LastRotatedTime.Time).Round(time.Second).String()
+	}
+
+	status.Overdue = int32(len(overdue))
+	sort.Slice(overdue, func(i, j int) bool { return overdue[i].next.Before(overdue[j].next) })
+	for i := 0; i < len(overdue) && i < rotationv1alpha1.MaxReportEntries; i++ {
+		status.OverdueRotations = append(status.OverdueRotations, overdue[i].name)
+	}
+
+	namespaces := make([]string, 0, len(failing))
+	for namespace := range failing {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		names := failing[namespace]
+		sort.Strings(names)
+		entry := rotationv1alpha1.NamespaceFailures{Namespace: namespace, Count: int32(len(names)), Rotations: names}
+		if len(names) > rotationv1alpha1.MaxReportEntries {
+			entry.Rotations = names[:rotationv1alpha1.MaxReportEntries]
+		}
+		status.FailingByNamespace = append(status.FailingByNamespace, entry)
+	}
+	return status
+}
+
+// reportNextRotation devuelve la próxima rotación prevista; false si nunca rotó o el
+// intervalo no es válido.
+func reportNextRotation(rotation *rotationv1alpha1.Rotation) (time.Time, bool) {
+	if rotation.Status.LastRotatedTime == nil {
+		return time.Time{}, false
+	}
+	interval, err := time.ParseDuration(rotation.Spec.RotationInterval)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return nextRotation(rotation, interval), true
+}
+
+// allReports encola todos los RotationReports cuando cambia una Rotation.
+func (r *RotationReportReconciler) allReports(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := &rotationv1alpha1.RotationReportList{}
+	if err := r.List(ctx, list); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al listar los RotationReports")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, report := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: report.Name}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RotationReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// El propio parche de estado no vuelve a encolar el informe
+		For(&rotationv1alpha1.RotationReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&rotationv1alpha1.Rotation{}, handler.EnqueueRequestsFromMapFunc(r.allReports)).
+		Named("rotationreport").
+		Complete(r)
+}
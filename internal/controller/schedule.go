@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// DefaultStartupSpread es la ventana de arranque por defecto para repartir rotaciones vencidas.
+const DefaultStartupSpread = 5 * time.Minute
+
+// nextRotation devuelve la próxima rotación prevista: status.nextRotationTime si existe
+// o, en su defecto, la última rotación más el intervalo.
+func nextRotation(rotation *rotationv1alpha1.Rotation, interval time.Duration) time.Time {
+	if rotation.Status.NextRotationTime != nil {
+		return rotation.Status.NextRotationTime.Time
+	}
+	return rotation.Status.LastRotatedTime.Add(interval)
+}
+
+// dueDelay devuelve cuánto debe esperar una rotación ya vencida antes de empezar: hasta
+// el siguiente día permitido por spec.schedule, que pasa a ser su próxima rotación, o
+// hasta su turno en el arranque escalonado.
+func (r *RotationReconciler) dueDelay(ctx context.Context, rotation *rotationv1alpha1.Rotation) (time.Duration, error) {
+	log := logf.FromContext(ctx)
+
+	wait, err := r.excludedDayDelay(ctx, rotation)
+	if err != nil {
+		return 0, err
+	}
+	if wait > 0 {
+		next := metav1.NewTime(r.now().Add(wait))
+		rotation.Status.NextRotationTime = &next
+		log.Info("Rotación vencida aplazada al siguiente día permitido", "próximaRotación", next.Time)
+		return wait, nil
+	}
+
+	if wait = r.startupDelay(ctx, rotation); wait > 0 {
+		log.V(1).Info("Rotación vencida aplazada por el arranque escalonado", "espera", wait)
+	}
+	return wait, nil
+}
+
+// excludedDayDelay devuelve cuánto falta, si hoy (en UTC) es un día excluido por
+// spec.schedule, para el comienzo del siguiente día permitido; cero si hoy lo es.
+func (r *RotationReconciler) excludedDayDelay(ctx context.Context, rotation *rotationv1alpha1.Rotation) (time.Duration, error) {
+	schedule := rotation.Spec.Schedule
+	if schedule == nil {
+		return 0, nil
+	}
+	holidays, err := r.holidays(ctx, rotation)
+	if err != nil {
+		return 0, err
+	}
+	excluded := map[string]bool{}
+	for _, day := range schedule.ExcludeDays {
+		excluded[day] = true
+	}
+
+	now := r.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// Un año basta para saltar cualquier combinación de días de la semana y festivos
+	for range 366 {
+		if !excluded[day.Weekday().String()] && !holidays[day.Format(time.DateOnly)] {
+			return max(day.Sub(now), 0), nil
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return 0, fmt.Errorf("spec.schedule no permite ningún día del próximo año")
+}
+
+// holidays devuelve los festivos del calendario de spec.schedule.holidayCalendar: una
+// fecha (AAAA-MM-DD) por línea, opcionalmente seguida de una descripción. Las líneas
+// vacías y las que empiezan por # se ignoran.
+func (r *RotationReconciler) holidays(ctx context.Context, rotation *rotationv1alpha1.Rotation) (map[string]bool, error) {
+	ref := rotation.Spec.Schedule.HolidayCalendar
+	if ref == nil {
+		return nil, nil
+	}
+	key := ref.Key
+	if key == "" {
+		key = rotationv1alpha1.DefaultHolidaysKey
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rotation.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("fallo al leer el calendario de festivos %q: %w", ref.Name, err)
+	}
+	holidays := map[string]bool{}
+	for _, line := range strings.Split(cm.Data[key], "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, fields[0]); err != nil {
+			return nil, fmt.Errorf("fecha no válida en el calendario de festivos %q: %q", ref.Name, fields[0])
+		}
+		holidays[fields[0]] = true
+	}
+	return holidays, nil
+}
+
+// startupDelay devuelve cuánto debe esperar una rotación vencida para no coincidir con
+// el resto al arrancar el operador. Las Rotations vencidas al arrancar se reparten en
+// StartupSpread por prioridad (startupOrder); el resto recibe un desfase estable
+// calculado a partir de su nombre, así los reintentos no lo alteran.
+func (r *RotationReconciler) startupDelay(ctx context.Context, rotation *rotationv1alpha1.Rotation) time.Duration {
+	if r.StartupSpread <= 0 || r.startedAt.IsZero() || !r.now().Before(r.startedAt.Add(r.StartupSpread)) {
+		return 0
+	}
+
+	r.startupOnce.Do(func() { r.startupOffsets = r.startupOrder(ctx) })
+	offset, ordered := r.startupOffsets[rotation.Namespace+"/"+rotation.Name]
+	if !ordered {
+		h := fnv.New64a()
+		h.Write([]byte(rotation.Namespace + "/" + rotation.Name))
+		offset = time.Duration(h.Sum64() % uint64(r.StartupSpread))
+	}
+
+	return r.startedAt.Add(offset).Sub(r.now())
+}
+
+// windowDelay devuelve cuánto falta para que se abra la ventana de mantenimiento diaria;
+// cero si now ya está dentro de ella.
+func windowDelay(window *rotationv1alpha1.MaintenanceWindow, now time.Time) (time.Duration, error) {
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return 0, fmt.Errorf("inicio de ventana %q no válido: %w", window.Start, err)
+	}
+	length, err := time.ParseDuration(window.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("duración de ventana %q no válida: %w", window.Duration, err)
+	}
+
+	now = now.UTC()
+	opens := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+	// La ventana abierta ayer puede seguir vigente si cruza la medianoche
+	if yesterday := opens.AddDate(0, 0, -1); now.Before(yesterday.Add(length)) {
+		return 0, nil
+	}
+	if !now.Before(opens) && now.Before(opens.Add(length)) {
+		return 0, nil
+	}
+	if now.Before(opens) {
+		return opens.Sub(now), nil
+	}
+	return opens.AddDate(0, 0, 1).Sub(now), nil
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// SecretReconciler materializa una Rotation gestionada para cada Secret anotado con
+// rotation.security.io/interval, de modo que los equipos puedan incorporar Secrets
+// existentes a la rotación sin escribir YAML nuevo.
+type SecretReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile crea, actualiza o elimina la Rotation gestionada de un Secret.
+func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		// Si el Secret se borró, el garbage collector elimina su Rotation (ownerReference)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	rotation := &rotationv1alpha1.Rotation{}
+	err := r.Get(ctx, req.NamespacedName, rotation)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	exists := err == nil
+
+	// Nunca tocamos una Rotation con el mismo nombre creada a mano
+	if exists && rotation.Labels[rotationv1alpha1.ManagedByLabel] != rotationv1alpha1.ManagedByValue {
+		log.Info("Ya existe una Rotation no gestionada con el mismo nombre, se ignora la anotación")
+		return ctrl.Result{}, nil
+	}
+
+	interval, annotated := secret.Annotations[rotationv1alpha1.IntervalAnnotation]
+	if !annotated {
+		// Se retiró la anotación: la Rotation gestionada deja de tener sentido
+		if exists {
+			log.Info("Anotación de rotación retirada, eliminando la Rotation gestionada")
+			return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, rotation))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	spec, err := rotationSpecFromSecret(secret, interval)
+	if err != nil {
+		log.Error(err, "Anotaciones de rotación no válidas")
+		return ctrl.Result{}, nil
+	}
+
+	// Con server-side apply solo se declaran los campos derivados del Secret: los que el
+	// usuario cambie en la Rotation (p. ej. suspend) se conservan sin copiarlos
+	if err := r.applyManagedRotation(ctx, secret, spec); err != nil {
+		return ctrl.Result{}, fmt.Errorf("fallo al materializar la Rotation: %w", err)
+	}
+	log.V(1).Info("Rotation gestionada aplicada desde el Secret")
+
+	return ctrl.Result{}, nil
+}
+
+// applyManagedRotation declara con server-side apply, como FieldManager, la Rotation
+// gestionada del Secret: la etiqueta ManagedByLabel, su ownerReference y spec.
+func (r *SecretReconciler) applyManagedRotation(ctx context.Context, secret *corev1.Secret, spec rotationv1alpha1.RotationSpec) error {
+	rotation := &rotationv1alpha1.Rotation{ObjectMeta: metav1.ObjectMeta{Name: secret.Name, Namespace: secret.Namespace}}
+	if err := controllerutil.SetControllerReference(secret, rotation, r.Scheme); err != nil {
+		return err
+	}
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return err
+	}
+
+	applied, err := applyObject(r.Client, rotation)
+	if err != nil {
+		return err
+	}
+	applied.SetLabels(map[string]string{rotationv1alpha1.ManagedByLabel: rotationv1alpha1.ManagedByValue})
+	applied.SetOwnerReferences(rotation.OwnerReferences)
+	applied.Object["spec"] = fields
+	return r.Apply(ctx, client.ApplyConfigurationFromUnstructured(applied),
+		client.FieldOwner(rotationv1alpha1.FieldManager), client.ForceOwnership)
+}
+
+// rotationSpecFromSecret traduce las anotaciones del Secret a la spec de su Rotation.
+func rotationSpecFromSecret(secret *corev1.Secret, interval string) (rotationv1alpha1.RotationSpec, error) {
+	spec := rotationv1alpha1.RotationSpec{
+		RotationInterval: interval,
+		VaultPath:        fmt.Sprintf("secret/data/%s/%s", secret.Namespace, secret.Name),
+		PasswordLength:   security.DefaultPasswordLength,
+		IncludeSymbols:   security.DefaultIncludeSymbols,
+	}
+	if path := secret.Annotations[rotationv1alpha1.VaultPathAnnotation]; path != "" {
+		spec.VaultPath = path
+	}
+	if length := secret.Annotations[rotationv1alpha1.PasswordLengthAnnotation]; length != "" {
+		n, err := strconv.Atoi(length)
+		if err != nil {
+			return spec, fmt.Errorf("longitud de contraseña no válida %q: %w", length, err)
+		}
+		spec.PasswordLength = n
+	}
+
+	key := secret.Annotations[rotationv1alpha1.KeyAnnotation]
+	if key == "" {
+		key = rotationv1alpha1.DefaultSecretKey
+	}
+	spec.Targets = &rotationv1alpha1.RotationTargets{
+		Secrets: []rotationv1alpha1.SecretTarget{{Name: secret.Name, Key: key}},
+	}
+	return spec, nil
+}
+
+// annotatedSecret filtra los eventos de Secrets que tienen (o tenían) la anotación de intervalo.
+func annotatedSecret() predicate.Predicate {
+	has := func(obj client.Object) bool {
+		_, ok := obj.GetAnnotations()[rotationv1alpha1.IntervalAnnotation]
+		return ok
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return has(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return has(e.ObjectOld) || has(e.ObjectNew) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return has(e.Object) },
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Secret{}, builder.WithPredicates(annotatedSecret())).
+		Owns(&rotationv1alpha1.Rotation{}).
+		Named("secret").
+		Complete(r)
+}
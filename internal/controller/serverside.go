@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend"
+)
+
+// Valores por defecto de spec.serverSide.
+const (
+	defaultTransitMount = "transit"
+	defaultDataKeyBits  = 256
+)
+
+// serverSideValue genera el valor de una Rotation con spec.serverSide en el motor
+// transit de Vault. Solo se recibe el texto cifrado de la clave de datos: es lo que se
+// publica y se toma la huella, y el texto en claro no pasa nunca por el operador.
+func (r *RotationReconciler) serverSideValue(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	var store backend.SecretBackend = r.Backend
+	if store == nil {
+		store = r.vaultSessions()
+	}
+	generator, ok := store.(backend.DataKeyBackend)
+	if !ok {
+		return nil, errors.New("el backend no genera claves en el servidor, necesario con spec.serverSide")
+	}
+
+	spec := rotation.Spec.ServerSide
+	mount := spec.Mount
+	if mount == "" {
+		mount = defaultTransitMount
+	}
+	bits := spec.Bits
+	if bits == 0 {
+		bits = defaultDataKeyBits
+	}
+
+	var ciphertext string
+	err := r.doVault(ctx, func(ctx context.Context) error {
+		var err error
+		ciphertext, err = generator.GenerateDataKey(ctx, mount, spec.TransitKey, bits)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	key := valueKey(rotation)
+	return &rotatedValue{key: key, data: map[string]string{key: ciphertext}}, nil
+}
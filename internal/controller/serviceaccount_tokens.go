@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// defaultTokenExpirationSeconds es la vida solicitada para un token si el spec no la fija.
+const defaultTokenExpirationSeconds int64 = 3600
+
+// mintServiceAccountToken solicita un token ligado a la ServiceAccount con la API
+// TokenRequest. El valor caduca en el momento indicado por el API server, que puede
+// ser anterior al solicitado.
+func (r *RotationReconciler) mintServiceAccountToken(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	spec := rotation.Spec.ServiceAccountToken
+	if spec == nil {
+		return nil, fmt.Errorf("el tipo %s requiere spec.serviceAccountToken", rotationv1alpha1.TypeServiceAccountToken)
+	}
+	expirationSeconds := spec.ExpirationSeconds
+	if expirationSeconds == 0 {
+		expirationSeconds = defaultTokenExpirationSeconds
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:      spec.ServiceAccountName,
+		Namespace: rotation.Namespace,
+	}}
+	request := &authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{
+		Audiences:         spec.Audiences,
+		ExpirationSeconds: &expirationSeconds,
+	}}
+	if err := r.SubResource("token").Create(ctx, serviceAccount, request); err != nil {
+		return nil, fmt.Errorf("fallo al solicitar un token para la ServiceAccount %q: %w", spec.ServiceAccountName, err)
+	}
+
+	expires := request.Status.ExpirationTimestamp.Time
+	return &rotatedValue{
+		key:     rotationv1alpha1.TokenSecretKey,
+		data:    map[string]string{rotationv1alpha1.TokenSecretKey: request.Status.Token},
+		expires: &expires,
+	}, nil
+}
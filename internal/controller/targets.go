@@ -0,0 +1,327 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// syncTargets escribe el valor rotado en los Secrets de destino, creándolos si no existen,
+// y después en sus copias de los clústeres remotos.
+func (r *RotationReconciler) syncTargets(ctx context.Context, rotation *rotationv1alpha1.Rotation, value *rotatedValue) error {
+	if rotation.Spec.Targets == nil {
+		return nil
+	}
+	for _, target := range rotation.Spec.Targets.Secrets {
+		if err := r.syncSecret(ctx, rotation, target, value); err != nil {
+			return err
+		}
+	}
+	return r.syncRemoteClusters(ctx, rotation, value)
+}
+
+func (r *RotationReconciler) syncSecret(ctx context.Context, rotation *rotationv1alpha1.Rotation, target rotationv1alpha1.SecretTarget, value *rotatedValue) error {
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = rotation.Namespace
+	}
+	key := target.Key
+	if key == "" {
+		key = valueKey(rotation)
+	}
+	password := value.value()
+	// Las plantillas se resuelven antes de tocar el Secret: una plantilla errónea no
+	// deja el valor nuevo a medio publicar
+	rendered, err := renderTemplates(target.Templates, value)
+	if err != nil {
+		return fmt.Errorf("fallo al componer el Secret %s/%s: %w", namespace, target.Name, err)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: target.Name, Namespace: namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		// Solo etiquetamos los Secrets que crea el operador, no los existentes
+		if secret.CreationTimestamp.IsZero() {
+			secret.Labels = map[string]string{rotationv1alpha1.ManagedByLabel: rotationv1alpha1.ManagedByValue}
+			// El tipo es inmutable: solo se fija al crear el Secret
+			if rotation.Spec.Type == rotationv1alpha1.TypeDockerConfigJSON {
+				secret.Type = corev1.SecretTypeDockerConfigJson
+			}
+		}
+		// Los Secrets creados por el operador (también los anteriores a este cambio) se
+		// borran con la Rotation; los existentes nunca
+		if secret.Labels[rotationv1alpha1.ManagedByLabel] == rotationv1alpha1.ManagedByValue {
+			if err := r.setSecretOwner(rotation, secret); err != nil {
+				return err
+			}
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		// Solo se conserva como anterior el valor rotado vigente (el de la huella del estado),
+		// no uno editado a mano ni el que ya se escribió al retomar un intento; en esos casos,
+		// y al restablecer un Secret, el anterior es el que conserva el backend
+		if current := string(secret.Data[key]); rotation.Spec.RetainPrevious {
+			if current != password && security.MatchFingerprint(rotation.Status.SecretFingerprint, current) {
+				secret.Data[rotationv1alpha1.PreviousSecretKey] = []byte(current)
+			} else if previous := value.previous(); previous != "" {
+				secret.Data[rotationv1alpha1.PreviousSecretKey] = []byte(previous)
+			} else {
+				delete(secret.Data, rotationv1alpha1.PreviousSecretKey)
+			}
+		}
+		secret.Data[key] = []byte(password)
+		maps.Copy(secret.Data, rendered)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fallo al sincronizar el Secret %s/%s: %w", namespace, target.Name, err)
+	}
+	return nil
+}
+
+// renderTemplates compone las claves adicionales de un Secret de destino a partir de los
+// valores rotados. Una clave ausente en los valores es un error, no un texto vacío.
+func renderTemplates(templates map[string]string, value *rotatedValue) (map[string][]byte, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	rendered := make(map[string][]byte, len(templates))
+	for key, text := range templates {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("plantilla de la clave %q no válida: %w", key, err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, value.data); err != nil {
+			return nil, fmt.Errorf("fallo al componer la clave %q: %w", key, err)
+		}
+		rendered[key] = out.Bytes()
+	}
+	return rendered, nil
+}
+
+// setSecretOwner vincula un Secret creado por el operador a la Rotation: con una
+// ownerReference en su mismo namespace y, en otro namespace, con OwnerUIDLabel para
+// que lo borre CleanupFinalizer. Varias Rotations pueden compartir un mismo Secret:
+// las ownerReferences se acumulan y el recolector lo borra con la última.
+func (r *RotationReconciler) setSecretOwner(rotation *rotationv1alpha1.Rotation, secret *corev1.Secret) error {
+	if secret.Namespace == rotation.Namespace {
+		return controllerutil.SetOwnerReference(rotation, secret, r.Scheme)
+	}
+	if _, owned := secret.Labels[rotationv1alpha1.OwnerUIDLabel]; !owned {
+		secret.Labels[rotationv1alpha1.OwnerUIDLabel] = string(rotation.UID)
+	}
+	return nil
+}
+
+// ensureCleanupFinalizer añade CleanupFinalizer a las Rotations con Secrets de destino en
+// otros namespaces. Una vez añadido se conserva hasta el borrado, aunque cambien los
+// destinos, para no dejar atrás los Secrets ya creados.
+func (r *RotationReconciler) ensureCleanupFinalizer(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if rotation.Spec.Targets == nil || controllerutil.ContainsFinalizer(rotation, rotationv1alpha1.CleanupFinalizer) {
+		return nil
+	}
+	for _, target := range rotation.Spec.Targets.Secrets {
+		if target.Namespace == "" || target.Namespace == rotation.Namespace {
+			continue
+		}
+		original := rotation.DeepCopy()
+		controllerutil.AddFinalizer(rotation, rotationv1alpha1.CleanupFinalizer)
+		return r.Patch(ctx, rotation, client.MergeFrom(original))
+	}
+	return nil
+}
+
+// cleanupTargets borra, al eliminar la Rotation, los Secrets que creó en otros
+// namespaces y retira CleanupFinalizer. Los de su namespace los borra el recolector
+// de basura por su ownerReference.
+func (r *RotationReconciler) cleanupTargets(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if !controllerutil.ContainsFinalizer(rotation, rotationv1alpha1.CleanupFinalizer) {
+		return nil
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.MatchingLabels{rotationv1alpha1.OwnerUIDLabel: string(rotation.UID)}); err != nil {
+		return fmt.Errorf("fallo al buscar los Secrets creados por la Rotation: %w", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("fallo al borrar el Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		logf.FromContext(ctx).Info("Secret de destino borrado con la Rotation", "secret", secret.Namespace+"/"+secret.Name)
+	}
+
+	original := rotation.DeepCopy()
+	controllerutil.RemoveFinalizer(rotation, rotationv1alpha1.CleanupFinalizer)
+	return r.Patch(ctx, rotation, client.MergeFrom(original))
+}
+
+// healTargets restablece, sin esperar a la próxima rotación, los Secrets de destino que
+// se borraron o cuyo valor, claves compuestas por plantilla o valor anterior ya no
+// coinciden con el vigente. Solo lee el backend si algún destino puede haberse desviado.
+func (r *RotationReconciler) healTargets(ctx context.Context, rotation *rotationv1alpha1.Rotation) error {
+	if rotation.Spec.Targets == nil || rotation.Status.SecretFingerprint == "" {
+		return nil
+	}
+
+	// Una rotación en curso sobre la ruta ya escribirá sus destinos
+	unlock := r.pathLocks.Lock(rotation.Spec.VaultPath)
+	defer unlock()
+
+	var (
+		value  *rotatedValue
+		loaded bool
+	)
+	// El valor vigente se lee una sola vez, y solo si hace falta para comparar o restablecer
+	current := func() (*rotatedValue, error) {
+		if loaded {
+			return value, nil
+		}
+		doc, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+		if err != nil {
+			return nil, fmt.Errorf("fallo al leer el valor vigente: %w", err)
+		}
+		if value, err = valueFromVault(rotation, doc); err != nil {
+			return nil, err
+		}
+		loaded = true
+		return value, nil
+	}
+
+	var drifted []rotationv1alpha1.SecretTarget
+	for _, target := range rotation.Spec.Targets.Secrets {
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = rotation.Namespace
+		}
+		key := target.Key
+		if key == "" {
+			key = valueKey(rotation)
+		}
+
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: target.Name}, secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("fallo al leer el Secret %s/%s: %w", namespace, target.Name, err)
+		}
+		if err != nil || !security.MatchFingerprint(rotation.Status.SecretFingerprint, string(secret.Data[key])) {
+			drifted = append(drifted, target)
+			continue
+		}
+		// Las claves compuestas y el valor anterior no tienen huella: se comparan con el backend
+		if len(target.Templates) == 0 && !rotation.Spec.RetainPrevious {
+			continue
+		}
+		v, err := current()
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			continue
+		}
+		drift, err := secretDrifted(rotation, target, secret, v)
+		if err != nil {
+			return err
+		}
+		if drift {
+			drifted = append(drifted, target)
+		}
+	}
+	if len(drifted) == 0 {
+		return nil
+	}
+
+	v, err := current()
+	if err != nil || v == nil {
+		// Sin valor en el backend no hay nada que restablecer
+		return err
+	}
+	// Los destinos de otros namespaces se restablecen con la misma autorización que al rotar
+	if err := r.authorizeTargets(ctx, rotation); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(drifted))
+	for _, target := range drifted {
+		if err := r.syncSecret(ctx, rotation, target, v); err != nil {
+			return err
+		}
+		names = append(names, target.Name)
+	}
+	logf.FromContext(ctx).Info("Secrets de destino restablecidos desde el backend", "secrets", names)
+	r.event(rotation, corev1.EventTypeWarning, "TargetHealed",
+		"Secrets de destino borrados o modificados restablecidos: "+strings.Join(names, ", "))
+	return nil
+}
+
+// secretDrifted indica si las claves compuestas por plantilla o el valor anterior de un
+// Secret de destino, cuyo valor principal ya coincide con el vigente, faltan o difieren
+// de los que publicaría syncSecret.
+func secretDrifted(rotation *rotationv1alpha1.Rotation, target rotationv1alpha1.SecretTarget, secret *corev1.Secret, value *rotatedValue) (bool, error) {
+	rendered, err := renderTemplates(target.Templates, value)
+	if err != nil {
+		return false, fmt.Errorf("fallo al componer el Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	for key, want := range rendered {
+		if got, ok := secret.Data[key]; !ok || !bytes.Equal(got, want) {
+			return true, nil
+		}
+	}
+	if !rotation.Spec.RetainPrevious {
+		return false, nil
+	}
+	old, ok := secret.Data[rotationv1alpha1.PreviousSecretKey]
+	if previous := value.previous(); previous != "" {
+		return !ok || string(old) != previous, nil
+	}
+	return ok, nil
+}
+
+// rotationsForTargetSecret encola las Rotations que sincronizan el Secret modificado.
+func (r *RotationReconciler) rotationsForTargetSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &rotationv1alpha1.RotationList{}
+	if err := r.List(ctx, list, client.MatchingFields{rotationTargetSecretsIndex: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Fallo al buscar Rotations por Secret de destino", "secret", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, rotation := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rotation.Namespace, Name: rotation.Name}})
+	}
+	return requests
+}
+
+// targetSecretChanged filtra los eventos de Secrets que pueden haber desviado un destino:
+// borrados y cambios de datos. Las creaciones las hace el propio operador.
+func targetSecretChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			previous, ok := e.ObjectOld.(*corev1.Secret)
+			current, ok2 := e.ObjectNew.(*corev1.Secret)
+			return !ok || !ok2 || !equality.Semantic.DeepEqual(previous.Data, current.Data)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
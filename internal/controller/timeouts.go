@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// defaultRotationTimeout es el plazo de una rotación si el spec no lo fija.
+const defaultRotationTimeout = 10 * time.Minute
+
+// statusTimeout es el estado de un intento cortado por spec.timeout.
+const statusTimeout = "ErrorTimeout"
+
+// rotationTimeout devuelve el plazo de los pasos externos de una rotación: generación,
+// ejecutor y su verificación, y escrituras en Vault, destinos y Secrets.
+func rotationTimeout(rotation *rotationv1alpha1.Rotation) time.Duration {
+	if timeout, err := time.ParseDuration(rotation.Spec.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultRotationTimeout
+}
+
+// failedStatus devuelve el estado de un intento fallido: statusTimeout, con la condición
+// TimedOut, si lo cortó el plazo de opCtx, o status si falló por otra causa.
+func (r *RotationReconciler) failedStatus(opCtx context.Context, rotation *rotationv1alpha1.Rotation, status string) string {
+	if !errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+		meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionTimedOut)
+		return status
+	}
+
+	message := fmt.Sprintf("La rotación superó su plazo de %s en el paso %s", rotationTimeout(rotation), status)
+	rotationTimeouts.WithLabelValues(rotation.Namespace, rotation.Name).Inc()
+	r.event(rotation, corev1.EventTypeWarning, "RotationTimedOut", message)
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionTimedOut,
+		Status:             metav1.ConditionTrue,
+		Reason:             status,
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return statusTimeout
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// markInvalidSpec marca la Rotation como Ready=False/InvalidSpec y publica un Event.
+// Solo se notifica una vez por generación: los reconciles provocados por el propio
+// parche de estado no repiten el Event.
+func (r *RotationReconciler) markInvalidSpec(ctx context.Context, rotation, observed *rotationv1alpha1.Rotation, message string) error {
+	ready := meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionReady)
+	if ready != nil && ready.Reason == rotationv1alpha1.ReasonInvalidSpec && ready.ObservedGeneration == rotation.Generation {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("Spec no válido, la Rotation no se procesará hasta que cambie", "motivo", message)
+	if r.Recorder != nil {
+		r.Recorder.Event(rotation, corev1.EventTypeWarning, rotationv1alpha1.ReasonInvalidSpec, message)
+	}
+
+	rotation.Status.Status = rotationv1alpha1.ReasonInvalidSpec
+	meta.SetStatusCondition(&rotation.Status.Conditions, metav1.Condition{
+		Type:               rotationv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             rotationv1alpha1.ReasonInvalidSpec,
+		Message:            message,
+		ObservedGeneration: rotation.Generation,
+	})
+	return r.patchStatus(ctx, rotation, observed)
+}
+
+// clearInvalidSpec retira la marca InvalidSpec una vez corregido el spec.
+func clearInvalidSpec(rotation *rotationv1alpha1.Rotation) {
+	ready := meta.FindStatusCondition(rotation.Status.Conditions, rotationv1alpha1.ConditionReady)
+	if ready == nil || ready.Reason != rotationv1alpha1.ReasonInvalidSpec {
+		return
+	}
+	meta.RemoveStatusCondition(&rotation.Status.Conditions, rotationv1alpha1.ConditionReady)
+	if rotation.Status.Status == rotationv1alpha1.ReasonInvalidSpec {
+		rotation.Status.Status = ""
+	}
+}
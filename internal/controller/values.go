@@ -0,0 +1,405 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/backend/vault"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/openpgp"
+	"github.com/AndreCbrera/secret-rotator-operator/internal/security"
+)
+
+// expiresKey es la clave del documento de Vault con el vencimiento (RFC 3339) de un
+// valor que caduca.
+const expiresKey = "expires_at"
+
+// publicKeyKey es la clave del documento de Vault con la parte pública del valor, que
+// también se expone en el estado.
+const publicKeyKey = "publicKey"
+
+// keyFingerprintKey es la clave del documento de Vault con la huella de una clave
+// OpenPGP, que también se expone en el estado.
+const keyFingerprintKey = "fingerprint"
+
+// activeKeyIDKey es la clave del documento de Vault con el identificador de la clave
+// activa de un anillo de claves, que también se registra en el estado.
+const activeKeyIDKey = "activeKeyId"
+
+// defaultRetainedKeys es el número de claves anteriores que conserva un anillo de claves.
+const defaultRetainedKeys = 2
+
+// renewFraction es la parte de la vida de un valor que caduca tras la cual se renueva.
+const renewFraction = 0.8
+
+// rotatedValue es una credencial generada: el valor principal y los datos que se
+// publican con él en Vault.
+type rotatedValue struct {
+	// key es la clave del valor principal en Vault y, por defecto, en los Secrets de destino.
+	key string
+	// data son los valores publicados en Vault, incluido el principal.
+	data map[string]string
+	// expires es el vencimiento del valor si caduca por sí solo.
+	expires *time.Time
+}
+
+// value devuelve el valor principal: el que reciben ejecutores, destinos y Secrets.
+func (v *rotatedValue) value() string {
+	return v.data[v.key]
+}
+
+// previous devuelve el valor anterior que se conserva junto al principal; vacío si no lo hay.
+func (v *rotatedValue) previous() string {
+	return v.data[v.key+rotationv1alpha1.PreviousValueSuffix]
+}
+
+// vaultData devuelve los valores que se escriben en Vault, incluido el vencimiento.
+func (v *rotatedValue) vaultData() map[string]string {
+	data := make(map[string]string, len(v.data)+1)
+	for key, value := range v.data {
+		data[key] = value
+	}
+	if v.expires != nil {
+		data[expiresKey] = v.expires.UTC().Format(time.RFC3339)
+	}
+	return data
+}
+
+// valueKey devuelve la clave del valor principal según el tipo de la Rotation.
+func valueKey(rotation *rotationv1alpha1.Rotation) string {
+	switch rotation.Spec.Type {
+	case rotationv1alpha1.TypeServiceAccountToken:
+		return rotationv1alpha1.TokenSecretKey
+	case rotationv1alpha1.TypeDockerConfigJSON:
+		return rotationv1alpha1.DockerConfigSecretKey
+	case rotationv1alpha1.TypeHtpasswd:
+		return rotationv1alpha1.HtpasswdSecretKey
+	case rotationv1alpha1.TypeTOTP:
+		return rotationv1alpha1.TOTPSeedKey
+	case rotationv1alpha1.TypeWireGuard, rotationv1alpha1.TypeOpenPGP:
+		return rotationv1alpha1.PrivateKeyKey
+	case rotationv1alpha1.TypeDataEncryptionKey:
+		return rotationv1alpha1.KeyRingKey
+	}
+	return rotationv1alpha1.DefaultSecretKey
+}
+
+// generateValue genera una credencial nueva del tipo de la Rotation. Con
+// spec.retainPrevious conserva el valor vigente junto a ella; se lee antes de generar
+// para no dejar una credencial emitida sin publicar si la lectura falla.
+func (r *RotationReconciler) generateValue(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	// El valor anterior se conserva para los consumidores o para revocarlo al vencer el
+	// periodo de gracia
+	var previous string
+	if rotation.Spec.RetainPrevious || revokesAtSource(rotation) {
+		doc, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+		if err != nil {
+			return nil, fmt.Errorf("fallo al leer el valor anterior: %w", err)
+		}
+		previous, _ = doc[valueKey(rotation)].(string)
+	}
+
+	v, err := r.newValue(ctx, rotation)
+	if err == nil && previous != "" {
+		v.data[v.key+rotationv1alpha1.PreviousValueSuffix] = previous
+	}
+	return v, err
+}
+
+// newValue genera una credencial nueva del tipo de la Rotation.
+func (r *RotationReconciler) newValue(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	// Con spec.serverSide el valor se genera en Vault y solo se maneja su texto cifrado
+	if rotation.Spec.ServerSide != nil {
+		return r.serverSideValue(ctx, rotation)
+	}
+
+	switch rotation.Spec.Type {
+	case rotationv1alpha1.TypeServiceAccountToken:
+		return r.mintServiceAccountToken(ctx, rotation)
+	case rotationv1alpha1.TypeDockerConfigJSON:
+		return r.rotateRegistryCredential(ctx, rotation)
+	case rotationv1alpha1.TypeTOTP:
+		return totpValue(rotation)
+	case rotationv1alpha1.TypeWireGuard:
+		return wireGuardValue(rotation)
+	case rotationv1alpha1.TypeOpenPGP:
+		return r.openPGPValue(rotation)
+	case rotationv1alpha1.TypeDataEncryptionKey:
+		return r.dataEncryptionKeyValue(ctx, rotation)
+	}
+
+	passwordLength := rotation.Spec.PasswordLength
+	if passwordLength == 0 {
+		passwordLength = security.DefaultPasswordLength // Usar valor por defecto si no se especifica
+	}
+	// bcrypt rechaza contraseñas más largas en lugar de truncarlas
+	if passwordLength > security.BcryptMaxLength && usesBcrypt(rotation) {
+		return nil, fmt.Errorf("passwordLength %d supera los %d caracteres que admite bcrypt", passwordLength, security.BcryptMaxLength)
+	}
+	password, err := security.GeneratePassword(passwordLength, rotation.Spec.IncludeSymbols)
+	if err != nil {
+		return nil, err
+	}
+	var v *rotatedValue
+	if rotation.Spec.Type == rotationv1alpha1.TypeHtpasswd {
+		if v, err = htpasswdValue(rotation, password); err != nil {
+			return nil, err
+		}
+	} else {
+		key := valueKey(rotation)
+		v = &rotatedValue{key: key, data: map[string]string{key: password}}
+	}
+
+	// Los hashes se calculan de la misma contraseña para que cliente y servidor coincidan
+	if rotation.Spec.Password != nil {
+		for _, algorithm := range rotation.Spec.Password.HashOutputs {
+			hash, err := security.HashPassword(algorithm, password)
+			if err != nil {
+				return nil, err
+			}
+			v.data[hashKey(algorithm)] = hash
+		}
+	}
+	return v, nil
+}
+
+// usesBcrypt indica si la contraseña generada pasa por bcrypt: con el tipo Htpasswd o
+// con la salida de hash bcrypt.
+func usesBcrypt(rotation *rotationv1alpha1.Rotation) bool {
+	if rotation.Spec.Type == rotationv1alpha1.TypeHtpasswd {
+		return true
+	}
+	return rotation.Spec.Password != nil && slices.Contains(rotation.Spec.Password.HashOutputs, security.HashBcrypt)
+}
+
+// totpValue genera una semilla TOTP nueva y su URI de aprovisionamiento.
+func totpValue(rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	spec := rotation.Spec.TOTP
+	if spec == nil {
+		return nil, fmt.Errorf("el tipo %s requiere spec.totp", rotationv1alpha1.TypeTOTP)
+	}
+	cfg := security.TOTPConfig{
+		Issuer:      spec.Issuer,
+		AccountName: spec.AccountName,
+		Algorithm:   spec.Algorithm,
+		Digits:      spec.Digits,
+		Period:      spec.Period,
+	}
+	// Los mismos valores por defecto que fija el CRD
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "SHA1"
+	}
+	if cfg.Digits == 0 {
+		cfg.Digits = 6
+	}
+	if cfg.Period == 0 {
+		cfg.Period = 30
+	}
+
+	seed, uri, err := security.GenerateTOTP(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatedValue{key: rotationv1alpha1.TOTPSeedKey, data: map[string]string{
+		rotationv1alpha1.TOTPSeedKey: seed,
+		"uri":                        uri,
+	}}, nil
+}
+
+// wireGuardValue genera un par de claves de WireGuard y, si se pide, una clave precompartida.
+func wireGuardValue(rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	pair, err := security.GenerateWireGuardKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	v := &rotatedValue{key: rotationv1alpha1.PrivateKeyKey, data: map[string]string{
+		rotationv1alpha1.PrivateKeyKey: pair.PrivateKey,
+		publicKeyKey:                   pair.PublicKey,
+	}}
+	if rotation.Spec.WireGuard != nil && rotation.Spec.WireGuard.PresharedKey {
+		if v.data["presharedKey"], err = security.GenerateWireGuardPresharedKey(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// openPGPAlgorithms relaciona los algoritmos OpenPGP con su nombre en la lista FIPS.
+var openPGPAlgorithms = map[string]string{
+	openpgp.AlgorithmEd25519: "ed25519",
+	openpgp.AlgorithmRSA3072: "rsa-3072",
+	openpgp.AlgorithmRSA4096: "rsa-4096",
+}
+
+// openPGPValue genera una clave OpenPGP que caduca tras la validez configurada.
+func (r *RotationReconciler) openPGPValue(rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	spec := rotation.Spec.OpenPGP
+	if spec == nil {
+		return nil, fmt.Errorf("el tipo %s requiere spec.openPGP", rotationv1alpha1.TypeOpenPGP)
+	}
+	// Los mismos valores por defecto que fija el CRD
+	cfg := openpgp.Config{Algorithm: spec.Algorithm, Name: spec.Name, Email: spec.Email, Comment: spec.Comment}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = openpgp.AlgorithmEd25519
+	}
+	expiry := spec.Expiry
+	if expiry == "" {
+		expiry = "8760h"
+	}
+	var err error
+	if cfg.Lifetime, err = time.ParseDuration(expiry); err != nil {
+		return nil, fmt.Errorf("expiry %q no es una duración válida: %w", expiry, err)
+	}
+	if err = security.RequireApproved(openPGPAlgorithms[cfg.Algorithm]); err != nil {
+		return nil, err
+	}
+
+	key, err := openpgp.Generate(cfg, r.now())
+	if err != nil {
+		return nil, err
+	}
+	return &rotatedValue{
+		key: rotationv1alpha1.PrivateKeyKey,
+		data: map[string]string{
+			rotationv1alpha1.PrivateKeyKey: key.PrivateKey,
+			publicKeyKey:                   key.PublicKey,
+			keyFingerprintKey:              key.Fingerprint,
+		},
+		expires: &key.Expires,
+	}, nil
+}
+
+// dataEncryptionKeyValue añade una clave nueva al anillo publicado en Vault, que
+// conserva las anteriores para descifrar los datos existentes.
+func (r *RotationReconciler) dataEncryptionKeyValue(ctx context.Context, rotation *rotationv1alpha1.Rotation) (*rotatedValue, error) {
+	retain := defaultRetainedKeys
+	if spec := rotation.Spec.DataEncryptionKey; spec != nil && spec.Retain != nil {
+		retain = *spec.Retain
+	}
+
+	current, err := r.readFromVault(ctx, rotation.Spec.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+	var ring security.KeyRing
+	if raw, _ := current[rotationv1alpha1.KeyRingKey].(string); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &ring); err != nil {
+			return nil, fmt.Errorf("el anillo de claves de Vault no es válido: %w", err)
+		}
+	}
+
+	ring, err = ring.Rotate(retain)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(ring)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatedValue{key: rotationv1alpha1.KeyRingKey, data: map[string]string{
+		rotationv1alpha1.KeyRingKey: string(encoded),
+		activeKeyIDKey:              strconv.FormatInt(ring.ActiveKeyID, 10),
+	}}, nil
+}
+
+// hashKey devuelve la clave del documento de Vault con el hash de un algoritmo.
+func hashKey(algorithm string) string {
+	return "hash_" + strings.ReplaceAll(algorithm, "-", "_")
+}
+
+// htpasswdValue publica la contraseña junto a su entrada htpasswd, que es el valor
+// principal: el servidor solo necesita el hash y el cliente lee la contraseña de Vault.
+func htpasswdValue(rotation *rotationv1alpha1.Rotation, password string) (*rotatedValue, error) {
+	if rotation.Spec.Htpasswd == nil {
+		return nil, fmt.Errorf("el tipo %s requiere spec.htpasswd", rotationv1alpha1.TypeHtpasswd)
+	}
+	entry, err := security.HtpasswdEntry(rotation.Spec.Htpasswd.Username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatedValue{key: rotationv1alpha1.HtpasswdSecretKey, data: map[string]string{
+		rotationv1alpha1.HtpasswdSecretKey: entry,
+		"username":                         rotation.Spec.Htpasswd.Username,
+		rotationv1alpha1.DefaultSecretKey:  password,
+	}}, nil
+}
+
+// ownedKeys devuelve las claves del documento de Vault que escribe la Rotation: el valor
+// principal, los datos que genera su tipo junto a él (de los que se componen las
+// plantillas), el valor anterior y el vencimiento.
+func ownedKeys(rotation *rotationv1alpha1.Rotation) map[string]bool {
+	key := valueKey(rotation)
+	owned := map[string]bool{key: true, key + rotationv1alpha1.PreviousValueSuffix: true, expiresKey: true}
+	switch rotation.Spec.Type {
+	case rotationv1alpha1.TypeDockerConfigJSON, rotationv1alpha1.TypeHtpasswd:
+		owned["username"] = true
+		owned[rotationv1alpha1.DefaultSecretKey] = true
+	case rotationv1alpha1.TypeTOTP:
+		owned["uri"] = true
+	case rotationv1alpha1.TypeWireGuard:
+		owned[publicKeyKey] = true
+		owned["presharedKey"] = true
+	case rotationv1alpha1.TypeOpenPGP:
+		owned[publicKeyKey] = true
+		owned[keyFingerprintKey] = true
+	case rotationv1alpha1.TypeDataEncryptionKey:
+		owned[activeKeyIDKey] = true
+	}
+	if rotation.Spec.Password != nil {
+		for _, algorithm := range rotation.Spec.Password.HashOutputs {
+			owned[hashKey(algorithm)] = true
+		}
+	}
+	return owned
+}
+
+// valueFromVault reconstruye el valor escrito por un intento a partir del documento
+// leído de Vault. Devuelve nil si el documento no contiene el valor principal. En modo
+// Merge solo recoge las claves de ownedKeys.
+func valueFromVault(rotation *rotationv1alpha1.Rotation, doc map[string]interface{}) (*rotatedValue, error) {
+	key := valueKey(rotation)
+	if value, _ := doc[key].(string); value == "" {
+		return nil, nil
+	}
+
+	// En modo Merge la ruta comparte documento con claves ajenas que no se republican
+	var owned map[string]bool
+	if rotation.Spec.WriteMode == rotationv1alpha1.WriteModeMerge {
+		owned = ownedKeys(rotation)
+	}
+	v := &rotatedValue{key: key, data: map[string]string{}}
+	for k, raw := range doc {
+		value, ok := raw.(string)
+		if !ok || k == vault.AttemptKey || k == "rotated_by" || owned != nil && !owned[k] {
+			continue
+		}
+		if k == expiresKey {
+			expires, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("vencimiento no válido en Vault: %w", err)
+			}
+			v.expires = &expires
+			continue
+		}
+		v.data[k] = value
+	}
+	return v, nil
+}
+
+// nextRotationAfter devuelve la próxima rotación tras una completada en now: al cumplirse
+// el intervalo o, si el valor caduca antes, cuando haya transcurrido renewFraction de su vida.
+func nextRotationAfter(now time.Time, interval time.Duration, v *rotatedValue) time.Time {
+	next := now.Add(interval)
+	if v.expires != nil {
+		renewAt := now.Add(time.Duration(float64(v.expires.Sub(now)) * renewFraction))
+		if renewAt.Before(next) {
+			next = renewAt
+		}
+	}
+	return next
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rotationv1alpha1 "github.com/AndreCbrera/secret-rotator-operator/api/v1alpha1"
+)
+
+// mirroredPath es una ruta de spec.vaultPaths ya escrita, con el documento que había
+// antes para poder restablecerla; previous es nil si la ruta no existía.
+type mirroredPath struct {
+	index    int
+	previous map[string]interface{}
+}
+
+// rotationPaths devuelve las rutas de Vault en las que escribe la Rotation: vaultPath y
+// las de spec.vaultPaths.
+func rotationPaths(rotation *rotationv1alpha1.Rotation) []string {
+	return append([]string{rotation.Spec.VaultPath}, rotation.Spec.VaultPaths...)
+}
+
+// writeVaultPaths escribe doc en las rutas adicionales de spec.vaultPaths y deja el
+// resultado de cada una en status.vaultPaths. Con AllOrNothing se detiene en el primer
+// fallo, restablece las ya escritas y devuelve el error; si todas se escriben, devuelve
+// las rutas escritas para poder restablecerlas si después falla vaultPath. Con
+// BestEffort los fallos solo se informan.
+func (r *RotationReconciler) writeVaultPaths(ctx context.Context, rotation *rotationv1alpha1.Rotation, doc map[string]interface{}) ([]mirroredPath, error) {
+	if len(rotation.Spec.VaultPaths) == 0 {
+		rotation.Status.VaultPaths = nil
+		return nil, nil
+	}
+
+	allOrNothing := rotation.Spec.VaultPathsPolicy != rotationv1alpha1.VaultPathsBestEffort
+	now := metav1.NewTime(r.now())
+	statuses := make([]rotationv1alpha1.VaultPathStatus, 0, len(rotation.Spec.VaultPaths))
+	var written []mirroredPath
+	var failed []string
+	for _, path := range rotation.Spec.VaultPaths {
+		entry := rotationv1alpha1.VaultPathStatus{Path: path, Result: rotationv1alpha1.VaultPathWritten, Time: now}
+		var previous map[string]interface{}
+		var err error
+		// Solo hace falta el documento anterior si puede haber que restablecerlo
+		if allOrNothing {
+			previous, err = r.readFromVault(ctx, path)
+		}
+		if err == nil {
+			err = r.writeVaultPath(ctx, rotation, path, doc)
+		}
+		if err != nil {
+			entry.Result = rotationv1alpha1.VaultPathFailed
+			entry.Message = err.Error()
+			statuses = append(statuses, entry)
+			failed = append(failed, path)
+			if allOrNothing {
+				rotation.Status.VaultPaths = statuses
+				return nil, errors.Join(fmt.Errorf("fallo al escribir en %s: %w", path, err), r.rollbackVaultPaths(ctx, rotation, written))
+			}
+			continue
+		}
+		statuses = append(statuses, entry)
+		written = append(written, mirroredPath{index: len(statuses) - 1, previous: previous})
+	}
+	rotation.Status.VaultPaths = statuses
+
+	if len(failed) > 0 {
+		logf.FromContext(ctx).Info("No se pudo escribir en algunas rutas adicionales", "rutas", failed)
+		r.event(rotation, corev1.EventTypeWarning, "VaultPathsFailed",
+			fmt.Sprintf("El valor rotado no se escribió en %d de %d rutas adicionales: %v", len(failed), len(rotation.Spec.VaultPaths), failed))
+	}
+	if !allOrNothing {
+		return nil, nil
+	}
+	return written, nil
+}
+
+// rollbackVaultPaths restablece el documento anterior de las rutas adicionales ya
+// escritas y las marca RolledBack. Una ruta que no existía antes de rotar se borra con
+// sus metadatos KV, para que el valor nuevo, sin publicar, no quede en ninguna versión.
+func (r *RotationReconciler) rollbackVaultPaths(ctx context.Context, rotation *rotationv1alpha1.Rotation, written []mirroredPath) error {
+	var errs []error
+	for _, m := range written {
+		entry := &rotation.Status.VaultPaths[m.index]
+		if err := r.restoreVaultPath(ctx, entry.Path, m.previous); err != nil {
+			entry.Message = fmt.Sprintf("fallo al restablecer el valor anterior: %v", err)
+			errs = append(errs, fmt.Errorf("fallo al restablecer %s: %w", entry.Path, err))
+			continue
+		}
+		entry.Result = rotationv1alpha1.VaultPathRolledBack
+		if m.previous == nil {
+			entry.Message = "la ruta no existía antes de rotar y se ha borrado"
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreVaultPath vuelve a escribir en path el documento previous o, si es nil porque la
+// ruta no existía, la borra.
+func (r *RotationReconciler) restoreVaultPath(ctx context.Context, path string, previous map[string]interface{}) error {
+	if previous != nil {
+		return r.restoreVault(ctx, path, previous)
+	}
+	lister, ok := r.listingBackend()
+	if !ok {
+		return errors.New("el backend no permite borrar la ruta, que no existía antes de rotar")
+	}
+	return r.doVault(ctx, func(ctx context.Context) error {
+		return lister.Delete(ctx, path)
+	})
+}
@@ -0,0 +1,148 @@
+package endpoint
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout limita la duración de cualquier llamada a un endpoint externo.
+const DefaultTimeout = 15 * time.Second
+
+// TLSMaterial contiene el material TLS ya resuelto (desde Secrets) para un endpoint.
+type TLSMaterial struct {
+	// CAPEM, si no está vacío, sustituye a las CAs del sistema.
+	CAPEM []byte
+	// CertPEM y KeyPEM forman el certificado de cliente para mTLS.
+	CertPEM []byte
+	KeyPEM  []byte
+	// ClientCertificate, si CertPEM está vacío, resuelve el certificado de cliente en cada
+	// handshake (e.g., el X.509-SVID del operador, que rota con frecuencia).
+	ClientCertificate func() (*tls.Certificate, error)
+	// PinnedSHA256 son huellas SHA-256 (hex) de certificados aceptados en la cadena del servidor.
+	PinnedSHA256 []string
+	// ServerName sustituye al nombre usado para SNI y verificación.
+	ServerName string
+}
+
+// NewHTTPClient construye un cliente HTTP que aplica el material TLS indicado.
+// Con material nil devuelve un cliente con la configuración TLS por defecto.
+func NewHTTPClient(m *TLSMaterial) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if m != nil {
+		if len(m.CAPEM) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(m.CAPEM) {
+				return nil, fmt.Errorf("no se encontraron certificados válidos en la CA configurada")
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if len(m.CertPEM) > 0 || len(m.KeyPEM) > 0 {
+			cert, err := tls.X509KeyPair(m.CertPEM, m.KeyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("certificado de cliente no válido: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		} else if m.ClientCertificate != nil {
+			tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return m.ClientCertificate()
+			}
+		}
+
+		tlsConfig.ServerName = m.ServerName
+
+		if len(m.PinnedSHA256) > 0 {
+			pins := make(map[string]bool, len(m.PinnedSHA256))
+			for _, pin := range m.PinnedSHA256 {
+				pins[normalizePin(pin)] = true
+			}
+			// La verificación estándar de la cadena sigue aplicándose; el anclaje la complementa
+			tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+				for _, cert := range cs.PeerCertificates {
+					sum := sha256.Sum256(cert.Raw)
+					if pins[hex.EncodeToString(sum[:])] {
+						return nil
+					}
+				}
+				return fmt.Errorf("ningún certificado del servidor coincide con las huellas ancladas")
+			}
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: DefaultTimeout}, nil
+}
+
+// maxCachedClients limita los clientes que guarda Clients: el material de los Secrets
+// cambia al rotar sus certificados y los clientes anteriores dejan de usarse.
+const maxCachedClients = 64
+
+// Clients reutiliza un cliente HTTP por material TLS. Cada cliente tiene su propio
+// Transport con su pool de conexiones: crear uno por llamada abriría conexiones nuevas
+// cada vez y dejaría las anteriores ociosas hasta que caduquen. El valor cero está listo
+// para usarse.
+type Clients struct {
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// Get devuelve el cliente del material TLS indicado, creándolo la primera vez. El
+// material se identifica por su contenido y ClientCertificate solo por estar presente,
+// así que una misma caché debe usarse siempre con la misma función.
+func (c *Clients) Get(m *TLSMaterial) (*http.Client, error) {
+	key := materialKey(m)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+	client, err := NewHTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	// Al llenarse se descartan todos y se cierran sus conexiones ociosas
+	if len(c.clients) >= maxCachedClients {
+		for _, old := range c.clients {
+			old.CloseIdleConnections()
+		}
+		c.clients = nil
+	}
+	if c.clients == nil {
+		c.clients = map[string]*http.Client{}
+	}
+	c.clients[key] = client
+	return client, nil
+}
+
+// materialKey resume el material TLS en una clave de Clients.
+func materialKey(m *TLSMaterial) string {
+	if m == nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, field := range [][]byte{m.CAPEM, m.CertPEM, m.KeyPEM, []byte(m.ServerName)} {
+		// La longitud delante de cada campo evita que dos materiales distintos se concatenen igual
+		fmt.Fprintf(h, "%d:", len(field))
+		h.Write(field)
+	}
+	for _, pin := range m.PinnedSHA256 {
+		fmt.Fprintf(h, "pin:%s;", normalizePin(pin))
+	}
+	fmt.Fprintf(h, "identity:%t", m.ClientCertificate != nil)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizePin acepta huellas con o sin separadores ":" y en cualquier capitalización.
+func normalizePin(pin string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(pin, "sha256:"), ":", ""))
+}
@@ -0,0 +1,133 @@
+package endpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePin(t *testing.T) {
+	const want = "ab01cd23"
+	tests := []struct {
+		name string
+		pin  string
+	}{
+		{name: "plain", pin: "ab01cd23"},
+		{name: "uppercase", pin: "AB01CD23"},
+		{name: "colons", pin: "AB:01:CD:23"},
+		{name: "prefixed", pin: "sha256:ab:01:cd:23"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizePin(tt.pin); got != want {
+				t.Errorf("normalizePin(%q) = %q, se esperaba %q", tt.pin, got, want)
+			}
+		})
+	}
+}
+
+// colonHex escribe una huella como la muestran openssl y los navegadores: "AB:01:...".
+func colonHex(sum []byte) string {
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+func TestPinnedCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	leaf := server.Certificate()
+	sum := sha256.Sum256(leaf.Raw)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	other := sha256.Sum256([]byte("otro certificado"))
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr string
+	}{
+		{name: "no pins"},
+		{name: "hex pin", pins: []string{hex.EncodeToString(sum[:])}},
+		{name: "colon pin", pins: []string{"sha256:" + colonHex(sum[:])}},
+		{name: "one of several", pins: []string{hex.EncodeToString(other[:]), hex.EncodeToString(sum[:])}},
+		{name: "mismatch", pins: []string{hex.EncodeToString(other[:])}, wantErr: "huellas ancladas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(&TLSMaterial{CAPEM: caPEM, PinnedSHA256: tt.pins})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Get() = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientRejectsInvalidMaterial(t *testing.T) {
+	tests := []struct {
+		name     string
+		material *TLSMaterial
+		wantErr  string
+	}{
+		{name: "CA without certificates", material: &TLSMaterial{CAPEM: []byte("no es PEM")}, wantErr: "CA configurada"},
+		{name: "certificate without key", material: &TLSMaterial{CertPEM: []byte("no es PEM")}, wantErr: "certificado de cliente"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPClient(tt.material)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewHTTPClient() = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientsReuseByMaterial(t *testing.T) {
+	var clients Clients
+	get := func(m *TLSMaterial) *http.Client {
+		t.Helper()
+		client, err := clients.Get(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	base := get(&TLSMaterial{ServerName: "hooks.example.com", PinnedSHA256: []string{"AB:CD"}})
+	if get(&TLSMaterial{ServerName: "hooks.example.com", PinnedSHA256: []string{"abcd"}}) != base {
+		t.Errorf("el mismo material con la huella escrita de otra forma no reutiliza el cliente")
+	}
+	if get(&TLSMaterial{ServerName: "api.example.com", PinnedSHA256: []string{"abcd"}}) == base {
+		t.Errorf("materiales distintos comparten cliente")
+	}
+	if get(nil) != get(nil) {
+		t.Errorf("el material vacío no reutiliza el cliente")
+	}
+
+	// Al superar el límite se empieza de cero
+	for i := range maxCachedClients {
+		get(&TLSMaterial{ServerName: fmt.Sprintf("host-%d.example.com", i)})
+	}
+	if len(clients.clients) > maxCachedClients {
+		t.Errorf("la caché guarda %d clientes, el límite es %d", len(clients.clients), maxCachedClients)
+	}
+}
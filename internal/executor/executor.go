@@ -0,0 +1,24 @@
+// Package executor define los ejecutores que aplican una credencial rotada en el
+// sistema que la utiliza (un directorio LDAP, una base de datos...) antes de publicarla.
+package executor
+
+import "context"
+
+// Executor cambia la credencial en el sistema de destino y comprueba que funciona.
+type Executor interface {
+	// Rotate establece newPassword como credencial vigente.
+	Rotate(ctx context.Context, newPassword string) error
+	// Verify se autentica con newPassword para confirmar que el cambio es efectivo.
+	Verify(ctx context.Context, newPassword string) error
+}
+
+// Revoker es un Executor que puede dar de alta una credencial nueva sin invalidar la
+// vigente, y revocar la anterior más tarde, cuando sus consumidores ya usan la nueva.
+type Revoker interface {
+	Executor
+	// Add establece newPassword como credencial válida sin retirar las que ya lo son.
+	Add(ctx context.Context, newPassword string) error
+	// Revoke invalida oldPassword en el sistema de destino. Revocar una credencial que
+	// ya no es válida no es un error.
+	Revoke(ctx context.Context, oldPassword string) error
+}
@@ -0,0 +1,153 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Etiquetas BER usadas por las operaciones LDAP del ejecutor (RFC 4511).
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	tagBindRequest    = 0x60
+	tagBindResponse   = 0x61
+	tagUnbindRequest  = 0x42
+	tagModifyRequest  = 0x66
+	tagModifyResponse = 0x67
+	tagSimpleAuth     = 0x80
+
+	tagExtendedRequest     = 0x77
+	tagExtendedResponse    = 0x78
+	tagExtendedRequestName = 0x80
+)
+
+// maxMessageSize limita el tamaño de las respuestas leídas del servidor.
+const maxMessageSize = 1 << 20
+
+// element es un elemento BER ya decodificado.
+type element struct {
+	tag      byte
+	contents []byte
+}
+
+func encode(tag byte, contents ...[]byte) []byte {
+	var body []byte
+	for _, c := range contents {
+		body = append(body, c...)
+	}
+	return append(append([]byte{tag}, encodeLength(len(body))...), body...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var out []byte
+	for v := n; v > 0; v >>= 8 {
+		out = append([]byte{byte(v)}, out...)
+	}
+	return append([]byte{0x80 | byte(len(out))}, out...)
+}
+
+func encodeInt(tag byte, v int) []byte {
+	// Representación mínima en complemento a dos
+	b := []byte{byte(v)}
+	for v >>= 8; v != 0 && v != -1; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	// El primer byte debe llevar el signo del valor
+	if v == 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	if v == -1 && b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return encode(tag, b)
+}
+
+func octetString(s []byte) []byte {
+	return encode(tagOctetString, s)
+}
+
+// readElement lee un elemento BER completo de la conexión.
+func readElement(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+
+	length := int(first)
+	if first&0x80 != 0 {
+		n := int(first & 0x7f)
+		if n == 0 || n > 4 {
+			return element{}, errors.New("longitud BER no soportada")
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return element{}, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxMessageSize {
+		return element{}, fmt.Errorf("mensaje LDAP demasiado grande: %d bytes", length)
+	}
+
+	contents := make([]byte, length)
+	if _, err := io.ReadFull(r, contents); err != nil {
+		return element{}, err
+	}
+	return element{tag: tag, contents: contents}, nil
+}
+
+// children decodifica los elementos contenidos en un elemento construido.
+func (e element) children() ([]element, error) {
+	var out []element
+	data := e.contents
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("elemento BER truncado")
+		}
+		tag, length, header := data[0], int(data[1]), 2
+		if data[1]&0x80 != 0 {
+			n := int(data[1] & 0x7f)
+			if n == 0 || n > 4 || len(data) < 2+n {
+				return nil, errors.New("longitud BER no válida")
+			}
+			length = 0
+			for _, b := range data[2 : 2+n] {
+				length = length<<8 | int(b)
+			}
+			header += n
+		}
+		if length < 0 || len(data) < header+length {
+			return nil, errors.New("elemento BER truncado")
+		}
+		out = append(out, element{tag: tag, contents: data[header : header+length]})
+		data = data[header+length:]
+	}
+	return out, nil
+}
+
+func (e element) int() int {
+	v := 0
+	for i, b := range e.contents {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int(b)
+	}
+	return v
+}
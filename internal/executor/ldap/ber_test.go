@@ -0,0 +1,166 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncodeLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: "00"},
+		{n: 127, want: "7f"},
+		{n: 128, want: "8180"},
+		{n: 255, want: "81ff"},
+		{n: 256, want: "820100"},
+		{n: 65536, want: "83010000"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(encodeLength(tt.n)); got != tt.want {
+			t.Errorf("encodeLength(%d) = %s, se esperaba %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeInt(t *testing.T) {
+	tests := []struct {
+		v    int
+		want string
+	}{
+		{v: 0, want: "020100"},
+		{v: 3, want: "020103"},
+		{v: 127, want: "02017f"},
+		{v: 128, want: "02020080"},
+		{v: 256, want: "02020100"},
+		{v: 65535, want: "020300ffff"},
+		{v: -1, want: "0201ff"},
+		{v: -128, want: "020180"},
+		{v: -129, want: "0202ff7f"},
+	}
+	for _, tt := range tests {
+		encoded := encodeInt(tagInteger, tt.v)
+		if got := hex.EncodeToString(encoded); got != tt.want {
+			t.Errorf("encodeInt(%d) = %s, se esperaba %s", tt.v, got, tt.want)
+		}
+		// Y se decodifica al mismo valor
+		parsed, err := readElement(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parsed.int(); got != tt.v {
+			t.Errorf("int() de %s = %d, se esperaba %d", tt.want, got, tt.v)
+		}
+	}
+}
+
+// TestBindRequest comprueba la codificación completa de un BindRequest simple
+// (RFC 4511, apartado 4.2) con el identificador de mensaje 1.
+func TestBindRequest(t *testing.T) {
+	request := encode(tagBindRequest,
+		encodeInt(tagInteger, 3),
+		octetString([]byte("cn=admin")),
+		encode(tagSimpleAuth, []byte("secret")),
+	)
+	message := encode(tagSequence, encodeInt(tagInteger, 1), request)
+	want := "301a" + "020101" + "6015" + "020103" + "0408" + hex.EncodeToString([]byte("cn=admin")) +
+		"8006" + hex.EncodeToString([]byte("secret"))
+	if got := hex.EncodeToString(message); got != want {
+		t.Fatalf("BindRequest = %s, se esperaba %s", got, want)
+	}
+
+	parsed, err := readElement(bufio.NewReader(bytes.NewReader(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := parsed.children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 || parts[0].int() != 1 || parts[1].tag != tagBindRequest {
+		t.Fatalf("mensaje decodificado = %+v", parts)
+	}
+	fields, err := parts[1].children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 || fields[0].int() != 3 || string(fields[1].contents) != "cn=admin" || string(fields[2].contents) != "secret" {
+		t.Errorf("BindRequest decodificado = %+v", fields)
+	}
+}
+
+func TestReadElementLongForm(t *testing.T) {
+	value := bytes.Repeat([]byte("a"), 300)
+	parsed, err := readElement(bufio.NewReader(bytes.NewReader(octetString(value))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.tag != tagOctetString || !bytes.Equal(parsed.contents, value) {
+		t.Errorf("readElement() = %x (%d bytes)", parsed.tag, len(parsed.contents))
+	}
+}
+
+func TestReadElementErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "indefinite length", data: "3080", wantErr: "no soportada"},
+		{name: "length too wide", data: "30850000000001", wantErr: "no soportada"},
+		{name: "too large", data: "308400200000", wantErr: "demasiado grande"},
+		{name: "truncated contents", data: "300500", wantErr: "EOF"},
+		{name: "empty", data: "", wantErr: "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			_, err := readElement(bufio.NewReader(bytes.NewReader(data)))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readElement(%s) = %v, se esperaba %q", tt.data, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChildrenErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{name: "missing length", contents: "02"},
+		{name: "short contents", contents: "020301"},
+		{name: "invalid long form", contents: "0280"},
+		{name: "short long form", contents: "0282"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, _ := hex.DecodeString(tt.contents)
+			if _, err := (element{tag: tagSequence, contents: contents}).children(); err == nil {
+				t.Errorf("children(%s) no devolvió error", tt.contents)
+			}
+		})
+	}
+}
+
+func TestUnicodePwd(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "new", want: "22006e00650077002200"},
+		{password: "", want: "22002200"},
+		{password: "ñ", want: "2200f1002200"},
+		// Fuera del plano básico: par sustituto en UTF-16
+		{password: "😀", want: "22003dd800de2200"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(unicodePwd(tt.password)); got != tt.want {
+			t.Errorf("unicodePwd(%q) = %s, se esperaba %s", tt.password, got, tt.want)
+		}
+	}
+}
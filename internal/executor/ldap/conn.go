@@ -0,0 +1,184 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Operaciones de modificación (RFC 4511, ModifyRequest).
+const (
+	modifyAdd     = 0
+	modifyDelete  = 1
+	modifyReplace = 2
+)
+
+// oidStartTLS identifica la operación extendida StartTLS (RFC 4511, sección 4.14).
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// Códigos de resultado LDAP (RFC 4511, apéndice A).
+const (
+	// resultSuccess es el código de resultado de una operación correcta.
+	resultSuccess = 0
+	// resultNoSuchAttribute indica que el valor que se quería borrar no existe.
+	resultNoSuchAttribute = 16
+	// resultAttributeOrValueExists indica que el valor que se quería añadir ya existe.
+	resultAttributeOrValueExists = 20
+)
+
+// dialTimeout limita el establecimiento de la conexión con el directorio.
+const dialTimeout = 10 * time.Second
+
+// conn es una conexión LDAP mínima: bind simple, modify y unbind.
+type conn struct {
+	net.Conn
+	reader    *bufio.Reader
+	messageID int
+}
+
+// dial conecta con una URL ldaps:// o ldap://. Las conexiones ldap:// se cifran con
+// StartTLS antes de devolverlas: las credenciales nunca viajan en claro.
+func dial(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de LDAP no válida: %w", err)
+	}
+
+	cfg := tlsConfig.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName = u.Hostname()
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var c net.Conn
+	switch u.Scheme {
+	case "ldaps":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		c, err = (&tls.Dialer{NetDialer: dialer, Config: cfg}).DialContext(ctx, "tcp", host)
+	case "ldap":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		c, err = dialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("esquema de LDAP no soportado: %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fallo al conectar con %s: %w", u.Host, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.SetDeadline(deadline)
+	}
+	lc := &conn{Conn: c, reader: bufio.NewReader(c)}
+	if u.Scheme == "ldap" {
+		if err := lc.startTLS(ctx, cfg); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	return lc, nil
+}
+
+// startTLS cifra la conexión con la operación extendida StartTLS.
+func (c *conn) startTLS(ctx context.Context, cfg *tls.Config) error {
+	request := encode(tagExtendedRequest, encode(tagExtendedRequestName, []byte(oidStartTLS)))
+	if err := c.roundTrip(request, tagExtendedResponse); err != nil {
+		return fmt.Errorf("el servidor LDAP no aceptó StartTLS: %w", err)
+	}
+	tlsConn := tls.Client(c.Conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("fallo en la negociación TLS de StartTLS: %w", err)
+	}
+	c.Conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// bind se autentica con un bind simple.
+func (c *conn) bind(dn, password string) error {
+	request := encode(tagBindRequest,
+		encodeInt(tagInteger, 3),
+		octetString([]byte(dn)),
+		encode(tagSimpleAuth, []byte(password)),
+	)
+	return c.roundTrip(request, tagBindResponse)
+}
+
+// modify aplica la operación (modifyAdd, modifyDelete o modifyReplace) a los valores de
+// un atributo de la entrada indicada.
+func (c *conn) modify(dn string, operation int, attribute string, values ...[]byte) error {
+	var vals []byte
+	for _, v := range values {
+		vals = append(vals, octetString(v)...)
+	}
+	change := encode(tagSequence,
+		encodeInt(tagEnumerated, operation),
+		encode(tagSequence, octetString([]byte(attribute)), encode(tagSet, vals)),
+	)
+	request := encode(tagModifyRequest, octetString([]byte(dn)), encode(tagSequence, change))
+	return c.roundTrip(request, tagModifyResponse)
+}
+
+// Close envía un unbind antes de cerrar la conexión.
+func (c *conn) Close() error {
+	c.messageID++
+	_, _ = c.Conn.Write(encode(tagSequence, encodeInt(tagInteger, c.messageID), []byte{tagUnbindRequest, 0}))
+	return c.Conn.Close()
+}
+
+// roundTrip envía una operación y comprueba el resultado de su respuesta.
+func (c *conn) roundTrip(op []byte, responseTag byte) error {
+	c.messageID++
+	if _, err := c.Conn.Write(encode(tagSequence, encodeInt(tagInteger, c.messageID), op)); err != nil {
+		return err
+	}
+
+	msg, err := readElement(c.reader)
+	if err != nil {
+		return fmt.Errorf("fallo al leer la respuesta LDAP: %w", err)
+	}
+	parts, err := msg.children()
+	if err != nil {
+		return err
+	}
+	if msg.tag != tagSequence || len(parts) < 2 || parts[0].int() != c.messageID || parts[1].tag != responseTag {
+		return fmt.Errorf("respuesta LDAP inesperada")
+	}
+
+	result, err := parts[1].children()
+	if err != nil {
+		return err
+	}
+	if len(result) < 3 {
+		return fmt.Errorf("resultado LDAP incompleto")
+	}
+	if code := result[0].int(); code != resultSuccess {
+		return &resultError{code: code, message: string(result[2].contents)}
+	}
+	return nil
+}
+
+// resultError es un resultado LDAP distinto de resultSuccess.
+type resultError struct {
+	code    int
+	message string
+}
+
+func (e *resultError) Error() string {
+	return fmt.Sprintf("el servidor LDAP devolvió el código %d: %s", e.code, e.message)
+}
+
+// isResult indica si err es un resultado LDAP con el código indicado.
+func isResult(err error, code int) bool {
+	var result *resultError
+	return errors.As(err, &result) && result.code == code
+}
@@ -0,0 +1,136 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate genera un certificado autofirmado para 127.0.0.1.
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldap-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// respond escribe la respuesta de una operación con el código de resultado indicado.
+func respond(t *testing.T, c net.Conn, messageID int, tag byte, code int) {
+	t.Helper()
+	op := encode(tag, encodeInt(tagEnumerated, code), octetString(nil), octetString(nil))
+	if _, err := c.Write(encode(tagSequence, encodeInt(tagInteger, messageID), op)); err != nil {
+		t.Error(err)
+	}
+}
+
+// readOperation lee un mensaje LDAP y devuelve la etiqueta de su operación.
+func readOperation(t *testing.T, r *bufio.Reader) byte {
+	t.Helper()
+	msg, err := readElement(r)
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	parts, err := msg.children()
+	if err != nil || len(parts) < 2 {
+		t.Errorf("mensaje LDAP no válido: %v", err)
+		return 0
+	}
+	return parts[1].tag
+}
+
+func TestStartTLS(t *testing.T) {
+	cert, pool := testCertificate(t)
+
+	tests := []struct {
+		name     string
+		startTLS int
+		wantErr  string
+		wantBind bool
+	}{
+		{name: "negotiated", startTLS: resultSuccess, wantBind: true},
+		{name: "refused", startTLS: 2, wantErr: "no aceptó StartTLS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+
+			bound := make(chan bool, 1)
+			go func() {
+				c, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer c.Close()
+				if tag := readOperation(t, bufio.NewReader(c)); tag != tagExtendedRequest {
+					t.Errorf("primera operación = %#x, se esperaba StartTLS", tag)
+				}
+				respond(t, c, 1, tagExtendedResponse, tt.startTLS)
+				if tt.startTLS != resultSuccess {
+					bound <- false
+					return
+				}
+				// El bind solo puede llegar ya cifrado
+				server := tls.Server(c, &tls.Config{Certificates: []tls.Certificate{cert}})
+				if tag := readOperation(t, bufio.NewReader(server)); tag != tagBindRequest {
+					t.Errorf("operación cifrada = %#x, se esperaba un bind", tag)
+				}
+				respond(t, server, 2, tagBindResponse, resultSuccess)
+				bound <- true
+			}()
+
+			exec := &Executor{
+				URL:       "ldap://" + listener.Addr().String(),
+				UserDN:    "cn=app,dc=example,dc=com",
+				TLSConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = exec.Verify(ctx, "nueva")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Verify() = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Verify() = %v, se esperaba %q", err, tt.wantErr)
+			}
+			if got := <-bound; got != tt.wantBind {
+				t.Errorf("bind recibido = %t, se esperaba %t", got, tt.wantBind)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package ldap cambia la contraseña de una cuenta de servicio en un directorio LDAP
+// o Active Directory.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Atributos de contraseña soportados.
+const (
+	// AttributeUserPassword es el atributo estándar (OpenLDAP, 389 DS...).
+	AttributeUserPassword = "userPassword"
+	// AttributeUnicodePwd es el atributo de Active Directory.
+	AttributeUnicodePwd = "unicodePwd"
+)
+
+// Executor cambia la contraseña de UserDN autenticándose como BindDN.
+type Executor struct {
+	// URL del directorio (ldaps://host, o ldap://host con StartTLS).
+	URL string
+	// BindDN y BindPassword son las credenciales con permiso para cambiar la contraseña.
+	BindDN       string
+	BindPassword string
+	// UserDN es la cuenta cuya contraseña se rota.
+	UserDN string
+	// Attribute es AttributeUserPassword (por defecto) o AttributeUnicodePwd.
+	Attribute string
+	// TLSConfig se aplica a las conexiones ldaps:// y a StartTLS.
+	TLSConfig *tls.Config
+}
+
+// Rotate establece la contraseña nueva en el directorio.
+func (e *Executor) Rotate(ctx context.Context, newPassword string) error {
+	attribute, value := e.Attribute, []byte(newPassword)
+	switch attribute {
+	case "", AttributeUserPassword:
+		attribute = AttributeUserPassword
+	case AttributeUnicodePwd:
+		value = unicodePwd(newPassword)
+	default:
+		return fmt.Errorf("atributo de contraseña no soportado: %q", attribute)
+	}
+
+	err := e.modify(ctx, func(c *conn) error {
+		return c.modify(e.UserDN, modifyReplace, attribute, value)
+	})
+	if err != nil {
+		return fmt.Errorf("fallo al cambiar la contraseña de %s: %w", e.UserDN, err)
+	}
+	return nil
+}
+
+// Add añade la contraseña nueva a las vigentes de la cuenta. Solo userPassword admite
+// varios valores: Active Directory guarda una única contraseña en unicodePwd.
+func (e *Executor) Add(ctx context.Context, newPassword string) error {
+	if err := e.multiValued(); err != nil {
+		return err
+	}
+	err := e.modify(ctx, func(c *conn) error {
+		err := c.modify(e.UserDN, modifyAdd, AttributeUserPassword, []byte(newPassword))
+		if isResult(err, resultAttributeOrValueExists) {
+			// Un reintento tras un fallo posterior al alta la encuentra ya añadida
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("fallo al añadir la contraseña nueva de %s: %w", e.UserDN, err)
+	}
+	return nil
+}
+
+// Revoke retira una contraseña de las vigentes de la cuenta; si ya no lo era, no hace
+// nada.
+func (e *Executor) Revoke(ctx context.Context, oldPassword string) error {
+	if err := e.multiValued(); err != nil {
+		return err
+	}
+	err := e.modify(ctx, func(c *conn) error {
+		err := c.modify(e.UserDN, modifyDelete, AttributeUserPassword, []byte(oldPassword))
+		if isResult(err, resultNoSuchAttribute) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("fallo al revocar la contraseña anterior de %s: %w", e.UserDN, err)
+	}
+	return nil
+}
+
+// multiValued comprueba que el atributo de contraseña admite varios valores vigentes.
+func (e *Executor) multiValued() error {
+	if e.Attribute != "" && e.Attribute != AttributeUserPassword {
+		return fmt.Errorf("el atributo %s no admite varias contraseñas vigentes", e.Attribute)
+	}
+	return nil
+}
+
+// modify abre una conexión con el bind administrativo y aplica en ella la modificación.
+func (e *Executor) modify(ctx context.Context, apply func(c *conn) error) error {
+	c, err := dial(ctx, e.URL, e.tlsConfig())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.bind(e.BindDN, e.BindPassword); err != nil {
+		return fmt.Errorf("fallo en el bind administrativo: %w", err)
+	}
+	return apply(c)
+}
+
+// Verify comprueba que la cuenta puede autenticarse con la contraseña nueva.
+func (e *Executor) Verify(ctx context.Context, newPassword string) error {
+	c, err := dial(ctx, e.URL, e.tlsConfig())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.bind(e.UserDN, newPassword); err != nil {
+		return fmt.Errorf("la contraseña nueva no permite autenticarse como %s: %w", e.UserDN, err)
+	}
+	return nil
+}
+
+func (e *Executor) tlsConfig() *tls.Config {
+	if e.TLSConfig != nil {
+		return e.TLSConfig
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// unicodePwd codifica la contraseña como la espera Active Directory: entre comillas y
+// en UTF-16LE.
+func unicodePwd(password string) []byte {
+	units := utf16.Encode([]rune(`"` + password + `"`))
+	out := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(out[2*i:], u)
+	}
+	return out
+}
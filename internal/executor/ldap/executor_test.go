@@ -0,0 +1,123 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// modification es la operación de un ModifyRequest recibido por el servidor de prueba.
+type modification struct {
+	operation int
+	attribute string
+	value     string
+}
+
+// readModify lee un ModifyRequest con un único cambio de un único valor.
+func readModify(t *testing.T, r *bufio.Reader) modification {
+	t.Helper()
+	msg, err := readElement(r)
+	if err != nil {
+		t.Error(err)
+		return modification{}
+	}
+	parts, err := msg.children()
+	if err != nil || len(parts) < 2 || parts[1].tag != tagModifyRequest {
+		t.Errorf("se esperaba un ModifyRequest: %v", err)
+		return modification{}
+	}
+	request, _ := parts[1].children()
+	changes, _ := request[1].children()
+	change, _ := changes[0].children()
+	attribute, _ := change[1].children()
+	values, _ := attribute[1].children()
+	if len(values) != 1 {
+		t.Errorf("valores = %d, se esperaba 1", len(values))
+		return modification{}
+	}
+	return modification{
+		operation: change[0].int(),
+		attribute: string(attribute[0].contents),
+		value:     string(values[0].contents),
+	}
+}
+
+func TestAddRevoke(t *testing.T) {
+	cert, pool := testCertificate(t)
+
+	tests := []struct {
+		name    string
+		add     bool
+		code    int
+		want    modification
+		wantErr string
+	}{
+		{name: "add", add: true, code: resultSuccess, want: modification{modifyAdd, AttributeUserPassword, "s3cr3t"}},
+		{name: "add already present", add: true, code: resultAttributeOrValueExists, want: modification{modifyAdd, AttributeUserPassword, "s3cr3t"}},
+		{name: "revoke", code: resultSuccess, want: modification{modifyDelete, AttributeUserPassword, "s3cr3t"}},
+		{name: "revoke already revoked", code: resultNoSuchAttribute, want: modification{modifyDelete, AttributeUserPassword, "s3cr3t"}},
+		{name: "revoke refused", code: 50, want: modification{modifyDelete, AttributeUserPassword, "s3cr3t"}, wantErr: "código 50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+
+			received := make(chan modification, 1)
+			go func() {
+				c, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer c.Close()
+				r := bufio.NewReader(c)
+				if tag := readOperation(t, r); tag != tagBindRequest {
+					t.Errorf("primera operación = %#x, se esperaba un bind", tag)
+				}
+				respond(t, c, 1, tagBindResponse, resultSuccess)
+				received <- readModify(t, r)
+				respond(t, c, 2, tagModifyResponse, tt.code)
+			}()
+
+			exec := &Executor{
+				URL:       "ldaps://" + listener.Addr().String(),
+				UserDN:    "cn=app,dc=example,dc=com",
+				TLSConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.add {
+				err = exec.Add(ctx, "s3cr3t")
+			} else {
+				err = exec.Revoke(ctx, "s3cr3t")
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+			if got := <-received; got != tt.want {
+				t.Errorf("modificación = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRevokeUnicodePwd(t *testing.T) {
+	// Active Directory no admite varias contraseñas: se rechaza sin conectar
+	exec := &Executor{URL: "ldaps://" + net.JoinHostPort("127.0.0.1", "1"), Attribute: AttributeUnicodePwd}
+	if err := exec.Add(context.Background(), "s3cr3t"); err == nil || !strings.Contains(err.Error(), "no admite varias") {
+		t.Errorf("Add() = %v, se esperaba el rechazo de unicodePwd", err)
+	}
+	if err := exec.Revoke(context.Background(), "s3cr3t"); err == nil || !strings.Contains(err.Error(), "no admite varias") {
+		t.Errorf("Revoke() = %v, se esperaba el rechazo de unicodePwd", err)
+	}
+}
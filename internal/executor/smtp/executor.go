@@ -0,0 +1,110 @@
+// Package smtp rota credenciales de un relay SMTP y comprueba que el servidor acepta
+// la credencial nueva antes de darla por buena.
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/AndreCbrera/secret-rotator-operator/internal/executor"
+)
+
+// Modos de conexión con el servidor SMTP.
+const (
+	// ModeStartTLS conecta en claro y negocia STARTTLS (puerto 587).
+	ModeStartTLS = "StartTLS"
+	// ModeTLS usa TLS implícito desde el inicio (puerto 465).
+	ModeTLS = "TLS"
+)
+
+// dialTimeout limita el establecimiento de la conexión con el servidor.
+const dialTimeout = 10 * time.Second
+
+// Executor delega el cambio de contraseña en el sistema que almacena las cuentas del
+// relay (p. ej., un directorio LDAP) y la verifica con un AUTH en el servidor SMTP.
+type Executor struct {
+	// Store cambia la contraseña de la cuenta en su almacén.
+	Store executor.Executor
+	// Host y Port del servidor SMTP.
+	Host string
+	Port int
+	// Username es el usuario presentado en el AUTH.
+	Username string
+	// Mode es ModeStartTLS (por defecto) o ModeTLS.
+	Mode string
+	// TLSConfig verifica el certificado del servidor.
+	TLSConfig *tls.Config
+}
+
+// Rotate cambia la contraseña en el almacén de cuentas.
+func (e *Executor) Rotate(ctx context.Context, newPassword string) error {
+	return e.Store.Rotate(ctx, newPassword)
+}
+
+// Add añade la contraseña nueva en el almacén de cuentas sin retirar la vigente, si el
+// almacén lo admite.
+func (e *Executor) Add(ctx context.Context, newPassword string) error {
+	store, ok := e.Store.(executor.Revoker)
+	if !ok {
+		return fmt.Errorf("el almacén de cuentas no admite varias contraseñas vigentes")
+	}
+	return store.Add(ctx, newPassword)
+}
+
+// Revoke retira una contraseña anterior del almacén de cuentas.
+func (e *Executor) Revoke(ctx context.Context, oldPassword string) error {
+	store, ok := e.Store.(executor.Revoker)
+	if !ok {
+		return fmt.Errorf("el almacén de cuentas no admite varias contraseñas vigentes")
+	}
+	return store.Revoke(ctx, oldPassword)
+}
+
+// Verify se autentica en el servidor SMTP con la contraseña nueva.
+func (e *Executor) Verify(ctx context.Context, newPassword string) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if e.TLSConfig != nil {
+		cfg = e.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = e.Host
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if e.Mode == ModeTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: cfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("fallo al conectar con %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("fallo en el saludo SMTP: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if e.Mode != ModeTLS {
+		if err := client.StartTLS(cfg); err != nil {
+			return fmt.Errorf("fallo al negociar STARTTLS: %w", err)
+		}
+	}
+	if err := client.Auth(smtp.PlainAuth("", e.Username, newPassword, e.Host)); err != nil {
+		return fmt.Errorf("el servidor SMTP rechazó la credencial nueva de %s: %w", e.Username, err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,126 @@
+// Package snowflake rota la contraseña de un usuario de servicio de Snowflake y la
+// valida con una consulta.
+package snowflake
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/snowflakedb/gosnowflake"
+)
+
+// loginTimeout acota los reintentos de login del driver, que por defecto duran minutos.
+const loginTimeout = 30 * time.Second
+
+// Executor cambia la contraseña de User con ALTER USER usando una cuenta administrativa.
+type Executor struct {
+	// Account es el identificador de la cuenta (e.g., "myorg-myaccount").
+	Account string
+	// User es el usuario de servicio cuya contraseña se rota.
+	User string
+	// AdminUser se autentica con AdminPrivateKey (par de claves) o AdminPassword.
+	AdminUser       string
+	AdminPassword   string
+	AdminPrivateKey *rsa.PrivateKey
+	// AdminRole, si no está vacío, es el rol con el que se ejecuta ALTER USER.
+	AdminRole string
+}
+
+// Rotate ejecuta ALTER USER ... SET PASSWORD con la contraseña nueva.
+func (e *Executor) Rotate(ctx context.Context, newPassword string) error {
+	// ALTER USER no admite parámetros enlazados: la contraseña va como literal citado
+	stmt := fmt.Sprintf("ALTER USER %s SET PASSWORD = %s", quoteIdentifier(e.User), quoteLiteral(newPassword))
+	if err := exec(ctx, e.adminConfig(), stmt); err != nil {
+		return fmt.Errorf("fallo al cambiar la contraseña de %s: %w", e.User, err)
+	}
+	return nil
+}
+
+// Verify inicia sesión como User con la contraseña nueva y ejecuta una consulta simple.
+func (e *Executor) Verify(ctx context.Context, newPassword string) error {
+	cfg := e.config(e.User)
+	cfg.Password = newPassword
+	if err := exec(ctx, cfg, "SELECT 1"); err != nil {
+		return fmt.Errorf("la consulta de validación falló: %w", err)
+	}
+	return nil
+}
+
+// adminConfig devuelve la configuración del driver para la cuenta administrativa: par
+// de claves si hay AdminPrivateKey y, si no, contraseña.
+func (e *Executor) adminConfig() gosnowflake.Config {
+	cfg := e.config(e.AdminUser)
+	cfg.Role = e.AdminRole
+	if e.AdminPrivateKey != nil {
+		cfg.Authenticator = gosnowflake.AuthTypeJwt
+		cfg.PrivateKey = e.AdminPrivateKey
+	} else {
+		cfg.Password = e.AdminPassword
+	}
+	return cfg
+}
+
+// config devuelve la configuración del driver para iniciar sesión como user.
+func (e *Executor) config(user string) gosnowflake.Config {
+	return gosnowflake.Config{
+		Account:          e.Account,
+		User:             user,
+		LoginTimeout:     loginTimeout,
+		DisableTelemetry: true,
+	}
+}
+
+// exec abre una conexión con cfg, ejecuta stmt y la cierra.
+func exec(ctx context.Context, cfg gosnowflake.Config, stmt string) error {
+	db := sql.OpenDB(gosnowflake.NewConnector(gosnowflake.SnowflakeDriver{}, cfg))
+	defer func() { _ = db.Close() }()
+	_, err := db.ExecContext(ctx, stmt)
+	return err
+}
+
+// ParsePrivateKey lee una clave RSA en PEM (PKCS#8 o PKCS#1).
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no se encontró una clave PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("clave privada no válida: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("snowflake requiere una clave RSA")
+	}
+	return key, nil
+}
+
+// simpleIdentifier son los nombres que Snowflake acepta sin comillas (y que resuelve
+// sin distinguir mayúsculas).
+var simpleIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// quoteIdentifier deja tal cual los identificadores simples y cita el resto escapando
+// las comillas dobles.
+func quoteIdentifier(s string) string {
+	if simpleIdentifier.MatchString(s) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteLiteral cita una cadena de Snowflake escapando barras y comillas simples.
+func quoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
@@ -0,0 +1,150 @@
+package snowflake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/snowflakedb/gosnowflake"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "svc_app", want: "svc_app"},
+		{in: "SVC$APP1", want: "SVC$APP1"},
+		{in: "_svc", want: "_svc"},
+		{in: "svc-app", want: `"svc-app"`},
+		{in: "1svc", want: `"1svc"`},
+		{in: "svc app", want: `"svc app"`},
+		{in: `svc"; DROP USER admin; --`, want: `"svc""; DROP USER admin; --"`},
+		{in: "", want: `""`},
+	}
+	for _, tt := range tests {
+		if got := quoteIdentifier(tt.in); got != tt.want {
+			t.Errorf("quoteIdentifier(%q) = %s, se esperaba %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "s3cr3t", want: `'s3cr3t'`},
+		{in: "it's", want: `'it''s'`},
+		{in: `a\b`, want: `'a\\b'`},
+		// La barra se escapa antes que la comilla: \' no puede cerrar el literal
+		{in: `\'`, want: `'\\'''`},
+		{in: "", want: `''`},
+	}
+	for _, tt := range tests {
+		if got := quoteLiteral(tt.in); got != tt.want {
+			t.Errorf("quoteLiteral(%q) = %s, se esperaba %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAdminConfig(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		exec     Executor
+		wantAuth gosnowflake.AuthType
+		wantKey  bool
+		wantPass string
+	}{
+		{
+			name:     "password",
+			exec:     Executor{Account: "myorg-myaccount", AdminUser: "admin", AdminPassword: "clave", AdminRole: "SECURITYADMIN"},
+			wantAuth: gosnowflake.AuthTypeSnowflake,
+			wantPass: "clave",
+		},
+		{
+			name:     "key pair",
+			exec:     Executor{Account: "myorg-myaccount", AdminUser: "admin", AdminPassword: "clave", AdminPrivateKey: key, AdminRole: "SECURITYADMIN"},
+			wantAuth: gosnowflake.AuthTypeJwt,
+			wantKey:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.exec.adminConfig()
+			if cfg.Account != "myorg-myaccount" || cfg.User != "admin" || cfg.Role != "SECURITYADMIN" {
+				t.Errorf("cuenta, usuario o rol = %q, %q, %q", cfg.Account, cfg.User, cfg.Role)
+			}
+			if cfg.Authenticator != tt.wantAuth {
+				t.Errorf("Authenticator = %v, se esperaba %v", cfg.Authenticator, tt.wantAuth)
+			}
+			if (cfg.PrivateKey != nil) != tt.wantKey || cfg.Password != tt.wantPass {
+				t.Errorf("PrivateKey presente = %t, Password = %q", cfg.PrivateKey != nil, cfg.Password)
+			}
+			if cfg.LoginTimeout != loginTimeout || !cfg.DisableTelemetry {
+				t.Errorf("LoginTimeout = %s, DisableTelemetry = %t", cfg.LoginTimeout, cfg.DisableTelemetry)
+			}
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(typ string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "PKCS#1", data: encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{name: "PKCS#8", data: encode("PRIVATE KEY", pkcs8)},
+		{name: "not PEM", data: []byte("clave"), wantErr: "no se encontró una clave PEM"},
+		{name: "not a key", data: encode("PRIVATE KEY", []byte{0x30, 0x00}), wantErr: "clave privada no válida"},
+		{name: "not RSA", data: encode("PRIVATE KEY", ecPKCS8), wantErr: "requiere una clave RSA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParsePrivateKey(tt.data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParsePrivateKey() = %v", err)
+				}
+				if !key.Equal(rsaKey) {
+					t.Errorf("ParsePrivateKey() devolvió otra clave")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePrivateKey() = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
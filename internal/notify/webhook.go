@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Event describe una rotación completada. Nunca incluye el valor del secreto,
+// solo su huella.
+type Event struct {
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	VaultPath   string    `json:"vaultPath"`
+	RotatedAt   time.Time `json:"rotatedAt"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	// PublicKey es la parte pública del valor rotado, si la tiene (e.g., WireGuard).
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// SendWebhook publica el evento como JSON en la URL indicada usando el cliente dado.
+func SendWebhook(ctx context.Context, client *http.Client, url string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("fallo al serializar el evento: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("fallo al construir la petición: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fallo al llamar al webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("el webhook respondió con estado %d", resp.StatusCode)
+	}
+	return nil
+}
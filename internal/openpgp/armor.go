@@ -0,0 +1,44 @@
+package openpgp
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Tipos de bloque de la armadura ASCII.
+const (
+	blockPublicKey  = "PGP PUBLIC KEY BLOCK"
+	blockPrivateKey = "PGP PRIVATE KEY BLOCK"
+)
+
+// armor codifica data en armadura ASCII (RFC 4880, sección 6) con su CRC-24.
+func armor(blockType string, data []byte) string {
+	var b strings.Builder
+	b.WriteString("-----BEGIN " + blockType + "-----\n\n")
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 64 {
+		b.WriteString(encoded[:64] + "\n")
+		encoded = encoded[64:]
+	}
+	b.WriteString(encoded + "\n")
+
+	crc := crc24(data)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString("-----END " + blockType + "-----\n")
+	return b.String()
+}
+
+// crc24 calcula el CRC-24 de la armadura OpenPGP.
+func crc24(data []byte) uint32 {
+	crc := uint32(0xB704CE)
+	for _, octet := range data {
+		crc ^= uint32(octet) << 16
+		for range 8 {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864CFB
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
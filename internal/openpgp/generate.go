@@ -0,0 +1,217 @@
+package openpgp
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Algoritmos de clave admitidos por Generate.
+const (
+	AlgorithmEd25519 = "Ed25519"
+	AlgorithmRSA3072 = "RSA3072"
+	AlgorithmRSA4096 = "RSA4096"
+)
+
+// Config describe la clave a generar.
+type Config struct {
+	// Algorithm es AlgorithmEd25519 (primaria Ed25519 y subclave Curve25519) o
+	// AlgorithmRSA3072/AlgorithmRSA4096 (primaria y subclave RSA).
+	Algorithm string
+	Name      string
+	Email     string
+	Comment   string
+	// Lifetime es la validez de la clave desde su creación; cero no caduca.
+	Lifetime time.Duration
+}
+
+// Key es una clave generada.
+type Key struct {
+	// PrivateKey y PublicKey son las claves transferibles en armadura ASCII.
+	PrivateKey string
+	PublicKey  string
+	// Fingerprint es la huella v4 de la clave primaria en hexadecimal.
+	Fingerprint string
+	// Expires es cero si la clave no caduca.
+	Expires time.Time
+}
+
+// UserID compone el identificador de usuario "Nombre (Comentario) <correo>".
+func (c Config) UserID() string {
+	parts := make([]string, 0, 3)
+	if c.Name != "" {
+		parts = append(parts, c.Name)
+	}
+	if c.Comment != "" {
+		parts = append(parts, "("+c.Comment+")")
+	}
+	if c.Email != "" {
+		parts = append(parts, "<"+c.Email+">")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Generate crea una clave primaria de certificación y firma, una subclave de cifrado
+// y sus autofirmas, con fecha de creación now.
+func Generate(cfg Config, now time.Time) (*Key, error) {
+	uid := cfg.UserID()
+	if uid == "" {
+		return nil, fmt.Errorf("la clave necesita un nombre o un correo")
+	}
+	var lifetime uint32
+	if cfg.Lifetime > 0 {
+		lifetime = uint32(cfg.Lifetime / time.Second)
+	}
+
+	var primary, subkey *keyMaterial
+	var err error
+	switch cfg.Algorithm {
+	case AlgorithmEd25519:
+		if primary, err = ed25519Key(); err == nil {
+			subkey, err = cv25519Key()
+		}
+	case AlgorithmRSA3072, AlgorithmRSA4096:
+		bits := 3072
+		if cfg.Algorithm == AlgorithmRSA4096 {
+			bits = 4096
+		}
+		if primary, err = rsaKey(bits); err == nil {
+			subkey, err = rsaKey(bits)
+		}
+	default:
+		return nil, fmt.Errorf("algoritmo OpenPGP %q no soportado", cfg.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	created := uint32(now.Unix())
+	primaryBody := publicKeyBody(primary, created)
+	subkeyBody := publicKeyBody(subkey, created)
+	fingerprint := v4Fingerprint(primaryBody)
+
+	// Autocertificación del identificador de usuario
+	certHashed := bytes.Join([][]byte{
+		subpacket(subCreationTime, uint32Bytes(created)...),
+		expirationSubpacket(lifetime),
+		subpacket(subKeyFlags, flagCertify|flagSign),
+		subpacket(subPreferredSym, 9, 8, 7),
+		subpacket(subPreferredHash, 10, 8),
+		subpacket(subPreferredCompr, 2, 1),
+		subpacket(subFeatures, 0x01),
+		subpacket(subIssuerFingerprint, append([]byte{keyVersion}, fingerprint...)...),
+	}, nil)
+	uidHeader := append([]byte{0xB4}, uint32Bytes(uint32(len(uid)))...)
+	cert, err := signature(primary, fingerprint, sigPositiveCert, certHashed,
+		hashedKey(primaryBody), uidHeader, []byte(uid))
+	if err != nil {
+		return nil, err
+	}
+
+	// Vinculación de la subclave de cifrado
+	bindingHashed := bytes.Join([][]byte{
+		subpacket(subCreationTime, uint32Bytes(created)...),
+		expirationSubpacket(lifetime),
+		subpacket(subKeyFlags, flagEncrypt),
+		subpacket(subIssuerFingerprint, append([]byte{keyVersion}, fingerprint...)...),
+	}, nil)
+	binding, err := signature(primary, fingerprint, sigSubkeyBinding, bindingHashed,
+		hashedKey(primaryBody), hashedKey(subkeyBody))
+	if err != nil {
+		return nil, err
+	}
+
+	public := bytes.Join([][]byte{
+		packet(tagPublicKey, primaryBody),
+		packet(tagUserID, []byte(uid)),
+		packet(tagSignature, cert),
+		packet(tagPublicSubkey, subkeyBody),
+		packet(tagSignature, binding),
+	}, nil)
+	private := bytes.Join([][]byte{
+		packet(tagSecretKey, secretKeyBody(primaryBody, primary)),
+		packet(tagUserID, []byte(uid)),
+		packet(tagSignature, cert),
+		packet(tagSecretSubkey, secretKeyBody(subkeyBody, subkey)),
+		packet(tagSignature, binding),
+	}, nil)
+
+	key := &Key{
+		PrivateKey:  armor(blockPrivateKey, private),
+		PublicKey:   armor(blockPublicKey, public),
+		Fingerprint: strings.ToUpper(hex.EncodeToString(fingerprint)),
+	}
+	if lifetime > 0 {
+		key.Expires = time.Unix(int64(created)+int64(lifetime), 0)
+	}
+	return key, nil
+}
+
+// publicKeyBody serializa el cuerpo de un paquete de clave pública v4.
+func publicKeyBody(m *keyMaterial, created uint32) []byte {
+	body := append([]byte{keyVersion}, uint32Bytes(created)...)
+	body = append(body, m.algorithm)
+	return append(body, m.public...)
+}
+
+// secretKeyBody añade al cuerpo público el material secreto sin proteger y su suma de control.
+func secretKeyBody(publicBody []byte, m *keyMaterial) []byte {
+	var checksum uint16
+	for _, b := range m.secret {
+		checksum += uint16(b)
+	}
+	body := append(bytes.Clone(publicBody), s2kUsageUnprotected)
+	body = append(body, m.secret...)
+	return binary.BigEndian.AppendUint16(body, checksum)
+}
+
+// hashedKey es la forma en que una clave entra en el hash de una firma.
+func hashedKey(body []byte) []byte {
+	return append([]byte{0x99, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+// v4Fingerprint calcula la huella v4 (SHA-1) de una clave pública.
+func v4Fingerprint(body []byte) []byte {
+	sum := sha1.Sum(hashedKey(body))
+	return sum[:]
+}
+
+// expirationSubpacket devuelve el subpaquete de caducidad, o nada si la clave no caduca.
+func expirationSubpacket(lifetime uint32) []byte {
+	if lifetime == 0 {
+		return nil
+	}
+	return subpacket(subKeyExpiration, uint32Bytes(lifetime)...)
+}
+
+// signature firma con la clave primaria los datos indicados y devuelve el cuerpo del
+// paquete de firma v4.
+func signature(signer *keyMaterial, fingerprint []byte, sigType byte, hashed []byte, data ...[]byte) ([]byte, error) {
+	prefix := []byte{signatureVersion, sigType, signer.algorithm, hashSHA256, byte(len(hashed) >> 8), byte(len(hashed))}
+	prefix = append(prefix, hashed...)
+
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	h.Write(prefix)
+	h.Write([]byte{signatureVersion, 0xFF})
+	h.Write(uint32Bytes(uint32(len(prefix))))
+	digest := h.Sum(nil)
+
+	mpis, err := signer.sign(digest)
+	if err != nil {
+		return nil, fmt.Errorf("fallo al firmar: %w", err)
+	}
+
+	unhashed := subpacket(subIssuer, fingerprint[len(fingerprint)-8:]...)
+	body := append(prefix, byte(len(unhashed)>>8), byte(len(unhashed)))
+	body = append(body, unhashed...)
+	body = append(body, digest[:2]...)
+	return append(body, mpis...), nil
+}
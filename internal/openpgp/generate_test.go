@@ -0,0 +1,314 @@
+package openpgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parsedPacket es un paquete leído de una clave transferible.
+type parsedPacket struct {
+	tag  byte
+	body []byte
+}
+
+// dearmor decodifica la armadura ASCII y comprueba su CRC-24.
+func dearmor(t *testing.T, blockType, armored string) []byte {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(armored), "\n")
+	if lines[0] != "-----BEGIN "+blockType+"-----" || lines[len(lines)-1] != "-----END "+blockType+"-----" {
+		t.Fatalf("armadura sin las líneas de %s:\n%s", blockType, armored)
+	}
+	var encoded strings.Builder
+	var checksum string
+	for _, line := range lines[2 : len(lines)-1] {
+		if strings.HasPrefix(line, "=") {
+			checksum = line[1:]
+			break
+		}
+		if len(line) > 64 {
+			t.Errorf("línea de armadura de %d caracteres", len(line))
+		}
+		encoded.WriteString(line)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	crc, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(crc) != 3 {
+		t.Fatalf("CRC de la armadura %q no válido", checksum)
+	}
+	if got := crc24(data); uint32(crc[0])<<16|uint32(crc[1])<<8|uint32(crc[2]) != got {
+		t.Errorf("el CRC de la armadura no coincide con el de los datos (%06x)", got)
+	}
+	return data
+}
+
+// readPackets separa los paquetes de cabecera de formato nuevo.
+func readPackets(t *testing.T, data []byte) []parsedPacket {
+	t.Helper()
+	var packets []parsedPacket
+	for len(data) > 0 {
+		if data[0]&0xC0 != 0xC0 {
+			t.Fatalf("cabecera de paquete %02x no es de formato nuevo", data[0])
+		}
+		tag := data[0] & 0x3F
+		var n, header int
+		switch {
+		case data[1] < 192:
+			n, header = int(data[1]), 2
+		case data[1] < 224:
+			n, header = (int(data[1])-192)<<8+int(data[2])+192, 3
+		case data[1] == 0xFF:
+			n, header = int(binary.BigEndian.Uint32(data[2:6])), 6
+		default:
+			t.Fatalf("longitud parcial de paquete no esperada")
+		}
+		packets = append(packets, parsedPacket{tag: tag, body: data[header : header+n]})
+		data = data[header+n:]
+	}
+	return packets
+}
+
+// readMPI devuelve el primer MPI de data y el resto.
+func readMPI(data []byte) ([]byte, []byte) {
+	bits := int(binary.BigEndian.Uint16(data))
+	n := (bits + 7) / 8
+	return data[2 : 2+n], data[2+n:]
+}
+
+// leftPad rellena b con ceros a la izquierda hasta n bytes.
+func leftPad(b []byte, n int) []byte {
+	return append(make([]byte, n-len(b)), b...)
+}
+
+// publicLength devuelve la longitud del cuerpo de clave pública al principio de body.
+func publicLength(body []byte) int {
+	fields := body[6:]
+	switch body[5] {
+	case algoRSA:
+		_, rest := readMPI(fields)
+		_, rest = readMPI(rest)
+		return len(body) - len(rest)
+	case algoEdDSA:
+		_, rest := readMPI(fields[1+int(fields[0]):])
+		return len(body) - len(rest)
+	case algoECDH:
+		_, rest := readMPI(fields[1+int(fields[0]):])
+		return len(body) - len(rest) + 4
+	}
+	return len(body)
+}
+
+// verifySignature comprueba una firma v4 de la clave primaria sobre los datos indicados
+// y devuelve sus subpaquetes con hash.
+func verifySignature(t *testing.T, primary []byte, sig []byte, data ...[]byte) []byte {
+	t.Helper()
+	if sig[0] != signatureVersion || sig[3] != hashSHA256 {
+		t.Fatalf("firma de versión %d y hash %d, se esperaba v4 con SHA-256", sig[0], sig[3])
+	}
+	hashedLen := int(binary.BigEndian.Uint16(sig[4:6]))
+	prefix := sig[:6+hashedLen]
+	unhashedLen := int(binary.BigEndian.Uint16(sig[6+hashedLen:]))
+	rest := sig[8+hashedLen+unhashedLen:]
+
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	h.Write(prefix)
+	h.Write([]byte{signatureVersion, 0xFF})
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(prefix))))
+	digest := h.Sum(nil)
+	if !bytes.Equal(rest[:2], digest[:2]) {
+		t.Errorf("los 16 bits de control de la firma no coinciden con el digest")
+	}
+
+	fields := primary[6:]
+	switch sig[2] {
+	case algoEdDSA:
+		point, _ := readMPI(fields[1+int(fields[0]):])
+		r, s := readMPI(rest[2:])
+		s, _ = readMPI(s)
+		signature := append(leftPad(r, 32), leftPad(s, 32)...)
+		if !ed25519.Verify(ed25519.PublicKey(point[1:]), digest, signature) {
+			t.Errorf("la firma Ed25519 no verifica")
+		}
+	case algoRSA:
+		n, rest2 := readMPI(fields)
+		e, _ := readMPI(rest2)
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		signature, _ := readMPI(rest[2:])
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, leftPad(signature, key.Size())); err != nil {
+			t.Errorf("la firma RSA no verifica: %v", err)
+		}
+	default:
+		t.Fatalf("algoritmo de firma %d no esperado", sig[2])
+	}
+	return sig[6 : 6+hashedLen]
+}
+
+func TestGenerate(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		cfg       Config
+		primary   byte
+		subkey    byte
+		wantUID   string
+		wantUntil time.Time
+	}{
+		{name: "Ed25519", cfg: Config{Algorithm: AlgorithmEd25519, Name: "Deploy Bot", Email: "deploy@example.com", Lifetime: 365 * 24 * time.Hour},
+			primary: algoEdDSA, subkey: algoECDH, wantUID: "Deploy Bot <deploy@example.com>", wantUntil: now.Add(365 * 24 * time.Hour)},
+		{name: "RSA3072 without expiration", cfg: Config{Algorithm: AlgorithmRSA3072, Name: "Deploy Bot", Comment: "ci"},
+			primary: algoRSA, subkey: algoRSA, wantUID: "Deploy Bot (ci)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Generate(tt.cfg, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !key.Expires.Equal(tt.wantUntil) {
+				t.Errorf("Expires = %s, se esperaba %s", key.Expires, tt.wantUntil)
+			}
+
+			public := readPackets(t, dearmor(t, blockPublicKey, key.PublicKey))
+			wantTags := []byte{tagPublicKey, tagUserID, tagSignature, tagPublicSubkey, tagSignature}
+			if len(public) != len(wantTags) {
+				t.Fatalf("la clave pública tiene %d paquetes, se esperaban %d", len(public), len(wantTags))
+			}
+			for i, p := range public {
+				if p.tag != wantTags[i] {
+					t.Fatalf("paquete %d con etiqueta %d, se esperaba %d", i, p.tag, wantTags[i])
+				}
+			}
+			primary, uid, cert, subkey, binding := public[0].body, public[1].body, public[2].body, public[3].body, public[4].body
+
+			if primary[0] != keyVersion || binary.BigEndian.Uint32(primary[1:5]) != uint32(now.Unix()) || primary[5] != tt.primary {
+				t.Errorf("clave primaria v%d creada en %d con algoritmo %d", primary[0], binary.BigEndian.Uint32(primary[1:5]), primary[5])
+			}
+			if subkey[5] != tt.subkey {
+				t.Errorf("subclave con algoritmo %d, se esperaba %d", subkey[5], tt.subkey)
+			}
+			if string(uid) != tt.wantUID {
+				t.Errorf("identificador de usuario %q, se esperaba %q", uid, tt.wantUID)
+			}
+			fingerprint := sha1.Sum(append([]byte{0x99, byte(len(primary) >> 8), byte(len(primary))}, primary...))
+			if want := strings.ToUpper(hex.EncodeToString(fingerprint[:])); key.Fingerprint != want {
+				t.Errorf("Fingerprint = %s, se esperaba %s", key.Fingerprint, want)
+			}
+
+			// Las autofirmas verifican con la clave primaria publicada
+			hashed := func(body []byte) []byte {
+				return append([]byte{0x99, byte(len(body) >> 8), byte(len(body))}, body...)
+			}
+			uidHeader := append([]byte{0xB4}, binary.BigEndian.AppendUint32(nil, uint32(len(uid)))...)
+			certSubpackets := verifySignature(t, primary, cert, hashed(primary), uidHeader, uid)
+			bindingSubpackets := verifySignature(t, primary, binding, hashed(primary), hashed(subkey))
+			if cert[1] != sigPositiveCert || binding[1] != sigSubkeyBinding {
+				t.Errorf("tipos de firma %02x y %02x", cert[1], binding[1])
+			}
+			if !bytes.Contains(certSubpackets, subpacket(subKeyFlags, flagCertify|flagSign)) ||
+				!bytes.Contains(bindingSubpackets, subpacket(subKeyFlags, flagEncrypt)) {
+				t.Errorf("indicadores de uso de las autofirmas no esperados")
+			}
+			expiration := subpacket(subKeyExpiration, binary.BigEndian.AppendUint32(nil, uint32(tt.cfg.Lifetime/time.Second))...)
+			if bytes.Contains(certSubpackets, expiration) != (tt.cfg.Lifetime > 0) {
+				t.Errorf("subpaquete de caducidad %x en la certificación %x", expiration, certSubpackets)
+			}
+
+			// La clave privada lleva los mismos paquetes públicos y el material secreto sin proteger
+			private := readPackets(t, dearmor(t, blockPrivateKey, key.PrivateKey))
+			if len(private) != len(wantTags) || private[0].tag != tagSecretKey || private[3].tag != tagSecretSubkey {
+				t.Fatalf("la clave privada no tiene la estructura esperada")
+			}
+			for i, pub := range []int{0, 3} {
+				body := private[pub].body
+				n := publicLength(body)
+				if !bytes.Equal(body[:n], public[pub].body) {
+					t.Errorf("la parte pública del paquete secreto %d no coincide con la clave pública", i)
+				}
+				secret := body[n+1 : len(body)-2]
+				var checksum uint16
+				for _, b := range secret {
+					checksum += uint16(b)
+				}
+				if body[n] != s2kUsageUnprotected || binary.BigEndian.Uint16(body[len(body)-2:]) != checksum {
+					t.Errorf("paquete secreto %d protegido o con suma de control incorrecta", i)
+				}
+			}
+			if tt.primary == algoEdDSA {
+				seed, _ := readMPI(private[0].body[publicLength(private[0].body)+1:])
+				point, _ := readMPI(primary[7+int(primary[6]):])
+				if !bytes.Equal(ed25519.NewKeyFromSeed(leftPad(seed, 32)).Public().(ed25519.PublicKey), point[1:]) {
+					t.Errorf("la semilla Ed25519 no corresponde a la clave pública")
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "without identity", cfg: Config{Algorithm: AlgorithmEd25519}},
+		{name: "unknown algorithm", cfg: Config{Algorithm: "DSA", Name: "Deploy Bot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.cfg, time.Now()); err == nil {
+				t.Errorf("Generate() no devolvió error")
+			}
+		})
+	}
+}
+
+func TestMPI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "01", want: "000101"},
+		{in: "00ff", want: "0008ff"},
+		{in: "0100", want: "00090100"},
+		{in: "", want: "0000"},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		if got := hex.EncodeToString(mpi(in)); got != tt.want {
+			t.Errorf("mpi(%s) = %s, se esperaba %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPacketLengths(t *testing.T) {
+	tests := []struct {
+		n      int
+		header string
+	}{
+		{n: 100, header: "cd64"},
+		{n: 192, header: "cdc000"},
+		{n: 8383, header: "cddfff"},
+		{n: 8384, header: "cdff000020c0"},
+	}
+	for _, tt := range tests {
+		got := packet(tagUserID, make([]byte, tt.n))
+		if header := hex.EncodeToString(got[:len(got)-tt.n]); header != tt.header {
+			t.Errorf("cabecera para %d bytes = %s, se esperaba %s", tt.n, header, tt.header)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package openpgp
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Algoritmos de clave pública.
+const (
+	algoRSA   = 1
+	algoECDH  = 18
+	algoEdDSA = 22
+)
+
+// OIDs (sin la etiqueta DER) de las curvas de RFC 6637 y draft-koch-eddsa-for-openpgp.
+var (
+	oidEd25519    = []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0xDA, 0x47, 0x0F, 0x01}
+	oidCurve25519 = []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0x97, 0x55, 0x01, 0x05, 0x01}
+)
+
+// keyMaterial es el material de una clave o subclave, ya codificado para sus paquetes.
+type keyMaterial struct {
+	algorithm byte
+	// public son los campos públicos del paquete de clave, tras el algoritmo.
+	public []byte
+	// secret son las MPIs secretas, sin proteger.
+	secret []byte
+	// sign devuelve las MPIs de la firma de un digest SHA-256; nil si la clave no firma.
+	sign func(digest []byte) ([]byte, error)
+}
+
+// rsaKey genera una clave RSA del tamaño indicado, válida para firmar y cifrar.
+func rsaKey(bits int) (*keyMaterial, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("fallo al generar la clave RSA: %w", err)
+	}
+	// OpenPGP exige p < q y u = p^-1 mod q
+	p, q := key.Primes[0], key.Primes[1]
+	if p.Cmp(q) > 0 {
+		p, q = q, p
+	}
+	u := new(big.Int).ModInverse(p, q)
+
+	m := &keyMaterial{algorithm: algoRSA}
+	m.public = append(mpi(key.N.Bytes()), mpi(big.NewInt(int64(key.E)).Bytes())...)
+	for _, n := range []*big.Int{key.D, p, q, u} {
+		m.secret = append(m.secret, mpi(n.Bytes())...)
+	}
+	m.sign = func(digest []byte) ([]byte, error) {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+		if err != nil {
+			return nil, err
+		}
+		return mpi(sig), nil
+	}
+	return m, nil
+}
+
+// ed25519Key genera una clave EdDSA sobre Ed25519 para certificar y firmar.
+func ed25519Key() (*keyMaterial, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("fallo al generar la clave Ed25519: %w", err)
+	}
+	m := &keyMaterial{algorithm: algoEdDSA}
+	m.public = append([]byte{byte(len(oidEd25519))}, oidEd25519...)
+	m.public = append(m.public, mpi(append([]byte{0x40}, public...))...)
+	m.secret = mpi(private.Seed())
+	m.sign = func(digest []byte) ([]byte, error) {
+		sig := ed25519.Sign(private, digest)
+		return append(mpi(sig[:32]), mpi(sig[32:])...), nil
+	}
+	return m, nil
+}
+
+// cv25519Key genera una subclave ECDH sobre Curve25519 para cifrar.
+func cv25519Key() (*keyMaterial, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("fallo al generar la clave Curve25519: %w", err)
+	}
+	raw[0] &= 248
+	raw[31] = (raw[31] & 127) | 64
+	private, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &keyMaterial{algorithm: algoECDH}
+	m.public = append([]byte{byte(len(oidCurve25519))}, oidCurve25519...)
+	m.public = append(m.public, mpi(append([]byte{0x40}, private.PublicKey().Bytes()...))...)
+	// Parámetros KDF: SHA-256 y AES-128, los que usa GnuPG para Curve25519
+	m.public = append(m.public, 3, 1, hashSHA256, 7)
+	// El escalar se guarda en orden big-endian, el inverso del nativo de X25519
+	reversed := slices.Clone(raw)
+	slices.Reverse(reversed)
+	m.secret = mpi(reversed)
+	return m, nil
+}
@@ -0,0 +1,88 @@
+// Package openpgp genera claves OpenPGP v4 (RFC 4880 y RFC 6637) con la biblioteca
+// estándar: una clave primaria de certificación y firma, una subclave de cifrado y sus
+// autofirmas, serializadas en armadura ASCII.
+package openpgp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+)
+
+// Etiquetas de paquete.
+const (
+	tagSignature        = 2
+	tagSecretKey        = 5
+	tagPublicKey        = 6
+	tagSecretSubkey     = 7
+	tagUserID           = 13
+	tagPublicSubkey     = 14
+	signatureVersion    = 4
+	keyVersion          = 4
+	hashSHA256          = 8
+	sigPositiveCert     = 0x13
+	sigSubkeyBinding    = 0x18
+	s2kUsageUnprotected = 0
+)
+
+// Subpaquetes de firma.
+const (
+	subCreationTime      = 2
+	subKeyExpiration     = 9
+	subPreferredSym      = 11
+	subIssuer            = 16
+	subPreferredHash     = 21
+	subPreferredCompr    = 22
+	subKeyFlags          = 27
+	subFeatures          = 30
+	subIssuerFingerprint = 33
+)
+
+// Indicadores de uso de clave.
+const (
+	flagCertify = 0x01
+	flagSign    = 0x02
+	flagEncrypt = 0x04 | 0x08
+)
+
+// packet serializa un paquete con cabecera de formato nuevo.
+func packet(tag byte, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(0xC0 | tag)
+	n := len(body)
+	switch {
+	case n < 192:
+		buf.WriteByte(byte(n))
+	case n < 8384:
+		n -= 192
+		buf.WriteByte(byte(n>>8) + 192)
+		buf.WriteByte(byte(n))
+	default:
+		buf.WriteByte(0xFF)
+		_ = binary.Write(&buf, binary.BigEndian, uint32(n))
+	}
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// mpi codifica un entero sin signo (big-endian) como MPI: longitud en bits y bytes sin
+// ceros a la izquierda.
+func mpi(b []byte) []byte {
+	b = bytes.TrimLeft(b, "\x00")
+	bits := len(b) * 8
+	if len(b) > 0 {
+		bits = (len(b)-1)*8 + new(big.Int).SetBytes(b[:1]).BitLen()
+	}
+	out := make([]byte, 2, 2+len(b))
+	binary.BigEndian.PutUint16(out, uint16(bits))
+	return append(out, b...)
+}
+
+// subpacket serializa un subpaquete de firma (todos los usados miden menos de 192 bytes).
+func subpacket(typ byte, data ...byte) []byte {
+	return append([]byte{byte(len(data) + 1), typ}, data...)
+}
+
+func uint32Bytes(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
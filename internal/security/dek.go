@@ -0,0 +1,44 @@
+package security
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// DataKeySize es el tamaño en bytes de las claves de cifrado de datos (AES-256).
+const DataKeySize = 32
+
+// KeyRing es el conjunto de claves de cifrado de datos de una aplicación: la activa
+// cifra los datos nuevos y las anteriores solo descifran los ya existentes.
+type KeyRing struct {
+	ActiveKeyID int64     `json:"activeKeyId"`
+	Keys        []DataKey `json:"keys"`
+}
+
+// DataKey es una clave AES-256 con su identificador; Key se serializa en base64.
+type DataKey struct {
+	ID  int64  `json:"id"`
+	Key []byte `json:"key"`
+}
+
+// Rotate devuelve un KeyRing con una clave nueva activa, de identificador el siguiente
+// al de la activa, y como mucho las retain claves más recientes del actual.
+func (k KeyRing) Rotate(retain int) (KeyRing, error) {
+	if err := RequireApproved("aes-256"); err != nil {
+		return KeyRing{}, err
+	}
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return KeyRing{}, fmt.Errorf("fallo al generar la clave de cifrado: %w", err)
+	}
+
+	next := KeyRing{ActiveKeyID: k.ActiveKeyID + 1}
+	next.Keys = append(next.Keys, DataKey{ID: next.ActiveKeyID, Key: key})
+	for _, previous := range k.Keys {
+		if len(next.Keys) > retain {
+			break
+		}
+		next.Keys = append(next.Keys, previous)
+	}
+	return next, nil
+}
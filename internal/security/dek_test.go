@@ -0,0 +1,64 @@
+package security
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestKeyRingRotate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rotations int
+		retain    int
+		wantIDs   []int64
+	}{
+		{name: "first key", rotations: 1, retain: 2, wantIDs: []int64{1}},
+		{name: "within retention", rotations: 3, retain: 2, wantIDs: []int64{3, 2, 1}},
+		{name: "beyond retention", rotations: 5, retain: 2, wantIDs: []int64{5, 4, 3}},
+		{name: "no retention", rotations: 3, retain: 0, wantIDs: []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ring KeyRing
+			for range tt.rotations {
+				next, err := ring.Rotate(tt.retain)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Las claves que se conservan no cambian
+				for _, key := range next.Keys[1:] {
+					i := slices.IndexFunc(ring.Keys, func(k DataKey) bool { return k.ID == key.ID })
+					if i < 0 || !bytes.Equal(ring.Keys[i].Key, key.Key) {
+						t.Fatalf("la clave %d cambió al rotar", key.ID)
+					}
+				}
+				ring = next
+			}
+
+			ids := make([]int64, 0, len(ring.Keys))
+			for _, key := range ring.Keys {
+				ids = append(ids, key.ID)
+				if len(key.Key) != DataKeySize {
+					t.Errorf("la clave %d mide %d bytes, se esperaban %d", key.ID, len(key.Key), DataKeySize)
+				}
+			}
+			if ring.ActiveKeyID != tt.wantIDs[0] || !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("activa %d y claves %v, se esperaba activa %d y claves %v", ring.ActiveKeyID, ids, tt.wantIDs[0], tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestKeyRingRotateGeneratesDistinctKeys(t *testing.T) {
+	ring, err := KeyRing{}.Rotate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring, err = ring.Rotate(1); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ring.Keys[0].Key, ring.Keys[1].Key) {
+		t.Errorf("dos rotaciones generaron la misma clave")
+	}
+}
@@ -0,0 +1,44 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// fingerprintSaltSize es el tamaño en bytes de la sal aleatoria de cada huella.
+const fingerprintSaltSize = 16
+
+// Fingerprint calcula una huella SHA-256 con sal del valor indicado, con el formato
+// "sha256:<sal-hex>:<hash-hex>". La huella permite comparar credenciales sin exponerlas.
+func Fingerprint(value string) (string, error) {
+	salt := make([]byte, fingerprintSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("fallo al generar la sal de la huella: %w", err)
+	}
+	return fingerprintWithSalt(salt, value), nil
+}
+
+// MatchFingerprint indica si el valor corresponde a una huella generada con Fingerprint.
+func MatchFingerprint(fingerprint, value string) bool {
+	parts := strings.Split(fingerprint, ":")
+	if len(parts) != 3 || parts[0] != "sha256" {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	expected := fingerprintWithSalt(salt, value)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(fingerprint)) == 1
+}
+
+func fingerprintWithSalt(salt []byte, value string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(salt) + ":" + hex.EncodeToString(h.Sum(nil))
+}
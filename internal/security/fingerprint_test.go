@@ -0,0 +1,71 @@
+package security
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestFingerprintWithSalt(t *testing.T) {
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		name  string
+		salt  []byte
+		value string
+		want  string
+	}{
+		// Sin sal la huella es el SHA-256 del valor (FIPS 180-2, "abc")
+		{name: "unsalted", salt: nil, value: "abc",
+			want: "sha256::ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{name: "salted", salt: salt, value: "hunter2",
+			want: "sha256:000102030405060708090a0b0c0d0e0f:e8d1e18507861757b13674f2e9d0e9149ed6667f432ad5b8220f9c049a29dc54"},
+		{name: "empty value", salt: salt, value: "",
+			want: "sha256:000102030405060708090a0b0c0d0e0f:be45cb2605bf36bebde684841a28f0fd43c69850a3dce5fedba69928ee3a8991"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprintWithSalt(tt.salt, tt.value); got != tt.want {
+				t.Errorf("fingerprintWithSalt() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFingerprint(t *testing.T) {
+	fingerprint, err := Fingerprint("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Fingerprint("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint == other {
+		t.Errorf("dos huellas del mismo valor coinciden: la sal no es aleatoria")
+	}
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		value       string
+		want        bool
+	}{
+		{name: "same value", fingerprint: fingerprint, value: "hunter2", want: true},
+		{name: "other salt", fingerprint: other, value: "hunter2", want: true},
+		{name: "other value", fingerprint: fingerprint, value: "hunter3", want: false},
+		{name: "known answer", value: "hunter2", want: true,
+			fingerprint: "sha256:000102030405060708090a0b0c0d0e0f:e8d1e18507861757b13674f2e9d0e9149ed6667f432ad5b8220f9c049a29dc54"},
+		{name: "other algorithm", fingerprint: strings.Replace(fingerprint, "sha256", "sha512", 1), value: "hunter2", want: false},
+		{name: "invalid salt", fingerprint: "sha256:zz:00", value: "hunter2", want: false},
+		{name: "missing parts", fingerprint: "sha256:00", value: "hunter2", want: false},
+		{name: "empty", fingerprint: "", value: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchFingerprint(tt.fingerprint, tt.value); got != tt.want {
+				t.Errorf("MatchFingerprint(%q, %q) = %t, se esperaba %t", tt.fingerprint, tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package security
+
+import (
+	"crypto/fips140"
+	"fmt"
+	"sync/atomic"
+)
+
+// MinFIPSPasswordLength es la longitud mínima de contraseña aceptada en modo FIPS.
+const MinFIPSPasswordLength = 14
+
+// approvedAlgorithms lista los algoritmos que los generadores pueden usar en modo FIPS.
+// Cualquier generador nuevo debe registrar aquí su algoritmo si está aprobado.
+var approvedAlgorithms = map[string]bool{
+	"password":    true, // DRBG de crypto/rand
+	"sha256":      true,
+	"sha512":      true,
+	"hmac-sha256": true,
+	"aes-256":     true,
+	"rsa-3072":    true,
+	"rsa-4096":    true,
+	"ecdsa-p256":  true,
+	"ecdsa-p384":  true,
+}
+
+var fipsMode atomic.Bool
+
+// EnableFIPSMode activa el modo FIPS en tiempo de ejecución. Falla si el binario no
+// está respaldado por un módulo criptográfico validado (BoringCrypto o Go FIPS 140-3).
+func EnableFIPSMode() error {
+	if FIPSModule == "go-fips140" && !fips140.Enabled() {
+		return fmt.Errorf("modo FIPS solicitado pero el módulo criptográfico de Go no está en modo FIPS 140-3 " +
+			"(compilar con GOFIPS140 o ejecutar con GODEBUG=fips140=on)")
+	}
+	fipsMode.Store(true)
+	return nil
+}
+
+// FIPSMode indica si los generadores deben restringirse a algoritmos aprobados.
+// Los binarios compilados con el build tag "fips" siempre operan en modo FIPS.
+func FIPSMode() bool {
+	return FIPSBuild || fipsMode.Load()
+}
+
+// RequireApproved devuelve un error si el algoritmo no está aprobado y el modo FIPS está activo.
+func RequireApproved(algorithm string) error {
+	if FIPSMode() && !approvedAlgorithms[algorithm] {
+		return fmt.Errorf("algoritmo %q no aprobado en modo FIPS", algorithm)
+	}
+	return nil
+}
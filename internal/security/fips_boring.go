@@ -0,0 +1,9 @@
+//go:build boringcrypto
+
+package security
+
+// Con GOEXPERIMENT=boringcrypto restringimos además TLS a parámetros aprobados.
+import _ "crypto/tls/fipsonly"
+
+// FIPSModule identifica el módulo criptográfico que respalda el modo FIPS.
+const FIPSModule = "boringcrypto"
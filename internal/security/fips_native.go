@@ -0,0 +1,6 @@
+//go:build !boringcrypto
+
+package security
+
+// FIPSModule identifica el módulo criptográfico que respalda el modo FIPS.
+const FIPSModule = "go-fips140"
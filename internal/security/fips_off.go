@@ -0,0 +1,7 @@
+//go:build !fips
+
+package security
+
+// FIPSBuild indica que el binario se compiló con el build tag "fips" y no puede
+// operar fuera del modo FIPS.
+const FIPSBuild = false
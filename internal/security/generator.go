@@ -35,6 +35,11 @@ func GeneratePassword(length int, includeSymbols bool) (string, error) {
 		return "", fmt.Errorf("conjunto de caracteres vacío o longitud no válida")
 	}
 
+	// En modo FIPS exigimos una longitud mínima acorde a la política aprobada
+	if FIPSMode() && length < MinFIPSPasswordLength {
+		return "", fmt.Errorf("longitud %d inferior al mínimo FIPS de %d caracteres", length, MinFIPSPasswordLength)
+	}
+
 	password := make([]byte, length)
 	maxIndex := big.NewInt(int64(len(set)))
 
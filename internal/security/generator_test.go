@@ -0,0 +1,120 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+// withFIPSMode activa el modo FIPS en tiempo de ejecución durante el test.
+func withFIPSMode(t *testing.T) {
+	t.Helper()
+	previous := fipsMode.Load()
+	fipsMode.Store(true)
+	t.Cleanup(func() { fipsMode.Store(previous) })
+}
+
+func TestGeneratePassword(t *testing.T) {
+	if FIPSBuild {
+		t.Skip("el binario FIPS exige una longitud mínima")
+	}
+	tests := []struct {
+		name           string
+		length         int
+		includeSymbols bool
+		wantErr        bool
+	}{
+		{name: "alphanumeric", length: 64, includeSymbols: false},
+		{name: "with symbols", length: 64, includeSymbols: true},
+		{name: "single character", length: 1},
+		{name: "zero length", length: 0, wantErr: true},
+		{name: "negative length", length: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := GeneratePassword(tt.length, tt.includeSymbols)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratePassword() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(password) != tt.length {
+				t.Errorf("len(password) = %d, se esperaba %d", len(password), tt.length)
+			}
+			allowed := CharUpper + CharLower + CharDigits
+			if tt.includeSymbols {
+				allowed += CharSymbols
+			}
+			if i := strings.IndexFunc(password, func(r rune) bool { return !strings.ContainsRune(allowed, r) }); i >= 0 {
+				t.Errorf("el carácter %q no pertenece al conjunto permitido", password[i])
+			}
+		})
+	}
+}
+
+func TestGeneratePasswordFIPSMinimumLength(t *testing.T) {
+	withFIPSMode(t)
+	if _, err := GeneratePassword(MinFIPSPasswordLength-1, true); err == nil {
+		t.Errorf("GeneratePassword(%d) en modo FIPS no devolvió error", MinFIPSPasswordLength-1)
+	}
+	if _, err := GeneratePassword(MinFIPSPasswordLength, true); err != nil {
+		t.Errorf("GeneratePassword(%d) en modo FIPS = %v", MinFIPSPasswordLength, err)
+	}
+}
+
+func TestRequireApproved(t *testing.T) {
+	if FIPSBuild {
+		t.Skip("el binario FIPS siempre opera en modo FIPS")
+	}
+	tests := []struct {
+		algorithm   string
+		wantFIPSErr bool
+	}{
+		{algorithm: "password"},
+		{algorithm: "aes-256"},
+		{algorithm: "rsa-3072"},
+		{algorithm: "bcrypt", wantFIPSErr: true},
+		{algorithm: "x25519", wantFIPSErr: true},
+		{algorithm: "hmac-sha1", wantFIPSErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			// Fuera del modo FIPS todo algoritmo está permitido
+			if err := RequireApproved(tt.algorithm); err != nil {
+				t.Errorf("RequireApproved(%q) fuera del modo FIPS = %v", tt.algorithm, err)
+			}
+			withFIPSMode(t)
+			if err := RequireApproved(tt.algorithm); (err != nil) != tt.wantFIPSErr {
+				t.Errorf("RequireApproved(%q) en modo FIPS = %v, wantErr %t", tt.algorithm, err, tt.wantFIPSErr)
+			}
+		})
+	}
+}
+
+func TestParsePasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    PasswordPolicy
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: DefaultPasswordPolicy()},
+		{name: "length", spec: "length=32", want: PasswordPolicy{Length: 32, IncludeSymbols: DefaultIncludeSymbols}},
+		{name: "both", spec: " length=20 , symbols=false", want: PasswordPolicy{Length: 20, IncludeSymbols: false}},
+		{name: "missing value", spec: "length", wantErr: true},
+		{name: "invalid length", spec: "length=long", wantErr: true},
+		{name: "invalid symbols", spec: "symbols=maybe", wantErr: true},
+		{name: "unknown key", spec: "digits=4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePasswordPolicy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePasswordPolicy(%q) error = %v, wantErr %t", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParsePasswordPolicy(%q) = %+v, se esperaba %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
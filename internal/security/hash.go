@@ -0,0 +1,175 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritmos de hash de contraseñas admitidos por HashPassword.
+const (
+	HashBcrypt      = "bcrypt"
+	HashArgon2id    = "argon2id"
+	HashSHA512Crypt = "sha512-crypt"
+)
+
+// BcryptMaxLength es la longitud máxima, en bytes, de una contraseña que bcrypt acepta.
+const BcryptMaxLength = 72
+
+// Parámetros de argon2id: la segunda configuración recomendada por el RFC 9106.
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+// sha512CryptRounds es el número de rondas por defecto de sha512-crypt, que se omite
+// en el hash resultante.
+const sha512CryptRounds = 5000
+
+// cryptAlphabet es el alfabeto base64 de crypt(3).
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// HashPassword calcula el hash de la contraseña en el formato habitual de cada algoritmo:
+// "$2a$..." (bcrypt), PHC "$argon2id$v=19$..." o "$6$<sal>$..." (sha512-crypt).
+func HashPassword(algorithm, password string) (string, error) {
+	if err := RequireApproved(algorithm); err != nil {
+		return "", err
+	}
+	switch algorithm {
+	case HashBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("fallo al calcular el hash bcrypt: %w", err)
+		}
+		return string(hash), nil
+	case HashArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("fallo al generar la sal: %w", err)
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	case HashSHA512Crypt:
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("fallo al generar la sal: %w", err)
+		}
+		for i := range salt {
+			salt[i] = cryptAlphabet[int(salt[i])%len(cryptAlphabet)]
+		}
+		return sha512Crypt([]byte(password), salt), nil
+	}
+	return "", fmt.Errorf("algoritmo de hash %q no soportado", algorithm)
+}
+
+// HtpasswdEntry devuelve la línea de htpasswd "<usuario>:<hash bcrypt>" para la
+// contraseña, el formato que esperan los Secrets de basic-auth de los Ingress.
+func HtpasswdEntry(username, password string) (string, error) {
+	if username == "" || strings.Contains(username, ":") {
+		return "", fmt.Errorf("usuario de htpasswd no válido: %q", username)
+	}
+	hash, err := HashPassword(HashBcrypt, password)
+	if err != nil {
+		return "", err
+	}
+	return username + ":" + hash, nil
+}
+
+// sha512Crypt implementa el esquema "$6$" de crypt(3) (Drepper, "Unix crypt using
+// SHA-256 and SHA-512") con el número de rondas por defecto.
+func sha512Crypt(password, salt []byte) string {
+	// Digest B: contraseña, sal, contraseña
+	b := sha512.New()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	sumB := b.Sum(nil)
+
+	// Digest A
+	a := sha512.New()
+	a.Write(password)
+	a.Write(salt)
+	a.Write(repeatTo(sumB, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(sumB)
+		} else {
+			a.Write(password)
+		}
+	}
+	sumA := a.Sum(nil)
+
+	// Secuencia P: digest de la contraseña repetida tantas veces como su longitud
+	dp := sha512.New()
+	for range password {
+		dp.Write(password)
+	}
+	p := repeatTo(dp.Sum(nil), len(password))
+
+	// Secuencia S: digest de la sal repetida 16 + A[0] veces
+	ds := sha512.New()
+	for i := 0; i < 16+int(sumA[0]); i++ {
+		ds.Write(salt)
+	}
+	s := repeatTo(ds.Sum(nil), len(salt))
+
+	sum := sumA
+	for i := 0; i < sha512CryptRounds; i++ {
+		c := sha512.New()
+		if i&1 != 0 {
+			c.Write(p)
+		} else {
+			c.Write(sum)
+		}
+		if i%3 != 0 {
+			c.Write(s)
+		}
+		if i%7 != 0 {
+			c.Write(p)
+		}
+		if i&1 != 0 {
+			c.Write(sum)
+		} else {
+			c.Write(p)
+		}
+		sum = c.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString("$6$")
+	out.Write(salt)
+	out.WriteByte('$')
+	// Los bytes se codifican de tres en tres en el orden permutado del esquema
+	for i := 0; i < 21; i++ {
+		cryptBase64(&out, sum[(i*22)%63], sum[(i*22+21)%63], sum[(i*22+42)%63], 4)
+	}
+	cryptBase64(&out, 0, 0, sum[63], 2)
+	return out.String()
+}
+
+// repeatTo repite data hasta completar n bytes.
+func repeatTo(data []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, data[:min(len(data), n-len(out))]...)
+	}
+	return out
+}
+
+// cryptBase64 escribe n caracteres del grupo de 24 bits b2:b1:b0, empezando por los bits bajos.
+func cryptBase64(out *strings.Builder, b2, b1, b0 byte, n int) {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for ; n > 0; n-- {
+		out.WriteByte(cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
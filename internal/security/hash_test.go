@@ -0,0 +1,96 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHtpasswdEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", username: "admin", password: "s3cr3t"},
+		{name: "empty password", username: "admin", password: ""},
+		{name: "maximum length", username: "admin", password: strings.Repeat("a", BcryptMaxLength)},
+		{name: "empty username", username: "", password: "s3cr3t", wantErr: true},
+		{name: "colon in username", username: "ad:min", password: "s3cr3t", wantErr: true},
+		{name: "password too long", username: "admin", password: strings.Repeat("a", BcryptMaxLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := HtpasswdEntry(tt.username, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HtpasswdEntry() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			username, hash, ok := strings.Cut(entry, ":")
+			if !ok || username != tt.username {
+				t.Fatalf("HtpasswdEntry() = %q, se esperaba el usuario %q", entry, tt.username)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(tt.password)); err != nil {
+				t.Errorf("el hash %q no verifica la contraseña: %v", hash, err)
+			}
+		})
+	}
+}
+
+// Vectores de "Unix crypt using SHA-256 and SHA-512" (Drepper) con las rondas por defecto,
+// contrastados con openssl passwd -6.
+func TestSHA512Crypt(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		want     string
+	}{
+		{password: "Hello world!", salt: "saltstring",
+			want: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{password: "we have a short salt string but not a short password", salt: "short",
+			want: "$6$short$qmfj2meTBr5G2EAGIJ4vjX7RpefsD4JzpEyTAeEUJdzdxlBS6pe8gdMHm5zFftaFSj/2p2bjBwyVS9ZhWpLZt."},
+		// Contraseña no ASCII con la sal de 16 caracteres que genera HashPassword
+		{password: "ñandú", salt: "SALTsalt01234567",
+			want: "$6$SALTsalt01234567$54szvgysNrHKAxCf0W6Akyvxe8LXW1KsYYGr6yn3X6d2TEJk.UkMJtotH0O/bLXvCsOE8/yvt8TOnBFcvJy8i."},
+	}
+	for _, tt := range tests {
+		if got := sha512Crypt([]byte(tt.password), []byte(tt.salt)); got != tt.want {
+			t.Errorf("sha512Crypt(%q, %q) = %s, se esperaba %s", tt.password, tt.salt, got, tt.want)
+		}
+	}
+}
+
+func TestHashPasswordFormats(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		prefix    string
+		parts     int
+	}{
+		{algorithm: HashBcrypt, prefix: "$2a$10$", parts: 4},
+		{algorithm: HashArgon2id, prefix: "$argon2id$v=19$m=65536,t=3,p=4$", parts: 6},
+		{algorithm: HashSHA512Crypt, prefix: "$6$", parts: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			hash, err := HashPassword(tt.algorithm, "s3cr3t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hash, tt.prefix) || len(strings.Split(hash, "$")) != tt.parts {
+				t.Errorf("HashPassword(%q) = %q, se esperaba el formato %s...", tt.algorithm, hash, tt.prefix)
+			}
+			// La sal es aleatoria: dos hashes de la misma contraseña no coinciden
+			if again, _ := HashPassword(tt.algorithm, "s3cr3t"); again == hash {
+				t.Errorf("HashPassword(%q) repite el hash, la sal no es aleatoria", tt.algorithm)
+			}
+		})
+	}
+
+	if _, err := HashPassword("md5", "s3cr3t"); err == nil {
+		t.Errorf("HashPassword(\"md5\") no devolvió error")
+	}
+}
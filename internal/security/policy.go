@@ -0,0 +1,62 @@
+package security
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Valores por defecto de la política, iguales a los del CRD.
+const (
+	DefaultPasswordLength = 16
+	DefaultIncludeSymbols = true
+)
+
+// PasswordPolicy describe cómo se genera una contraseña.
+type PasswordPolicy struct {
+	Length         int
+	IncludeSymbols bool
+}
+
+// DefaultPasswordPolicy devuelve la política por defecto del operador.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{Length: DefaultPasswordLength, IncludeSymbols: DefaultIncludeSymbols}
+}
+
+// ParsePasswordPolicy interpreta una política en formato "length=32,symbols=false".
+// Las claves omitidas conservan su valor por defecto.
+func ParsePasswordPolicy(s string) (PasswordPolicy, error) {
+	policy := DefaultPasswordPolicy()
+	if strings.TrimSpace(s) == "" {
+		return policy, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return policy, fmt.Errorf("entrada de política no válida %q (se espera clave=valor)", part)
+		}
+		switch key {
+		case "length":
+			length, err := strconv.Atoi(value)
+			if err != nil {
+				return policy, fmt.Errorf("longitud no válida %q: %w", value, err)
+			}
+			policy.Length = length
+		case "symbols":
+			symbols, err := strconv.ParseBool(value)
+			if err != nil {
+				return policy, fmt.Errorf("valor de symbols no válido %q: %w", value, err)
+			}
+			policy.IncludeSymbols = symbols
+		default:
+			return policy, fmt.Errorf("clave de política desconocida %q", key)
+		}
+	}
+	return policy, nil
+}
+
+// Generate genera una contraseña que cumple la política.
+func (p PasswordPolicy) Generate() (string, error) {
+	return GeneratePassword(p.Length, p.IncludeSymbols)
+}
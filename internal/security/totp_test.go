@@ -0,0 +1,68 @@
+package security
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      TOTPConfig
+		seedSize int
+		wantPath string
+	}{
+		{name: "SHA1", cfg: TOTPConfig{Issuer: "Example", AccountName: "alice@example.com", Algorithm: "SHA1", Digits: 6, Period: 30},
+			seedSize: 20, wantPath: "/Example:alice@example.com"},
+		{name: "SHA256", cfg: TOTPConfig{Issuer: "Example", AccountName: "alice", Algorithm: "SHA256", Digits: 8, Period: 60},
+			seedSize: 32, wantPath: "/Example:alice"},
+		{name: "SHA512 without issuer", cfg: TOTPConfig{AccountName: "alice", Algorithm: "SHA512", Digits: 6, Period: 30},
+			seedSize: 64, wantPath: "/alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, uri, err := GenerateTOTP(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(seed)
+			if err != nil || len(raw) != tt.seedSize {
+				t.Fatalf("semilla %q: %d bytes (%v), se esperaban %d", seed, len(raw), err, tt.seedSize)
+			}
+
+			parsed, err := url.Parse(uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != tt.wantPath {
+				t.Errorf("URI = %q, se esperaba otpauth://totp%s", uri, tt.wantPath)
+			}
+			query := parsed.Query()
+			if query.Get("secret") != seed || query.Get("issuer") != tt.cfg.Issuer || query.Get("algorithm") != tt.cfg.Algorithm {
+				t.Errorf("consulta de la URI = %v", query)
+			}
+			if query.Get("digits") != strconv.Itoa(tt.cfg.Digits) || query.Get("period") != strconv.Itoa(tt.cfg.Period) {
+				t.Errorf("dígitos o periodo de la URI = %q, %q", query.Get("digits"), query.Get("period"))
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPEncodesSpacesAsPercent(t *testing.T) {
+	_, uri, err := GenerateTOTP(TOTPConfig{Issuer: "Acme Corp", AccountName: "alice", Algorithm: "SHA1", Digits: 6, Period: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(uri, "issuer=Acme%20Corp") || strings.Contains(uri, "+") {
+		t.Errorf("URI = %q, se esperaba el espacio del emisor como %%20", uri)
+	}
+}
+
+func TestGenerateTOTPRejectsUnknownAlgorithm(t *testing.T) {
+	if _, _, err := GenerateTOTP(TOTPConfig{AccountName: "alice", Algorithm: "MD5", Digits: 6, Period: 30}); err == nil {
+		t.Errorf("GenerateTOTP() con MD5 no devolvió error")
+	}
+}
@@ -0,0 +1,47 @@
+package security
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// WireGuardKeyPair es un par de claves Curve25519 de WireGuard codificadas en base64,
+// el formato de wg(8).
+type WireGuardKeyPair struct {
+	PrivateKey string
+	PublicKey  string
+}
+
+// GenerateWireGuardKeyPair genera un par de claves como "wg genkey | wg pubkey".
+func GenerateWireGuardKeyPair() (WireGuardKeyPair, error) {
+	if err := RequireApproved("x25519"); err != nil {
+		return WireGuardKeyPair{}, err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return WireGuardKeyPair{}, fmt.Errorf("fallo al generar la clave privada: %w", err)
+	}
+	// Clamping de Curve25519, igual que wg genkey
+	raw[0] &= 248
+	raw[31] = (raw[31] & 127) | 64
+
+	private, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return WireGuardKeyPair{}, err
+	}
+	return WireGuardKeyPair{
+		PrivateKey: base64.StdEncoding.EncodeToString(private.Bytes()),
+		PublicKey:  base64.StdEncoding.EncodeToString(private.PublicKey().Bytes()),
+	}, nil
+}
+
+// GenerateWireGuardPresharedKey genera una clave precompartida como "wg genpsk".
+func GenerateWireGuardPresharedKey() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("fallo al generar la clave precompartida: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,59 @@
+package security
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+func TestGenerateWireGuardKeyPair(t *testing.T) {
+	pair, err := GenerateWireGuardKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := base64.StdEncoding.DecodeString(pair.PrivateKey)
+	if err != nil || len(private) != 32 {
+		t.Fatalf("clave privada %q: %d bytes (%v), se esperaban 32", pair.PrivateKey, len(private), err)
+	}
+	// Clamping de Curve25519: los 3 bits bajos a cero, el bit alto a cero y el siguiente a uno
+	if private[0]&7 != 0 || private[31]&128 != 0 || private[31]&64 == 0 {
+		t.Errorf("la clave privada %x no está clamped", private)
+	}
+
+	// La clave pública es la que calcularía wg pubkey a partir de la privada
+	key, err := ecdh.X25519().NewPrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()); pair.PublicKey != want {
+		t.Errorf("PublicKey = %q, se esperaba %q", pair.PublicKey, want)
+	}
+
+	other, err := GenerateWireGuardKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.PrivateKey == pair.PrivateKey {
+		t.Errorf("dos pares generados comparten la clave privada")
+	}
+}
+
+func TestGenerateWireGuardPresharedKey(t *testing.T) {
+	first, err := GenerateWireGuardPresharedKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateWireGuardPresharedKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, errA := base64.StdEncoding.DecodeString(first)
+	b, errB := base64.StdEncoding.DecodeString(second)
+	if errA != nil || errB != nil || len(a) != 32 || len(b) != 32 {
+		t.Fatalf("claves precompartidas %q y %q, se esperaban 32 bytes en base64", first, second)
+	}
+	if bytes.Equal(a, b) {
+		t.Errorf("dos claves precompartidas coinciden")
+	}
+}
@@ -0,0 +1,18 @@
+package workpool
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// throttleWait mide cuánto espera cada operación al ritmo de su backend. Se publica en
+// el endpoint de métricas del manager.
+var throttleWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "secret_rotator_backend_throttle_wait_seconds",
+	Help:    "Time backend operations waited for the per-backend rate limit set with --executor-rates.",
+	Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+}, []string{"backend"})
+
+func init() {
+	metrics.Registry.MustRegister(throttleWait)
+}
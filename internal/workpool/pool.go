@@ -0,0 +1,149 @@
+package workpool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultWorkers es el número total de ejecuciones simultáneas por defecto.
+const DefaultWorkers = 8
+
+// Pool limita las ejecuciones costosas (escrituras en backends, APIs de cloud, bases de
+// datos) con independencia del número de workers de reconciliación. Además del límite
+// global, cada backend puede tener su propio límite de concurrencia y un ritmo máximo
+// de operaciones por segundo, común a todas las Rotations.
+type Pool struct {
+	total   chan struct{}
+	backend map[string]chan struct{}
+	rates   map[string]*rate.Limiter
+}
+
+// Rate es el ritmo máximo de operaciones contra un backend: PerSecond de media, con
+// ráfagas de hasta Burst operaciones seguidas.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// New crea un pool con workers ejecuciones simultáneas en total y los límites y ritmos
+// por backend indicados. Un backend sin límite propio solo está sujeto al global; sin
+// ritmo propio no se espacian sus operaciones.
+func New(workers int, limits map[string]int, rates map[string]Rate) *Pool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	p := &Pool{
+		total:   make(chan struct{}, workers),
+		backend: map[string]chan struct{}{},
+		rates:   map[string]*rate.Limiter{},
+	}
+	for name, limit := range limits {
+		if limit > 0 {
+			p.backend[name] = make(chan struct{}, limit)
+		}
+	}
+	for name, r := range rates {
+		if r.PerSecond > 0 {
+			p.rates[name] = rate.NewLimiter(rate.Limit(r.PerSecond), max(r.Burst, 1))
+		}
+	}
+	return p
+}
+
+// Do ejecuta fn cuando hay hueco para el backend indicado, o devuelve el error del
+// contexto si se cancela mientras espera.
+func (p *Pool) Do(ctx context.Context, backend string, fn func(context.Context) error) error {
+	if p == nil {
+		return fn(ctx)
+	}
+
+	// El ritmo se respeta antes de ocupar ningún hueco
+	if limiter, ok := p.rates[backend]; ok {
+		start := time.Now()
+		err := limiter.Wait(ctx)
+		throttleWait.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Primero el límite del backend: así una ráfaga contra un backend saturado no
+	// ocupa huecos globales que otros backends podrían usar.
+	if slots, ok := p.backend[backend]; ok {
+		if err := acquire(ctx, slots); err != nil {
+			return err
+		}
+		defer func() { <-slots }()
+	}
+	if err := acquire(ctx, p.total); err != nil {
+		return err
+	}
+	defer func() { <-p.total }()
+
+	return fn(ctx)
+}
+
+func acquire(ctx context.Context, slots chan struct{}) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ParseLimits interpreta límites por backend en formato "vault=4,database=2".
+func ParseLimits(spec string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("límite no válido %q: se esperaba backend=n", part)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("límite no válido para %q: %q", name, value)
+		}
+		limits[strings.TrimSpace(name)] = limit
+	}
+	return limits, nil
+}
+
+// ParseRates interpreta ritmos por backend, en operaciones por segundo y con una ráfaga
+// opcional tras los dos puntos, en formato "vault=20:40,registry=0.5". Sin ráfaga se
+// admite una sola operación seguida.
+func ParseRates(spec string) (map[string]Rate, error) {
+	rates := map[string]Rate{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("ritmo no válido %q: se esperaba backend=n o backend=n:ráfaga", part)
+		}
+		perSecondValue, burstValue, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+		perSecond, err := strconv.ParseFloat(perSecondValue, 64)
+		if err != nil || perSecond <= 0 {
+			return nil, fmt.Errorf("ritmo no válido para %q: %q", name, value)
+		}
+		burst := 1
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstValue); err != nil || burst <= 0 {
+				return nil, fmt.Errorf("ráfaga no válida para %q: %q", name, value)
+			}
+		}
+		rates[strings.TrimSpace(name)] = Rate{PerSecond: perSecond, Burst: burst}
+	}
+	return rates, nil
+}
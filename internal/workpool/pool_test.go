@@ -0,0 +1,179 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: map[string]int{}},
+		{name: "several", spec: "vault=4,database=2", want: map[string]int{"vault": 4, "database": 2}},
+		{name: "spaces", spec: " vault = 4 , ,database=2 ", want: map[string]int{"vault": 4, "database": 2}},
+		{name: "missing value", spec: "vault", wantErr: true},
+		{name: "not a number", spec: "vault=many", wantErr: true},
+		{name: "zero", spec: "vault=0", wantErr: true},
+		{name: "negative", spec: "vault=-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimits(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimits(%q) error = %v, wantErr %t", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("ParseLimits(%q) = %v, se esperaba %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+// peak ejecuta calls llamadas simultáneas a Do contra backend y devuelve el máximo de
+// ejecuciones que coincidieron.
+func peak(t *testing.T, p *Pool, backend string, calls int) int {
+	t.Helper()
+	var running, highest atomic.Int32
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.Do(context.Background(), backend, func(context.Context) error {
+				now := running.Add(1)
+				for {
+					seen := highest.Load()
+					if now <= seen || highest.CompareAndSwap(seen, now) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(highest.Load())
+}
+
+func TestDoLimitsConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		limits  map[string]int
+		backend string
+		limit   int
+	}{
+		{name: "global limit", workers: 3, backend: "vault", limit: 3},
+		{name: "default workers", workers: 0, backend: "vault", limit: DefaultWorkers},
+		{name: "backend limit", workers: 8, limits: map[string]int{"database": 2}, backend: "database", limit: 2},
+		{name: "other backend", workers: 4, limits: map[string]int{"database": 1}, backend: "vault", limit: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tt.workers, tt.limits, nil)
+			if got := peak(t, p, tt.backend, 4*tt.limit); got > tt.limit {
+				t.Errorf("coincidieron %d ejecuciones, el límite es %d", got, tt.limit)
+			}
+		})
+	}
+}
+
+func TestDoReturnsContextErrorWhileWaiting(t *testing.T) {
+	p := New(1, nil, nil)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = p.Do(context.Background(), "vault", func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	called := false
+	err := p.Do(ctx, "vault", func(context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || called {
+		t.Errorf("Do() = %v (ejecutada: %t), se esperaba que venciera el plazo sin ejecutarse", err, called)
+	}
+}
+
+func TestNilPoolRunsDirectly(t *testing.T) {
+	var p *Pool
+	want := errors.New("fallo")
+	if err := p.Do(context.Background(), "vault", func(context.Context) error { return want }); !errors.Is(err, want) {
+		t.Errorf("Do() = %v, se esperaba %v", err, want)
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]Rate
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: map[string]Rate{}},
+		{name: "without burst", spec: "registry=0.5", want: map[string]Rate{"registry": {PerSecond: 0.5, Burst: 1}}},
+		{name: "with burst", spec: "vault=20:40, registry=0.5",
+			want: map[string]Rate{"vault": {PerSecond: 20, Burst: 40}, "registry": {PerSecond: 0.5, Burst: 1}}},
+		{name: "missing value", spec: "vault", wantErr: true},
+		{name: "zero rate", spec: "vault=0", wantErr: true},
+		{name: "invalid rate", spec: "vault=fast", wantErr: true},
+		{name: "zero burst", spec: "vault=20:0", wantErr: true},
+		{name: "invalid burst", spec: "vault=20:many", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRates(%q) error = %v, wantErr %t", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("ParseRates(%q) = %v, se esperaba %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoPacesBackendOperations(t *testing.T) {
+	const perSecond = 50
+	p := New(0, nil, map[string]Rate{"registry": {PerSecond: perSecond, Burst: 2}})
+	run := func(backend string, calls int) time.Duration {
+		start := time.Now()
+		for range calls {
+			if err := p.Do(context.Background(), backend, func(context.Context) error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return time.Since(start)
+	}
+
+	// Tras la ráfaga de 2, cada operación espera 1/50 s: 5 operaciones más tardan al menos 100ms
+	if elapsed := run("registry", 7); elapsed < 5*time.Second/perSecond-10*time.Millisecond {
+		t.Errorf("7 operaciones tardaron %s, el ritmo no se respetó", elapsed)
+	}
+	if elapsed := run("vault", 50); elapsed > 50*time.Millisecond {
+		t.Errorf("un backend sin ritmo tardó %s en 50 operaciones", elapsed)
+	}
+}